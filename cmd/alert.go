@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/client"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/cyrilghali/metro-cli/internal/notify"
+	"github.com/spf13/cobra"
+)
+
+var (
+	alertLines    []string
+	alertPlaces   []string
+	alertInterval time.Duration
+	alertDesktop  bool
+	alertWebhooks []string
+	alertExec     []string
+)
+
+var alertCmd = &cobra.Command{
+	Use:   "alert",
+	Short: "Get notified when disruptions on your lines start, change or end",
+	Long: `Watch lines and places, and notify when a disruption appears, changes
+severity, or clears. Each disruption is reported once, however many of
+your lines or places it affects.
+
+--lines watches line-wide status. A code shared by several modes needs
+its mode: "M1" or "RER A" rather than "1" or "A". --places watches the
+disruptions on the lines serving your places (only those in --lines, if
+both are set). Disruptions already active when the command starts are
+reported on the first check.
+
+Every event is printed. Notifiers add other channels:
+  --desktop          desktop notification (notify-send)
+  --webhook URL      POST the event as JSON (repeatable)
+  --exec CMD         run CMD with the event as JSON on stdin and in
+                     METRO_EVENT, METRO_LINES, METRO_SEVERITY, METRO_TITLE,
+                     METRO_MESSAGE, ... (repeatable)
+
+Examples:
+  metro alert --lines M14,"RER A" --desktop
+  metro alert --places home,work --webhook https://hooks.example.com/metro
+  metro alert --lines "RER B" --exec 'logger -t metro "$METRO_TITLE"'`,
+	Args: cobra.NoArgs,
+	RunE: runAlert,
+}
+
+func init() {
+	alertCmd.Flags().StringSliceVar(&alertLines, "lines", nil, "lines to watch (e.g. M14,\"RER A\")")
+	alertCmd.Flags().StringSliceVar(&alertPlaces, "places", nil, "saved places, stations or addresses whose lines to watch")
+	alertCmd.Flags().DurationVar(&alertInterval, "interval", 2*time.Minute, "how often to check")
+	alertCmd.Flags().BoolVar(&alertDesktop, "desktop", false, "show desktop notifications (notify-send)")
+	alertCmd.Flags().StringArrayVar(&alertWebhooks, "webhook", nil, "POST events as JSON to this URL")
+	alertCmd.Flags().StringArrayVar(&alertExec, "exec", nil, "run this shell command for each event")
+	rootCmd.AddCommand(alertCmd)
+}
+
+func runAlert(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if len(alertLines) == 0 && len(alertPlaces) == 0 {
+		return fmt.Errorf("nothing to watch\nUsage: metro alert --lines M14,\"RER A\"\n       metro alert --places home")
+	}
+	if alertInterval < 30*time.Second {
+		return fmt.Errorf("--interval must be at least 30s (got %s)", alertInterval)
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	// Resolve each line to its full label up front, so "1" is refused as
+	// ambiguous rather than matching both metro 1 and bus 1
+	lines := make([]string, len(alertLines))
+	for i, l := range alertLines {
+		if lines[i], _, _, err = findLine(ctx, c, l); err != nil {
+			return err
+		}
+	}
+	var places []namedPlace
+	if len(alertPlaces) > 0 {
+		if places, err = resolvePlaces(ctx, c, alertPlaces, 0); err != nil {
+			return err
+		}
+	}
+	// Line status is cached for longer than the interval
+	c.SetRefresh(true)
+
+	var notifiers []notify.Notifier
+	if alertDesktop {
+		notifiers = append(notifiers, notify.Desktop{})
+	}
+	for _, u := range alertWebhooks {
+		notifiers = append(notifiers, notify.Webhook{URL: u})
+	}
+	for _, command := range alertExec {
+		notifiers = append(notifiers, notify.Exec{Command: command})
+	}
+
+	watching := append([]string(nil), lines...)
+	for _, p := range places {
+		watching = append(watching, p.Title)
+	}
+	infof("Watching %s every %s (Ctrl-C to stop)\n\n", strings.Join(watching, ", "), alertInterval)
+
+	quietInfo = true
+	defer func() { quietInfo = false }()
+
+	w := &alertWatcher{c: c, lines: lines, places: places, notifiers: notifiers, known: make(map[string]alertItem)}
+	tick := time.NewTicker(alertInterval)
+	defer tick.Stop()
+	for {
+		w.check(ctx, os.Stdout)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick.C:
+		}
+	}
+}
+
+// alertItem is an active disruption on watched lines.
+type alertItem struct {
+	Lines    []string
+	Severity model.Severity
+	Message  string
+}
+
+// alertWatcher polls lines and places and turns changes into events.
+type alertWatcher struct {
+	c         *client.Client
+	lines     []string
+	places    []namedPlace
+	notifiers []notify.Notifier
+	known     map[string]alertItem // by DisruptionID
+}
+
+// check polls once, prints the events and sends them to the notifiers.
+func (w *alertWatcher) check(ctx context.Context, out io.Writer) {
+	current, complete := w.poll(ctx)
+	for _, e := range w.diff(current, complete, time.Now()) {
+		printAlert(out, e)
+		for _, n := range w.notifiers {
+			if err := n.Notify(ctx, e); err != nil && ctx.Err() == nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+			}
+		}
+	}
+}
+
+// poll returns the active disruptions on watched lines by DisruptionID.
+// complete is false when a line or place could not be fetched, so its
+// disruptions may be missing.
+func (w *alertWatcher) poll(ctx context.Context) (map[string]alertItem, bool) {
+	current := make(map[string]alertItem)
+	complete := true
+	add := func(label string, d *model.Disruption) {
+		id := d.DisruptionID
+		if id == "" {
+			id = d.ID
+		}
+		item := current[id]
+		if !slices.Contains(item.Lines, label) {
+			item.Lines = append(item.Lines, label)
+			sort.Strings(item.Lines)
+		}
+		item.Severity = d.Severity
+		item.Message = display.DisruptionMessage(*d)
+		current[id] = item
+	}
+
+	for _, label := range w.lines {
+		resp, err := w.c.Lines(ctx, model.LineFilter(label), 10)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", label, err)
+			complete = false
+			continue
+		}
+		// A bus line's bare code also fetches the metro line of that code
+		only := *resp
+		only.Lines = nil
+		for _, l := range resp.Lines {
+			mode := ""
+			if l.CommercialMode != nil {
+				mode = l.CommercialMode.Name
+			}
+			if strings.EqualFold(model.LineLabel(l.Code, mode), label) {
+				only.Lines = append(only.Lines, l)
+			}
+		}
+		mode, _ := model.ParseLineLabel(label)
+		for _, ld := range display.LineDisruptions(&only, model.Modes[mode]) {
+			add(ld.Label, ld.Disruption)
+		}
+	}
+
+	for _, p := range w.places {
+		boards, err := fetchBoards(ctx, w.c, p.Target, model.AllModes, time.Time{})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", p.Title, err)
+			complete = false
+			continue
+		}
+		for _, b := range boards {
+			if b.Err != nil {
+				complete = false
+				continue
+			}
+			for _, ld := range display.DepartureDisruptions(b.Resp.Departures, b.Resp.Disruptions) {
+				if len(w.lines) == 0 || w.watches(ld.Label) {
+					add(ld.Label, ld.Disruption)
+				}
+			}
+		}
+	}
+	return current, complete
+}
+
+// watches reports whether a line label is one of the resolved --lines.
+func (w *alertWatcher) watches(label string) bool {
+	for _, l := range w.lines {
+		if strings.EqualFold(label, l) {
+			return true
+		}
+	}
+	return false
+}
+
+// diff compares the current disruptions with the known ones and returns
+// the events, sorted by line. Disruptions missing from an incomplete poll
+// are kept rather than reported as cleared.
+func (w *alertWatcher) diff(current map[string]alertItem, complete bool, now time.Time) []notify.Event {
+	var events []notify.Event
+	event := func(kind, id string, item alertItem) notify.Event {
+		return notify.Event{
+			Kind:         kind,
+			DisruptionID: id,
+			Lines:        item.Lines,
+			Effect:       item.Severity.Effect,
+			Severity:     display.SeverityLabel(item.Severity),
+			Message:      item.Message,
+			Time:         now,
+		}
+	}
+
+	for id, item := range current {
+		prev, ok := w.known[id]
+		switch {
+		case !ok:
+			events = append(events, event(notify.New, id, item))
+		case prev.Severity.Effect != item.Severity.Effect:
+			e := event(notify.Changed, id, item)
+			e.PreviousSeverity = display.SeverityLabel(prev.Severity)
+			events = append(events, e)
+		}
+	}
+	for id, item := range w.known {
+		if _, ok := current[id]; ok {
+			continue
+		}
+		if !complete {
+			current[id] = item
+			continue
+		}
+		events = append(events, event(notify.Cleared, id, item))
+	}
+	w.known = current
+
+	sort.Slice(events, func(i, j int) bool {
+		if a, b := strings.Join(events[i].Lines, ","), strings.Join(events[j].Lines, ","); a != b {
+			return a < b
+		}
+		return events[i].DisruptionID < events[j].DisruptionID
+	})
+	return events
+}
+
+// printAlert writes one line per event, e.g.
+// "14:32  NEW      M14  Delays  Trafic perturbé ...".
+func printAlert(w io.Writer, e notify.Event) {
+	kind := map[string]string{
+		notify.New:     "\033[31mNEW    \033[0m",
+		notify.Changed: "\033[33mCHANGED\033[0m",
+		notify.Cleared: "\033[32mCLEARED\033[0m",
+	}[e.Kind]
+	severity := e.Severity
+	if e.Kind == notify.Changed {
+		severity = e.PreviousSeverity + " → " + e.Severity
+	}
+	fmt.Fprintf(w, "%s  %s  \033[1m%s\033[0m  %s  %s\n", e.Time.Format("15:04"), kind, strings.Join(e.Lines, ", "), severity, e.Message)
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/cyrilghali/metro-cli/internal/notify"
+	"github.com/cyrilghali/metro-cli/internal/primtest"
+)
+
+type recordingNotifier struct{ events []notify.Event }
+
+func (r *recordingNotifier) Notify(_ context.Context, e notify.Event) error {
+	r.events = append(r.events, e)
+	return nil
+}
+
+func TestAlertWatcher(t *testing.T) {
+	setupFakePRIM(t)
+	quietInfo = true
+	t.Cleanup(func() { quietInfo = false })
+
+	c, err := newClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recordingNotifier{}
+	w := &alertWatcher{
+		c:         c,
+		lines:     []string{"M14", "RER A"},
+		places:    []namedPlace{{Title: "home", Target: departureTarget{StopID: chatelet.ID, Name: chatelet.Name}}},
+		notifiers: []notify.Notifier{rec},
+		known:     make(map[string]alertItem),
+	}
+
+	// The M14 disruption is reported by both the line and the place: once
+	var out bytes.Buffer
+	w.check(context.Background(), &out)
+	if len(rec.events) != 1 {
+		t.Fatalf("got %d events, want 1: %+v", len(rec.events), rec.events)
+	}
+	e := rec.events[0]
+	if e.Kind != notify.New || e.DisruptionID != "d5b0c7a2-1111" || strings.Join(e.Lines, ",") != "M14" || e.Severity != "Delays" {
+		t.Errorf("event = %+v", e)
+	}
+	if !strings.Contains(out.String(), "M14") || !strings.Contains(out.String(), "Saint-Lazare") {
+		t.Errorf("printed %q", out.String())
+	}
+
+	// Nothing changed: no event
+	w.check(context.Background(), &out)
+	if len(rec.events) != 1 {
+		t.Errorf("unchanged disruption fired again: %+v", rec.events[1:])
+	}
+
+	now := time.Now()
+	worse := map[string]alertItem{"d5b0c7a2-1111": {Lines: []string{"M14"}, Severity: model.Severity{Effect: "NO_SERVICE"}}}
+	if events := w.diff(worse, true, now); len(events) != 1 || events[0].Kind != notify.Changed ||
+		events[0].PreviousSeverity != "Delays" || events[0].Severity != "Interrupted" {
+		t.Errorf("severity change: %+v", events)
+	}
+
+	// A failed poll must not clear anything
+	if events := w.diff(map[string]alertItem{}, false, now); len(events) != 0 {
+		t.Errorf("incomplete poll fired %+v", events)
+	}
+	if events := w.diff(map[string]alertItem{}, true, now); len(events) != 1 || events[0].Kind != notify.Cleared {
+		t.Errorf("clear: %+v", events)
+	}
+}
+
+func TestAlertNeedsTarget(t *testing.T) {
+	setupFakePRIM(t)
+	if _, err := runCLI(t, "alert"); err == nil || !strings.Contains(err.Error(), "nothing to watch") {
+		t.Errorf("expected a usage error, got %v", err)
+	}
+}
+
+func TestAlertAmbiguousLine(t *testing.T) {
+	srv := setupFakePRIM(t)
+	srv.Inject("/v2/navitia/lines", primtest.Response{Status: 200, Body: `{"lines": [
+		{"id": "line:IDFM:C01371", "code": "1", "commercial_mode": {"id": "commercial_mode:Metro", "name": "Métro"}},
+		{"id": "line:IDFM:C01001", "code": "1", "commercial_mode": {"id": "commercial_mode:Bus", "name": "Bus"}}
+	], "disruptions": []}`})
+	_, err := runCLI(t, "alert", "--lines", "1")
+	if err == nil || !strings.Contains(err.Error(), "matches several lines: M1, 1") {
+		t.Errorf("expected an ambiguous line error, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/client"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	boardListen  string
+	boardRefresh time.Duration
+	boardMode    string
+	boardExclude []string
+)
+
+var boardCmd = &cobra.Command{
+	Use:   "board [place...]",
+	Short: "HTML departure board for a hallway screen",
+	Long: `Render a departure board as a web page styled like the platform displays,
+with line colors and disruptions inline.
+
+With --listen, serve the board over HTTP; the page reloads itself every
+--refresh. The page has no external scripts, styles or fonts, so it works
+on an offline LAN. Without --listen, write a single page to stdout.
+
+Without arguments, all saved places are shown (default place first, up
+to 4). Places can be saved aliases, stations or addresses.
+
+Examples:
+  metro board --listen :8080
+  metro board home work --listen 0.0.0.0:8080 --refresh 1m
+  metro board home -m metro > board.html
+  metro board --listen :8080 --exclude-mode bus`,
+	RunE: runBoard,
+}
+
+func init() {
+	boardCmd.Flags().StringVar(&boardListen, "listen", "", "serve the board on this address (e.g. :8080)")
+	boardCmd.Flags().DurationVar(&boardRefresh, "refresh", defaultWatchInterval, "how often the page reloads itself")
+	boardCmd.Flags().StringVarP(&boardMode, "mode", "m", "all", "transport filter, comma-separated (metro, rer, train, tram, bus)")
+	boardCmd.Flags().StringSliceVar(&boardExclude, "exclude-mode", nil, "leave out these transport modes (e.g. bus)")
+	rootCmd.AddCommand(boardCmd)
+}
+
+func runBoard(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if boardRefresh < 5*time.Second {
+		return fmt.Errorf("--refresh interval must be at least 5s (got %s)", boardRefresh)
+	}
+	mode, err := model.ParseModes(boardMode, boardExclude)
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	places, err := resolvePlaces(ctx, c, args, maxPlaces)
+	if err != nil {
+		return err
+	}
+
+	if boardListen == "" {
+		page := fetchBoardPage(ctx, c, places, mode)
+		page.Refresh = 0
+		return display.BoardHTML(os.Stdout, page, time.Now())
+	}
+
+	ln, err := net.Listen("tcp", boardListen)
+	if err != nil {
+		return err
+	}
+	quietInfo = true
+	defer func() { quietInfo = false }()
+	return serveHTTP(ctx, ln, boardHandler(c, places, mode))
+}
+
+// boardHandler serves the board at "/". Every request refetches, so
+// several screens share the response cache rather than the API quota.
+// Each request uses its own client session so that concurrent requests
+// don't take each other's stale data banner.
+func boardHandler(c *client.Client, places []namedPlace, mode model.ModeSet) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		page := fetchBoardPage(r.Context(), c.Session(), places, mode)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := display.BoardHTML(w, page, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "rendering board: %v\n", err)
+		}
+	})
+	return mux
+}
+
+// fetchBoardPage fetches departures for every place. Failures are shown
+// on the board rather than failing the page.
+func fetchBoardPage(ctx context.Context, c *client.Client, places []namedPlace, mode model.ModeSet) display.BoardPage {
+	page := display.BoardPage{Places: make([]display.BoardPlace, len(places)), Refresh: boardRefresh}
+	// One place at a time, as fetchBoards fans out over nearby stops
+	for i, p := range places {
+		bp := display.BoardPlace{Title: p.Title}
+		boards, err := fetchBoards(ctx, c, p.Target, mode, time.Time{})
+		if err != nil {
+			bp.Errors = append(bp.Errors, err.Error())
+		}
+		for _, b := range boards {
+			if b.Err != nil {
+				bp.Errors = append(bp.Errors, fmt.Sprintf("%s: %v", b.Name, b.Err))
+				continue
+			}
+			bp.Departures = append(bp.Departures, b.Resp.Departures...)
+			bp.Disruptions = append(bp.Disruptions, b.Resp.Disruptions...)
+		}
+		page.Places[i] = bp
+	}
+	page.Stale = staleMessage(c)
+	return page
+}
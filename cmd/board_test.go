@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cyrilghali/metro-cli/internal/config"
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+func TestBoardStatic(t *testing.T) {
+	setupFakePRIM(t)
+	saveTestPlaces(t, "home", map[string]config.SavedPlace{"home": chatelet})
+
+	out, err := runCLI(t, "board")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"<!DOCTYPE html>", "home · Châtelet", "background:#FFCD00;color:#000000", ">M14<", "Saint-Lazare"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("board missing %q", want)
+		}
+	}
+	if strings.Contains(out, "http-equiv") {
+		t.Error("a board written to stdout should not reload itself")
+	}
+}
+
+func TestBoardHandler(t *testing.T) {
+	setupFakePRIM(t)
+	quietInfo = true
+	t.Cleanup(func() { quietInfo = false })
+	boardRefresh = defaultWatchInterval
+
+	c, err := newClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	places := []namedPlace{{Title: "work", Target: departureTarget{StopID: chatelet.ID, Name: chatelet.Name}}}
+	srv := httptest.NewServer(boardHandler(c, places, model.AllModes))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Fatalf("GET / = %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	for _, want := range []string{`<meta http-equiv="refresh" content="30">`, "<h1>work</h1>", ">M1<"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("board missing %q", want)
+		}
+	}
+
+	resp, err = http.Get(srv.URL + "/favicon.ico")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /favicon.ico = %d, want 404", resp.StatusCode)
+	}
+}
@@ -0,0 +1,592 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/config"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/cyrilghali/metro-cli/internal/primtest"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// setupFakePRIM points the CLI at a fake PRIM server with an empty HOME.
+func setupFakePRIM(t *testing.T) *primtest.Server {
+	t.Helper()
+	srv := primtest.NewServer()
+	t.Cleanup(srv.Close)
+	t.Setenv("PRIM_TOKEN", "test-token")
+	t.Setenv("PRIM_BASE_URL", srv.URL)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	return srv
+}
+
+// saveTestPlaces writes saved places to the test HOME config.
+func saveTestPlaces(t *testing.T, def string, places map[string]config.SavedPlace) {
+	t.Helper()
+	if err := config.Save(&config.Config{DefaultPlace: def, Places: places}); err != nil {
+		t.Fatalf("saving config: %v", err)
+	}
+}
+
+var chatelet = config.SavedPlace{Name: "Châtelet", Type: "StopArea", ID: "stop_area:IDFM:71264", City: "Paris"}
+
+// runCLI executes the root command with args and returns captured stdout.
+// Flags are reset to their defaults first since cobra keeps them between runs.
+func runCLI(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	resetFlags(rootCmd)
+	stdinReader = bufio.NewReader(strings.NewReader(""))
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	rootCmd.SetArgs(args)
+	runErr := rootCmd.Execute()
+
+	w.Close()
+	os.Stdout = orig
+	return <-out, runErr
+}
+
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			// Set("[]") would append a "[]" element
+			_ = sv.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+func TestDeparturesSavedPlace(t *testing.T) {
+	srv := setupFakePRIM(t)
+	saveTestPlaces(t, "home", map[string]config.SavedPlace{"home": chatelet})
+
+	out, err := runCLI(t, "d", "home")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Châtelet", "M1", "La Défense", "Château de Vincennes", "M14", "Trafic perturbé"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	reqs := srv.Requests()
+	if len(reqs) != 1 || !strings.HasPrefix(reqs[0], "/v2/navitia/stop_areas/stop_area:IDFM:71264/departures") {
+		t.Errorf("unexpected requests: %v", reqs)
+	}
+}
+
+func TestDeparturesAt(t *testing.T) {
+	srv := setupFakePRIM(t)
+	saveTestPlaces(t, "", map[string]config.SavedPlace{"home": chatelet})
+
+	// Fixture departures leave from 14:32; count down from 14:30 that day
+	out, err := runCLI(t, "d", "home", "--at", "2026-02-25 14:30")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Departures from Wed 25 Feb 14:30", "2 min\033[0m \033[33m(+2)\033[0m, \033[36m6 min", "3 min\033[0m  \033[2mscheduled", "4 min"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	req := srv.Requests()[len(srv.Requests())-1]
+	if !strings.Contains(req, "from_datetime=20260225T143000") {
+		t.Errorf("request %q missing from_datetime", req)
+	}
+
+	if _, err := runCLI(t, "d", "home", "--at", "2026-02-25 14:30", "--watch"); err == nil {
+		t.Error("expected --at and --watch to be rejected together")
+	}
+}
+
+func TestDeparturesDefaultPlace(t *testing.T) {
+	setupFakePRIM(t)
+	saveTestPlaces(t, "home", map[string]config.SavedPlace{"home": chatelet})
+
+	out, err := runCLI(t, "d")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "M14") {
+		t.Errorf("expected default place departures, got:\n%s", out)
+	}
+}
+
+func TestDeparturesSearchJSON(t *testing.T) {
+	setupFakePRIM(t)
+
+	out, err := runCLI(t, "d", "chatelet", "-o", "json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var recs []map[string]any
+	if err := json.Unmarshal([]byte(out), &recs); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, out)
+	}
+	if len(recs) != 4 {
+		t.Fatalf("expected 4 departures, got %d", len(recs))
+	}
+	for _, r := range recs {
+		if r["line"] == "M14" && r["disruption_severity"] != "SIGNIFICANT_DELAYS" {
+			t.Errorf("expected M14 to carry its disruption severity, got %v", r)
+		}
+		if r["stop_id"] != "stop_area:IDFM:71264" {
+			t.Errorf("unexpected stop_id in %v", r)
+		}
+	}
+}
+
+func TestDeparturesAddressNearby(t *testing.T) {
+	srv := setupFakePRIM(t)
+
+	out, err := runCLI(t, "d", "rivoli")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "Châtelet les Halles") {
+		t.Errorf("expected nearby stop areas, got:\n%s", out)
+	}
+
+	var departures int
+	for _, r := range srv.Requests() {
+		if strings.Contains(r, "/departures") {
+			departures++
+		}
+	}
+	if departures != 2 {
+		t.Errorf("expected one departures request per nearby stop area, got %d", departures)
+	}
+}
+
+func TestDeparturesModeList(t *testing.T) {
+	srv := setupFakePRIM(t)
+	saveTestPlaces(t, "home", map[string]config.SavedPlace{"home": chatelet})
+
+	if _, err := runCLI(t, "d", "home", "-m", "metro,rer"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := runCLI(t, "d", "home", "--exclude-mode", "bus,train,tram"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var filters []string
+	for _, r := range srv.Requests() {
+		if strings.Contains(r, "/departures") {
+			_, filter, _ := strings.Cut(r, "filter=")
+			filters = append(filters, filter)
+		}
+	}
+	// One request per mode; the same modes by exclusion hit the cache
+	if len(filters) != 2 || !strings.Contains(filters[0], "Metro") || !strings.Contains(filters[1], "RapidTransit") {
+		t.Errorf("departures filters = %q", filters)
+	}
+
+	if _, err := runCLI(t, "d", "home", "-m", "metro", "--exclude-mode", "metro"); err == nil {
+		t.Error("expected an error when every mode is excluded")
+	}
+}
+
+func TestDeparturesLineDirection(t *testing.T) {
+	setupFakePRIM(t)
+	work := chatelet
+	work.Lines, work.Direction = []string{"M1"}, "defense"
+	saveTestPlaces(t, "", map[string]config.SavedPlace{"home": chatelet, "work": work})
+
+	jsonLines := func(out string) map[string]bool {
+		t.Helper()
+		var recs []map[string]any
+		if err := json.Unmarshal([]byte(out), &recs); err != nil {
+			t.Fatalf("stdout is not JSON: %v\n%s", err, out)
+		}
+		seen := make(map[string]bool)
+		for _, r := range recs {
+			seen[r["line"].(string)+" "+r["direction"].(string)] = true
+		}
+		return seen
+	}
+
+	out, err := runCLI(t, "d", "home", "--line", "M1", "--direction", "la def", "-o", "json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := jsonLines(out); len(got) != 1 || !got["M1 La Défense (Grande Arche)"] {
+		t.Errorf("--line M1 --direction \"la def\" kept %v", got)
+	}
+
+	// The saved place's filters apply, and flags override them
+	out, err = runCLI(t, "d", "work", "-o", "json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := jsonLines(out); len(got) != 1 || !got["M1 La Défense (Grande Arche)"] {
+		t.Errorf("work kept %v", got)
+	}
+	out, err = runCLI(t, "d", "work", "--direction", "vincennes", "-o", "json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := jsonLines(out); len(got) != 1 || !got["M1 Château de Vincennes"] {
+		t.Errorf("work --direction vincennes kept %v", got)
+	}
+
+	out, err = runCLI(t, "d", "home", "--line", "RER A")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "no upcoming departures") {
+		t.Errorf("expected no departures for RER A, got:\n%s", out)
+	}
+}
+
+func TestDisruptionsMode(t *testing.T) {
+	setupFakePRIM(t)
+
+	out, err := runCLI(t, "dis", "-m", "metro")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"M1", "OK", "M14", "Delays"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDisruptionsAllCSV(t *testing.T) {
+	setupFakePRIM(t)
+
+	out, err := runCLI(t, "dis", "-o", "csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if lines[0] != "line,mode,status,severity,severity_name,disruption_id,message" {
+		t.Errorf("unexpected CSV header: %q", lines[0])
+	}
+	if len(lines) != 5 {
+		t.Fatalf("expected header + 4 lines (M1, M14, RER A, RER B), got:\n%s", out)
+	}
+	if !strings.HasPrefix(lines[2], "M14,metro,disrupted,SIGNIFICANT_DELAYS") {
+		t.Errorf("unexpected M14 row: %q", lines[2])
+	}
+}
+
+func TestPlacesListYAML(t *testing.T) {
+	setupFakePRIM(t)
+	saveTestPlaces(t, "home", map[string]config.SavedPlace{"home": chatelet})
+
+	out, err := runCLI(t, "places", "-o", "yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "alias: home") || !strings.Contains(out, "default: true") {
+		t.Errorf("unexpected YAML:\n%s", out)
+	}
+}
+
+func TestPlacesEdit(t *testing.T) {
+	setupFakePRIM(t)
+	saveTestPlaces(t, "home", map[string]config.SavedPlace{"home": chatelet})
+
+	if _, err := runCLI(t, "places", "edit", "home", "-m", "metro", "--line", "M1", "--walk", "3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	home := cfg.Places["home"]
+	if strings.Join(home.Modes, ",") != "metro" || strings.Join(home.Lines, ",") != "M1" || home.WalkMinutes != 3 || home.ID != chatelet.ID {
+		t.Errorf("edited place = %+v", home)
+	}
+
+	// Fixture departures leave from 14:32; a 3 min walk from 14:30 misses
+	// only the first M1
+	out, err := runCLI(t, "d", "home", "--at", "2026-02-25 14:30", "-o", "json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var recs []map[string]any
+	if err := json.Unmarshal([]byte(out), &recs); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, out)
+	}
+	if len(recs) != 2 {
+		t.Errorf("expected 2 reachable M1 departures, got %d:\n%s", len(recs), out)
+	}
+
+	if _, err := runCLI(t, "places", "edit", "home", "--walk", "90"); err == nil {
+		t.Error("expected an error for a 90 min walk")
+	}
+	if _, err := runCLI(t, "places", "edit", "home", "--line", "", "--walk", "0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg, _ = config.Load(); cfg.Places["home"].Lines != nil || cfg.Places["home"].WalkMinutes != 0 {
+		t.Errorf("expected cleared settings, got %+v", cfg.Places["home"])
+	}
+}
+
+func TestPromptPlaceSettings(t *testing.T) {
+	saved := config.SavedPlace{Name: "Châtelet", Lines: []string{"M1"}, Direction: "defense", Radius: 800}
+	stdinReader = bufio.NewReader(strings.NewReader("rer\n\n-\n\n5\n"))
+	if err := promptPlaceSettings(&saved); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(saved.Modes, ",") != "rer" || strings.Join(saved.Lines, ",") != "M1" || saved.Direction != "" ||
+		saved.Radius != 800 || saved.WalkMinutes != 5 {
+		t.Errorf("prompted place = %+v", saved)
+	}
+}
+
+func TestJourneys(t *testing.T) {
+	setupFakePRIM(t)
+	saveTestPlaces(t, "", map[string]config.SavedPlace{"work": chatelet})
+
+	out, err := runCLI(t, "go", "rivoli", "work", "--depart", "08:10")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"08:12 → 08:33", "21 min", "direct", "M1", "Walk"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	if _, err := runCLI(t, "go", "rivoli", "work", "-n", "0"); err == nil {
+		t.Error("expected error for --count 0")
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	srv := setupFakePRIM(t)
+	saveTestPlaces(t, "", map[string]config.SavedPlace{"home": chatelet})
+
+	out, err := runCLI(t, "schedule", "home", "--line", "M1", "--date", "2026-02-25")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"departures on Wed 25 Feb", "La Défense", "Château de Vincennes", "05:31", "01:10", "05\033[0m │"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	var req string
+	for _, r := range srv.Requests() {
+		if strings.Contains(r, "/stop_schedules") {
+			req = r
+		}
+	}
+	for _, want := range []string{"from_datetime=20260225T040000", "duration=86400", "line.code%3D%221%22"} {
+		if !strings.Contains(req, want) {
+			t.Errorf("request %q missing %q", req, want)
+		}
+	}
+}
+
+func TestArrivalsJSON(t *testing.T) {
+	setupFakePRIM(t)
+	saveTestPlaces(t, "", map[string]config.SavedPlace{"home": chatelet})
+
+	out, err := runCLI(t, "arrivals", "home", "--date", "2026-02-25", "-o", "json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var recs []display.TimetableRecord
+	if err := json.Unmarshal([]byte(out), &recs); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(recs) != 5 {
+		t.Fatalf("expected 5 arrivals, got %d", len(recs))
+	}
+	if recs[0].Line != "M1" || recs[0].Direction != "Château de Vincennes" || recs[0].Time != "2026-02-25T05:28:00+01:00" {
+		t.Errorf("unexpected first record: %+v", recs[0])
+	}
+}
+
+func TestMissingToken(t *testing.T) {
+	setupFakePRIM(t)
+	t.Setenv("PRIM_TOKEN", "")
+
+	_, err := runCLI(t, "dis")
+	if err == nil || !strings.Contains(err.Error(), "PRIM_TOKEN not set") {
+		t.Errorf("expected missing token error, got %v", err)
+	}
+}
+
+func TestInvalidOutputFormat(t *testing.T) {
+	setupFakePRIM(t)
+
+	_, err := runCLI(t, "dis", "-o", "xml")
+	if err == nil || !strings.Contains(err.Error(), "unknown output format") {
+		t.Errorf("expected output format error, got %v", err)
+	}
+}
+
+func TestDeparturesOffline(t *testing.T) {
+	srv := setupFakePRIM(t)
+	saveTestPlaces(t, "home", map[string]config.SavedPlace{"home": chatelet})
+
+	if _, err := runCLI(t, "d", "home", "--offline"); err == nil || !strings.Contains(err.Error(), "offline") {
+		t.Fatalf("expected offline error with an empty cache, got %v", err)
+	}
+	if _, err := runCLI(t, "d", "home"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := runCLI(t, "d", "home", "--offline")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "M14") {
+		t.Errorf("expected cached board, got:\n%s", out)
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("expected a single network request, got %d", n)
+	}
+}
+
+func TestDisruptionsCached(t *testing.T) {
+	srv := setupFakePRIM(t)
+
+	for i := 0; i < 2; i++ {
+		if _, err := runCLI(t, "dis", "-m", "metro"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("expected line status to be served from cache, got %d requests", n)
+	}
+}
+
+func TestDisruptionShow(t *testing.T) {
+	setupFakePRIM(t)
+
+	for _, arg := range []string{"M14", "d5b0c7a2"} {
+		out, err := runCLI(t, "dis", "show", arg)
+		if err != nil {
+			t.Fatalf("dis show %s: %v", arg, err)
+		}
+		for _, want := range []string{"M14", "d5b0c7a2-1111", "Wed 25 Feb 05:00 → 23:59", "perturbation", "Incidents",
+			"Trafic perturbé entre Saint-Lazare et Olympiades - Incident"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("dis show %s: output missing %q:\n%s", arg, want, out)
+			}
+		}
+	}
+
+	if _, err := runCLI(t, "dis", "show", "M1"); err == nil || !strings.Contains(err.Error(), `no disruption or line matching "M1"`) {
+		t.Errorf("dis show M1: err = %v", err)
+	}
+}
+
+func TestFindDisruptionsByID(t *testing.T) {
+	all := []labeledDisruption{
+		{Disruption: model.Disruption{ID: "8F2A6C1E-0001", DisruptionID: "D5B0C7A2-1111"}},
+		{Disruption: model.Disruption{ID: "8f2a6c1e-0002", DisruptionID: "e7c1d9f0-2222"}},
+	}
+	for _, query := range []string{"d5b0c7a2-1111", "D5B0", "8f2a6c1e-0001"} {
+		if found := findDisruptions(all, query); len(found) != 1 || found[0].Disruption.DisruptionID != "D5B0C7A2-1111" {
+			t.Errorf("findDisruptions(%q) = %+v", query, found)
+		}
+	}
+	if found := findDisruptions(all, "8F2A"); len(found) != 2 {
+		t.Errorf("findDisruptions(8F2A) found %d, want 2", len(found))
+	}
+}
+
+func TestDisruptionsUpcoming(t *testing.T) {
+	setupFakePRIM(t)
+	quietInfo = true
+	t.Cleanup(func() { quietInfo = false })
+
+	c, err := newClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Friday 20 February 2026, noon
+	now := time.Date(2026, 2, 20, 12, 0, 0, 0, display.Paris())
+	until := now.AddDate(0, 0, 7)
+	statuses, err := fetchUpcoming(context.Background(), c, model.AllModes, now, until)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := renderUpcoming(&buf, statuses, now, until); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Planned disruptions until Fri 27 Feb", "Sat 21 – Sun 22, all day", "Mon 23 – Thu 26, 22:00–05:00 nightly", "Wed 25, 05:00–23:59"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	// RER A's works begin first; RER B's are in March
+	if strings.Index(out, "RER A") > strings.Index(out, "M14") || strings.Contains(out, "RER B") {
+		t.Errorf("unexpected lines or order:\n%s", out)
+	}
+
+	if _, err := runCLI(t, "dis", "--days", "14"); err == nil || !strings.Contains(err.Error(), "--upcoming") {
+		t.Errorf("expected --days without --upcoming to fail, got %v", err)
+	}
+}
+
+func TestLine(t *testing.T) {
+	setupFakePRIM(t)
+
+	out, err := runCLI(t, "line", "RER", "B")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Two branches; the Massy-Palaiseau short run is part of the second
+	for _, want := range []string{"1  Robinson → Aéroport Charles de Gaulle 2 TGV", "2  Saint-Rémy-lès-Chevreuse → Mitry-Claye", "M4 M5 RER D"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "3  ") {
+		t.Errorf("short run shown as a branch:\n%s", out)
+	}
+
+	csv, err := runCLI(t, "line", "RER B", "--reverse", "-o", "csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv), "\n")
+	if len(lines) != 9 || !strings.HasPrefix(lines[1], "RER B,stop_area:IDFM:73596,Aéroport Charles de Gaulle 2 TGV,1,") {
+		t.Errorf("unexpected CSV:\n%s", csv)
+	}
+
+	if _, err := runCLI(t, "line", "M7"); err == nil || !strings.Contains(err.Error(), `no line matching "M7"`) {
+		t.Errorf("line M7: err = %v", err)
+	}
+}
@@ -0,0 +1,478 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/client"
+	"github.com/cyrilghali/metro-cli/internal/config"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	commuteDays string
+	commuteTime string
+	commuteDest string
+)
+
+var commuteCmd = &cobra.Command{
+	Use:   "commute",
+	Short: "Show departures and line status for the current commute",
+	Long: `Show the departure board of the place your commute starts from right
+now, followed by the status of the lines you take.
+
+Commute rules pick a saved place by day and time, for example home on
+weekday mornings and work in the evening. The first rule matching the
+current time (Paris time) wins, and "metro d" with no station uses it
+before the default place.
+
+A rule with a destination plans the journey to get the lines of that
+leg, and the board keeps only those lines. Without one, the saved
+place's --line setting is used, or every line on the board.
+
+With --output, the departures are written as records, as for "metro d".
+
+Examples:
+  metro commute add home --days weekdays --time 06:00-11:00 --to work
+  metro commute add work --days weekdays --time 16:00-21:00 --to home
+  metro commute add home --days sat,sun --time 09:00-13:00
+  metro commute list
+  metro commute remove 2
+
+  metro commute                         # board and line status now
+  metro d                               # board of the commute place`,
+	Args: cobra.NoArgs,
+	RunE: runCommute,
+}
+
+var commuteAddCmd = &cobra.Command{
+	Use:   "add <place>",
+	Short: "Add a commute rule for a saved place",
+	Long: `Use a saved place during a time window on some days.
+
+--days takes "weekdays" (default), "weekends", "daily" or days like
+"mon,wed,fri". --time is a window like 06:00-11:00; one ending before
+it starts runs past midnight (22:00-02:00). --to is where the commute
+goes: a saved place, station or address.
+
+Examples:
+  metro commute add home --time 06:00-11:00 --to work
+  metro commute add work --days weekdays --time 16:00-21:00 --to home
+  metro commute add gym --days tue,thu --time 18:00-20:00`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCommuteAdd,
+}
+
+var commuteListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List commute rules",
+	Args:    cobra.NoArgs,
+	RunE:    runCommuteList,
+}
+
+var commuteRemoveCmd = &cobra.Command{
+	Use:     "remove <number>",
+	Aliases: []string{"rm", "delete"},
+	Short:   "Remove a commute rule by its number in \"metro commute list\"",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runCommuteRemove,
+}
+
+func init() {
+	commuteAddCmd.Flags().StringVar(&commuteDays, "days", "weekdays", "weekdays, weekends, daily or days like mon,wed,fri")
+	commuteAddCmd.Flags().StringVar(&commuteTime, "time", "", "time window, e.g. 06:00-11:00 (required)")
+	commuteAddCmd.Flags().StringVar(&commuteDest, "to", "", "destination: saved place, station or address")
+	commuteAddCmd.MarkFlagRequired("time")
+	commuteCmd.AddCommand(commuteAddCmd)
+	commuteCmd.AddCommand(commuteListCmd)
+	commuteCmd.AddCommand(commuteRemoveCmd)
+	rootCmd.AddCommand(commuteCmd)
+}
+
+func runCommute(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	now := time.Now()
+	rule, ok := activeCommute(cfg.Commute, now)
+	if !ok {
+		if len(cfg.Commute) == 0 {
+			return fmt.Errorf("no commute rules\nAdd one: metro commute add home --days weekdays --time 06:00-11:00 --to work")
+		}
+		return fmt.Errorf("no commute rule for %s\nSee: metro commute list", now.In(display.Paris()).Format("Mon 15:04"))
+	}
+	saved, ok := cfg.Places[rule.Place]
+	if !ok {
+		return fmt.Errorf("commute place \"%s\" not found in saved places\nRun: metro places save %s <station>", rule.Place, rule.Place)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	infof("Commute: %s\n\n", describeRule(rule))
+	target, err := savedPlaceTarget(ctx, c, saved)
+	if err != nil {
+		return err
+	}
+
+	var journey *model.Journey
+	if rule.Destination != "" {
+		if journey, err = planCommute(ctx, c, target, rule.Destination); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Could not plan the journey to %s, showing every line: %v\n", rule.Destination, err)
+		}
+		// The board keeps the lines of the leg unless the place has its own
+		if journey != nil && len(target.Lines) == 0 {
+			target.Lines = display.JourneyLines(*journey)
+		}
+	}
+
+	mode := model.AllModes
+	if target.Modes != nil {
+		mode = target.Modes
+	}
+	boards, err := fetchBoards(ctx, c, target, mode, time.Time{})
+	if err != nil {
+		return err
+	}
+	if outputFormat.IsStructured() {
+		printStale(c)
+		return renderBoards(os.Stdout, boards, mode, time.Now())
+	}
+
+	lines := target.Lines
+	if len(lines) == 0 {
+		lines = boardLines(boards)
+	}
+	statuses, err := fetchCommuteStatuses(ctx, c, lines)
+	if err != nil {
+		return err
+	}
+	printStale(c)
+
+	if journey != nil {
+		display.JourneySummary(os.Stdout, *journey)
+		fmt.Println()
+	}
+	if err := renderBoards(os.Stdout, boards, mode, time.Now()); err != nil {
+		return err
+	}
+	fmt.Println("\033[1mLine status\033[0m")
+	for _, st := range statuses {
+		if st.Err != nil {
+			fmt.Printf("  \033[31mError fetching %s: %v\033[0m\n", st.Mode.Name, st.Err)
+		}
+	}
+	display.LineStatuses(os.Stdout, commuteLineStatus(statuses, lines))
+	return nil
+}
+
+// planCommute plans the best journey from target to dest, or nil when
+// none is found.
+func planCommute(ctx context.Context, c *client.Client, target departureTarget, dest string) (*model.Journey, error) {
+	to, err := resolveEndpoint(ctx, c, dest)
+	if err != nil {
+		return nil, err
+	}
+	infof("Planning journey...\n")
+	resp, err := c.Journeys(ctx, target.journeyPlace(), to.journeyPlace(), "", false, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Journeys) == 0 {
+		infof("No itinerary found to %s, showing every line\n", dest)
+		return nil, nil
+	}
+	return &resp.Journeys[0], nil
+}
+
+// boardLines returns the labels of the lines departing on boards, in
+// board order.
+func boardLines(boards []stopBoard) []string {
+	var labels []string
+	for _, b := range boards {
+		if b.Err != nil {
+			continue
+		}
+		for _, d := range b.Resp.Departures {
+			label := model.LineLabel(d.DisplayInformations.Code, d.DisplayInformations.CommercialMode)
+			if !slices.Contains(labels, label) {
+				labels = append(labels, label)
+			}
+		}
+	}
+	return labels
+}
+
+// lineModes returns the modes a line label may belong to: its own, or
+// bus and train for a bare code like "27".
+func lineModes(label string) []string {
+	if mode, _ := model.ParseLineLabel(label); mode != "" {
+		return []string{mode}
+	}
+	return []string{"bus", "train"}
+}
+
+// fetchCommuteStatuses fetches line status for the modes of lines.
+func fetchCommuteStatuses(ctx context.Context, c *client.Client, lines []string) ([]modeStatus, error) {
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	var names []string
+	for _, l := range lines {
+		names = append(names, lineModes(l)...)
+	}
+	mode, err := model.ParseModes(strings.Join(names, ","), nil)
+	if err != nil {
+		return nil, err
+	}
+	return fetchStatuses(ctx, c, mode)
+}
+
+// commuteLineStatus picks the status records of lines from statuses,
+// in the order of lines.
+func commuteLineStatus(statuses []modeStatus, lines []string) []display.LineStatusRecord {
+	var recs []display.LineStatusRecord
+	for _, l := range lines {
+		modes := lineModes(l)
+		for _, st := range statuses {
+			if st.Err != nil || !slices.Contains(modes, st.Mode.Name) {
+				continue
+			}
+			for _, r := range display.LineStatusRecords(st.Resp, "", st.Mode) {
+				if model.MatchLine(r.Line, l) && !slices.Contains(recs, r) {
+					recs = append(recs, r)
+				}
+			}
+		}
+	}
+	return recs
+}
+
+func runCommuteAdd(cmd *cobra.Command, args []string) error {
+	alias := strings.ToLower(args[0])
+	from, to, ok := strings.Cut(commuteTime, "-")
+	if !ok {
+		return fmt.Errorf("invalid --time %q (use a window like 06:00-11:00)", commuteTime)
+	}
+	rule := config.CommuteRule{
+		Days:        strings.ToLower(strings.TrimSpace(commuteDays)),
+		From:        strings.TrimSpace(from),
+		To:          strings.TrimSpace(to),
+		Place:       alias,
+		Destination: strings.TrimSpace(commuteDest),
+	}
+	if err := checkRule(rule); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if _, ok := cfg.Places[alias]; !ok {
+		return fmt.Errorf("no saved place named \"%s\"\nRun: metro places save %s <station>", alias, alias)
+	}
+	cfg.Commute = append(cfg.Commute, rule)
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+
+	fmt.Printf("Added commute rule %d: %s\n", len(cfg.Commute), describeRule(rule))
+	return nil
+}
+
+func runCommuteList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if len(cfg.Commute) == 0 {
+		fmt.Println("No commute rules.")
+		fmt.Println("\nAdd one with:")
+		fmt.Println("  metro commute add home --days weekdays --time 06:00-11:00 --to work")
+		return nil
+	}
+
+	active, _ := activeRuleIndex(cfg.Commute, time.Now())
+	fmt.Print("Commute rules:\n\n")
+	for i, r := range cfg.Commute {
+		now := ""
+		if i == active {
+			now = "  \033[32m(now)\033[0m"
+		}
+		fmt.Printf("  %d. %s%s\n", i+1, describeRule(r), now)
+	}
+	if cfg.DefaultPlace != "" {
+		fmt.Printf("\nOtherwise: %s (default place)\n", cfg.DefaultPlace)
+	}
+	return nil
+}
+
+func runCommuteRemove(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(cfg.Commute) {
+		return fmt.Errorf("no commute rule %s (see: metro commute list)", args[0])
+	}
+
+	rule := cfg.Commute[n-1]
+	cfg.Commute = slices.Delete(cfg.Commute, n-1, n)
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+
+	fmt.Printf("Removed commute rule %d: %s\n", n, describeRule(rule))
+	return nil
+}
+
+// describeRule describes a commute rule, e.g.
+// "home → work, weekdays 06:00–11:00".
+func describeRule(r config.CommuteRule) string {
+	leg := r.Place
+	if r.Destination != "" {
+		leg += " → " + r.Destination
+	}
+	days := r.Days
+	if days == "" {
+		days = "daily"
+	}
+	return fmt.Sprintf("%s, %s %s–%s", leg, days, r.From, r.To)
+}
+
+// commutePlace returns the saved place alias "metro d" uses without a
+// station: the place of the commute rule applying at now, else the
+// default place. rule is nil when no commute rule applies.
+func commutePlace(cfg *config.Config, now time.Time) (alias string, rule *config.CommuteRule) {
+	if i, ok := activeRuleIndex(cfg.Commute, now); ok {
+		return cfg.Commute[i].Place, &cfg.Commute[i]
+	}
+	return cfg.DefaultPlace, nil
+}
+
+// activeCommute returns the first commute rule applying at now.
+func activeCommute(rules []config.CommuteRule, now time.Time) (config.CommuteRule, bool) {
+	i, ok := activeRuleIndex(rules, now)
+	if !ok {
+		return config.CommuteRule{}, false
+	}
+	return rules[i], true
+}
+
+// activeRuleIndex returns the index of the first rule applying at now.
+// Invalid rules never apply.
+func activeRuleIndex(rules []config.CommuteRule, now time.Time) (int, bool) {
+	for i, r := range rules {
+		if ok, err := ruleApplies(r, now); err == nil && ok {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// ruleApplies reports whether a commute rule applies at now, in Paris
+// time. A window ending before it starts runs past midnight and belongs
+// to the day it starts on.
+func ruleApplies(r config.CommuteRule, now time.Time) (bool, error) {
+	days, err := parseDays(r.Days)
+	if err != nil {
+		return false, err
+	}
+	from, err := ruleClock(r.From)
+	if err != nil {
+		return false, err
+	}
+	to, err := ruleClock(r.To)
+	if err != nil {
+		return false, err
+	}
+
+	now = now.In(display.Paris())
+	offset := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+	if from < to {
+		return days[now.Weekday()] && offset >= from && offset < to, nil
+	}
+	if offset >= from {
+		return days[now.Weekday()], nil
+	}
+	yesterday := (now.Weekday() + 6) % 7
+	return days[yesterday] && offset < to, nil
+}
+
+// checkRule validates a commute rule's days and window.
+func checkRule(r config.CommuteRule) error {
+	if _, err := parseDays(r.Days); err != nil {
+		return err
+	}
+	from, err := ruleClock(r.From)
+	if err != nil {
+		return err
+	}
+	to, err := ruleClock(r.To)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		return fmt.Errorf("empty time window %s-%s", r.From, r.To)
+	}
+	return nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// parseDays parses a rule's days: "weekdays", "weekends", "daily" (or
+// empty), or a comma-separated list of day names like "mon,wed,fri" or
+// "monday,friday".
+func parseDays(s string) ([7]bool, error) {
+	var days [7]bool
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "daily", "everyday":
+		return [7]bool{true, true, true, true, true, true, true}, nil
+	case "weekdays":
+		return [7]bool{false, true, true, true, true, true, false}, nil
+	case "weekends", "weekend":
+		return [7]bool{true, false, false, false, false, false, true}, nil
+	}
+	for _, name := range strings.Split(s, ",") {
+		d, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return days, fmt.Errorf("invalid day %q (use weekdays, weekends, daily or days like mon,wed,fri)", strings.TrimSpace(name))
+		}
+		days[d] = true
+	}
+	return days, nil
+}
+
+// ruleClock parses a rule's "HH:MM" time as an offset from midnight,
+// as parseClock, with "24:00" ending a window at midnight.
+func ruleClock(s string) (time.Duration, error) {
+	if strings.TrimSpace(s) == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	d, err := parseClock(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (use HH:MM)", s)
+	}
+	return d, nil
+}
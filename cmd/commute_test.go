@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/config"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/primtest"
+)
+
+func TestRuleApplies(t *testing.T) {
+	// 2026-02-25 is a Wednesday
+	at := func(s string) time.Time {
+		t, _ := time.ParseInLocation("2006-01-02 15:04", s, display.Paris())
+		return t
+	}
+	morning := config.CommuteRule{Days: "weekdays", From: "06:00", To: "11:00", Place: "home"}
+	night := config.CommuteRule{Days: "fri,sat", From: "22:00", To: "02:00", Place: "bar"}
+	allDay := config.CommuteRule{From: "00:00", To: "24:00", Place: "home"}
+
+	tests := []struct {
+		rule config.CommuteRule
+		now  string
+		want bool
+	}{
+		{morning, "2026-02-25 06:00", true},
+		{morning, "2026-02-25 10:59", true},
+		{morning, "2026-02-25 11:00", false},
+		{morning, "2026-02-25 05:59", false},
+		{morning, "2026-02-28 08:00", false}, // Saturday
+		{night, "2026-02-27 23:30", true},    // Friday night
+		{night, "2026-02-28 01:30", true},    // still Friday's window
+		{night, "2026-02-28 02:00", false},
+		{night, "2026-03-01 01:30", true}, // Saturday's window
+		{night, "2026-03-02 01:30", false},
+		{night, "2026-02-26 23:30", false}, // Thursday
+		{allDay, "2026-03-01 00:00", true},
+		{allDay, "2026-03-01 23:59", true},
+	}
+	for _, tt := range tests {
+		got, err := ruleApplies(tt.rule, at(tt.now))
+		if err != nil {
+			t.Fatalf("ruleApplies(%+v): %v", tt.rule, err)
+		}
+		if got != tt.want {
+			t.Errorf("ruleApplies(%s, %s) = %v, want %v", describeRule(tt.rule), tt.now, got, tt.want)
+		}
+	}
+
+	// The first applying rule wins, and its place replaces the default
+	cfg := &config.Config{DefaultPlace: "home", Commute: []config.CommuteRule{
+		{Days: "weekdays", From: "16:00", To: "21:00", Place: "work"},
+		{Days: "daily", From: "18:00", To: "19:00", Place: "gym"},
+	}}
+	if alias, rule := commutePlace(cfg, at("2026-02-25 18:30")); alias != "work" || rule == nil {
+		t.Errorf("weekday evening: %q", alias)
+	}
+	if alias, _ := commutePlace(cfg, at("2026-02-28 18:30")); alias != "gym" {
+		t.Errorf("Saturday evening: %q", alias)
+	}
+	if alias, rule := commutePlace(cfg, at("2026-02-25 12:00")); alias != "home" || rule != nil {
+		t.Errorf("noon: %q", alias)
+	}
+
+	for _, bad := range []config.CommuteRule{
+		{Days: "someday", From: "06:00", To: "11:00"},
+		{Days: "monkey,sunshine", From: "06:00", To: "11:00"},
+		{Days: "weekdays", From: "6h", To: "11:00"},
+		{Days: "weekdays", From: "06:00", To: "24:30"},
+		{Days: "weekdays", From: "08:00", To: "08:00"},
+	} {
+		if err := checkRule(bad); err == nil {
+			t.Errorf("checkRule(%+v) expected error", bad)
+		}
+	}
+}
+
+func TestCommute(t *testing.T) {
+	srv := setupFakePRIM(t)
+	saveTestPlaces(t, "", map[string]config.SavedPlace{
+		"home": chatelet,
+		"work": {Name: "La Défense", Type: "StopArea", ID: "stop_area:IDFM:71517", City: "Puteaux"},
+	})
+
+	if _, err := runCLI(t, "commute"); err == nil || !strings.Contains(err.Error(), "no commute rules") {
+		t.Errorf("expected no rules error, got %v", err)
+	}
+	if _, err := runCLI(t, "commute", "add", "gym", "--time", "06:00-11:00"); err == nil {
+		t.Error("expected error for an unknown place")
+	}
+	if _, err := runCLI(t, "commute", "add", "home", "--time", "06:00"); err == nil {
+		t.Error("expected error for a time without window")
+	}
+	if _, err := runCLI(t, "commute", "add", "home", "--days", "daily", "--time", "00:00-24:00", "--to", "work"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runCLI(t, "commute", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "1. home → work, daily 00:00–24:00") || !strings.Contains(out, "(now)") {
+		t.Errorf("unexpected list:\n%s", out)
+	}
+
+	// The fixture journey rides M1: the board keeps M1, and only its status
+	// (OK, while M14 has delays) is shown
+	out, err = runCLI(t, "commute")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"08:12 → 08:33", "Châtelet", "La Défense", "Line status", "OK"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "M14") {
+		t.Errorf("M14 is not on the commute:\n%s", out)
+	}
+
+	// Without a journey, the board falls back to every line at the place
+	t.Setenv("XDG_CACHE_HOME", t.TempDir()) // so the journey is refetched
+	srv.Inject("/v2/navitia/journeys", primtest.Response{Status: 400, Body: `{"message":"no solution"}`})
+	out, err = runCLI(t, "commute")
+	if err != nil {
+		t.Fatalf("a failed journey must not fail the commute: %v", err)
+	}
+	if !strings.Contains(out, "M14") {
+		t.Errorf("expected every line at the place:\n%s", out)
+	}
+
+	// metro d without a station uses the commute place
+	out, err = runCLI(t, "d", "-o", "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, chatelet.ID) {
+		t.Errorf("expected the home board:\n%s", out)
+	}
+
+	if _, err := runCLI(t, "places", "remove", "home"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Commute) != 0 {
+		t.Errorf("rules from a removed place should go: %+v", cfg.Commute)
+	}
+	if _, err := runCLI(t, "commute", "remove", "1"); err == nil {
+		t.Error("expected error removing a missing rule")
+	}
+}
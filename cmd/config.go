@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/cyrilghali/metro-cli/internal/client"
 	"github.com/cyrilghali/metro-cli/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -13,8 +14,13 @@ var configCmd = &cobra.Command{
 	Short: "Show metro CLI configuration",
 	Long: `Show the current metro CLI configuration.
 
-Config file:   ~/.metro.toml  (saved places and default)
+Config file:   ~/.metro.toml  (saved places, default and commute rules)
 API token:     PRIM_TOKEN environment variable
+API base URL:  PRIM_BASE_URL environment variable, or base_url in the
+               config file (defaults to the public PRIM endpoint)
+Cache:         API responses, in the user cache directory
+History:       recorded disruptions, in the user data directory (opt-in,
+               see "metro history")
 
 Examples:
   metro config`,
@@ -40,6 +46,25 @@ func runConfig(cmd *cobra.Command, args []string) error {
 		fmt.Println("  PRIM_TOKEN:     (not set)")
 	}
 
+	// API endpoint (only shown when overridden)
+	if base := os.Getenv("PRIM_BASE_URL"); base != "" {
+		fmt.Printf("  API base URL:   %s (PRIM_BASE_URL)\n", base)
+	} else if cfg.BaseURL != "" {
+		fmt.Printf("  API base URL:   %s\n", cfg.BaseURL)
+	}
+
+	if dir, err := client.DefaultCacheDir(); err == nil {
+		fmt.Printf("  Cache:          %s\n", dir)
+	}
+
+	if path, err := historyPath(); err == nil {
+		if cfg.History {
+			fmt.Printf("  History:        on, %s\n", path)
+		} else {
+			fmt.Println("  History:        off (\"metro history enable\" to record disruptions)")
+		}
+	}
+
 	// Default place
 	if cfg.DefaultPlace != "" {
 		if p, ok := cfg.Places[cfg.DefaultPlace]; ok {
@@ -58,6 +83,11 @@ func runConfig(cmd *cobra.Command, args []string) error {
 		fmt.Println("  Saved places:   (none)")
 	}
 
+	// Commute rules
+	if len(cfg.Commute) > 0 {
+		fmt.Printf("  Commute rules:  %d (run \"metro commute list\" to view)\n", len(cfg.Commute))
+	}
+
 	// Setup hints
 	needsSetup := os.Getenv("PRIM_TOKEN") == "" || len(cfg.Places) == 0
 	if needsSetup {
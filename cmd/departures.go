@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -22,6 +25,10 @@ var (
 	hereLAN      bool
 	hereCacheTTL time.Duration
 	modeFlag     string
+	excludeModes []string
+	departLines  []string
+	departDir    string
+	departAt     string
 
 	stdinReader = bufio.NewReader(os.Stdin)
 )
@@ -44,8 +51,17 @@ Modes:
   tram    Tramway lines T1-T13
   bus     Bus lines
 
+Several modes can be combined with commas (-m metro,rer), and
+--exclude-mode drops modes from the selection (--exclude-mode bus).
+
+--line keeps some lines (--line M1,"RER A") and --direction keeps the
+departures heading somewhere: "defense" or "la def" match "La Défense",
+ignoring case and accents. Saved places can store both filters.
+
 If the query matches a saved place alias, it is used directly
-without searching the API. See "metro places --help".
+without searching the API. See "metro places --help". Without a
+query, the place of the current commute rule is used, else the
+default place. See "metro commute --help".
 
 Examples:
   metro d chatelet
@@ -53,16 +69,28 @@ Examples:
   metro d "73 rue rivoli"
   metro d chatelet -m metro
   metro d chatelet -m rer
+  metro d "gare de lyon" -m metro,rer
+  metro d "gare de lyon" --exclude-mode bus
+  metro d chatelet --line M1 --direction defense
 
   # use a saved place (skips search)
   metro d home
   metro d work
-  metro d                               # uses default saved place
+  metro d                               # uses the commute or default place
 
   # auto-detect location via browser
   metro d --here
   metro d --here --port 8080
-  metro d --here --cache 5m`,
+  metro d --here --cache 5m
+
+  # departures at a later time (scheduled beyond the next hour)
+  metro d home --at "tomorrow 07:45"
+  metro d work --at 18:30
+  metro d home --at 2026-03-02T07:45
+
+  # refresh in place until Ctrl-C (default every 30s)
+  metro d home --watch
+  metro d home --watch=1m`,
 	RunE: runDepartures,
 }
 
@@ -71,174 +99,319 @@ func init() {
 	departuresCmd.Flags().IntVar(&herePort, "port", 0, "fixed port for --here server (default: random)")
 	departuresCmd.Flags().BoolVar(&hereLAN, "lan", false, "expose --here server on LAN (default: localhost only)")
 	departuresCmd.Flags().DurationVar(&hereCacheTTL, "cache", 0, "reuse cached location within this duration (e.g. 5m, 1h)")
-	departuresCmd.Flags().StringVarP(&modeFlag, "mode", "m", "all", "transport filter (see modes above)")
+	departuresCmd.Flags().StringVarP(&modeFlag, "mode", "m", "all", "transport filter, comma-separated (see modes above)")
+	departuresCmd.Flags().StringSliceVar(&excludeModes, "exclude-mode", nil, "leave out these transport modes (e.g. bus)")
+	departuresCmd.Flags().StringSliceVar(&departLines, "line", nil, "only these lines, comma-separated (e.g. M1,RER A)")
+	departuresCmd.Flags().StringVar(&departDir, "direction", "", "only departures towards this direction (fuzzy, e.g. defense)")
+	departuresCmd.Flags().StringVar(&departAt, "at", "", "show departures from this time (HH:MM, \"tomorrow HH:MM\" or YYYY-MM-DD HH:MM)")
+	addWatchFlag(departuresCmd)
+	departuresCmd.MarkFlagsMutuallyExclusive("at", "watch")
 	rootCmd.AddCommand(departuresCmd)
 }
 
 func runDepartures(cmd *cobra.Command, args []string) error {
-	c, err := client.New()
+	ctx := cmd.Context()
+	c, err := newClient()
 	if err != nil {
 		return err
 	}
 
-	mode, err := model.ParseMode(modeFlag)
+	mode, err := model.ParseModes(modeFlag, excludeModes)
 	if err != nil {
 		return err
 	}
+	if err := checkWatch(); err != nil {
+		return err
+	}
+	var at time.Time
+	if departAt != "" {
+		if at, err = parseWhen(departAt, time.Now()); err != nil {
+			return err
+		}
+	}
 
+	target, err := resolveDepartureTarget(ctx, c, args, mode)
+	if err != nil {
+		return err
+	}
+	// Flags override a saved place's settings
+	if cmd.Flags().Changed("mode") || cmd.Flags().Changed("exclude-mode") {
+		target.Modes = nil
+	} else if target.Modes != nil {
+		mode = target.Modes
+	}
+	if len(departLines) > 0 {
+		target.Lines = departLines
+	}
+	if departDir != "" {
+		target.Direction = departDir
+	}
+	if f := describeFilters(target); f != "" {
+		infof("Only %s\n", f)
+	}
+	if target.Walk > 0 {
+		infof("Hiding departures in less than %d min (walk to the platform)\n", int(target.Walk.Minutes()))
+	}
+
+	if watchInterval > 0 {
+		return watchDepartures(ctx, c, target, mode)
+	}
+
+	boards, err := fetchBoards(ctx, c, target, mode, at)
+	if err != nil {
+		return err
+	}
+	printStale(c)
+
+	now := time.Now()
+	if !at.IsZero() {
+		if _, realtime := departureWindow(at, now); realtime {
+			infof("Departures from %s\n\n", at.Format("Mon 2 Jan 15:04"))
+		} else {
+			infof("Scheduled departures from %s (no realtime data yet)\n\n", at.Format("Mon 2 Jan 15:04"))
+		}
+		now = at
+	}
+	return renderBoards(os.Stdout, boards, mode, now)
+}
+
+// realtimeHorizon is how far ahead realtime departures are available.
+// Later departures come from the base schedule.
+const realtimeHorizon = time.Hour
+
+// departureWindow returns the Departures start time and whether to use
+// realtime data for departures at at (zero means now).
+func departureWindow(at, now time.Time) (fromDatetime string, realtime bool) {
+	if at.IsZero() {
+		return "", true
+	}
+	return display.FormatNavitiaTime(at), at.Sub(now) < realtimeHorizon
+}
+
+// departureTarget is a resolved place to show departures for: either a
+// single stop area, or coordinates around which nearby stops are looked up.
+// The other fields come from a saved place: Modes replaces an "all" mode
+// selection, Lines and Direction keep only matching departures, Radius
+// (0 for defaultRadius) bounds the nearby search and departures sooner
+// than Walk are hidden.
+type departureTarget struct {
+	StopID string
+	Name   string
+	City   string
+	Lon    string
+	Lat    string
+
+	Modes     model.ModeSet
+	Lines     []string
+	Direction string
+	Radius    int
+	Walk      time.Duration
+}
+
+// defaultRadius is how far around coordinates stops are looked up, in meters.
+const defaultRadius = 500
+
+// radius returns the nearby search radius in meters.
+func (t departureTarget) radius() int {
+	if t.Radius > 0 {
+		return t.Radius
+	}
+	return defaultRadius
+}
+
+// stopBoard holds the departures fetched for one stop area.
+type stopBoard struct {
+	ID   string
+	Name string
+	City string
+	Resp *model.DeparturesResponse
+	Err  error
+}
+
+// resolveDepartureTarget turns the command arguments (or --here, or the
+// default saved place) into a departure target, prompting when needed.
+func resolveDepartureTarget(ctx context.Context, c *client.Client, args []string, mode model.ModeSet) (departureTarget, error) {
 	// --here: use browser geolocation
 	if here {
-		return runDeparturesHere(c, mode)
+		return resolveHere()
 	}
 
 	// Station/address search
 	query := strings.Join(args, " ")
 	if query == "" {
-		// No args: use default saved place
+		// No args: use the commute place, else the default saved place
 		cfg, err := config.Load()
 		if err != nil {
-			return fmt.Errorf("loading config: %w", err)
+			return departureTarget{}, fmt.Errorf("loading config: %w", err)
 		}
-		if cfg.DefaultPlace == "" {
-			return fmt.Errorf("no station provided and no default place set\nUsage: metro departures <station>\n       metro departures --here\nOr save a default place:\n       metro places save home chatelet\n       metro places default home")
+		alias, rule := commutePlace(cfg, time.Now())
+		if alias == "" {
+			return departureTarget{}, fmt.Errorf("no station provided and no default place set\nUsage: metro departures <station>\n       metro departures --here\nOr save a default place:\n       metro places save home chatelet\n       metro places default home")
 		}
-		saved, ok := cfg.Places[cfg.DefaultPlace]
+		saved, ok := cfg.Places[alias]
 		if !ok {
-			return fmt.Errorf("default place \"%s\" not found in saved places\nRun: metro places save %s <station>", cfg.DefaultPlace, cfg.DefaultPlace)
+			return departureTarget{}, fmt.Errorf("default place \"%s\" not found in saved places\nRun: metro places save %s <station>", alias, alias)
 		}
-		fmt.Println()
-		return showSavedPlace(c, saved, mode)
+		if rule != nil {
+			infof("Commute: %s\n", describeRule(*rule))
+		}
+		infof("\n")
+		return savedPlaceTarget(ctx, c, saved)
 	}
 
 	// Check saved places first
 	if saved, ok := lookupSavedPlace(query); ok {
-		fmt.Println()
-		return showSavedPlace(c, saved, mode)
+		infof("\n")
+		return savedPlaceTarget(ctx, c, saved)
 	}
 
-	fmt.Printf("Searching for \"%s\"...\n", query)
-	places, err := c.SearchPlaces(query)
+	place, err := searchPlace(ctx, c, query, mode)
 	if err != nil {
-		return err
+		return departureTarget{}, err
+	}
+
+	// Offer to save the picked place (not when scripting structured output)
+	if !outputFormat.IsStructured() {
+		promptSavePlace(place)
+	}
+
+	infof("\n")
+	return placeTarget(ctx, c, place)
+}
+
+// searchPlace searches PRIM for a station or address, preferring stop areas
+// served by mode, and lets the user pick when there are several matches.
+func searchPlace(ctx context.Context, c *client.Client, query string, mode model.ModeSet) (model.PRIMPlace, error) {
+	infof("Searching for \"%s\"...\n", query)
+	places, err := c.SearchPlaces(ctx, query)
+	if err != nil {
+		return model.PRIMPlace{}, err
 	}
 	if len(places.Places) == 0 {
-		return fmt.Errorf("no results found for \"%s\"", query)
+		return model.PRIMPlace{}, fmt.Errorf("no results found for \"%s\"", query)
+	}
+
+	candidates := placeCandidates(places.Places, mode)
+	if len(candidates) == 0 {
+		return model.PRIMPlace{}, fmt.Errorf("no stops or addresses found for \"%s\"", query)
 	}
 
-	// Filter: stop areas + addresses
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	return pickPlace(candidates)
+}
+
+// placeCandidates keeps the stop areas serving mode and addresses from
+// search results, falling back to any stop area or address.
+func placeCandidates(places []model.PRIMPlace, mode model.ModeSet) []model.PRIMPlace {
 	var candidates []model.PRIMPlace
-	for _, p := range places.Places {
+	for _, p := range places {
 		if p.Type == "StopArea" && hasTransport(p, mode) {
 			candidates = append(candidates, p)
 		} else if p.Type == "Address" {
 			candidates = append(candidates, p)
 		}
 	}
-	// Fallback: any stop area or address
-	if len(candidates) == 0 {
-		for _, p := range places.Places {
-			if p.Type == "StopArea" || p.Type == "Address" {
-				candidates = append(candidates, p)
-			}
-		}
-	}
-	if len(candidates) == 0 {
-		return fmt.Errorf("no stops or addresses found for \"%s\"", query)
+	if len(candidates) > 0 {
+		return candidates
 	}
-
-	place := candidates[0]
-	if len(candidates) > 1 {
-		place, err = pickPlace(candidates)
-		if err != nil {
-			return err
+	for _, p := range places {
+		if p.Type == "StopArea" || p.Type == "Address" {
+			candidates = append(candidates, p)
 		}
 	}
+	return candidates
+}
 
-	// Offer to save the picked place
-	promptSavePlace(place)
-
-	fmt.Println()
-
+// placeTarget turns a picked PRIM place into a departure target.
+func placeTarget(ctx context.Context, c *client.Client, place model.PRIMPlace) (departureTarget, error) {
 	if place.Type == "StopArea" {
-		return showStopAreaDepartures(c, place.ID, place.Name, place.City, mode)
+		return departureTarget{StopID: place.ID, Name: place.Name, City: place.City}, nil
 	}
-	return showNearbyDepartures(c, place.Name+" "+place.City, mode)
+	return resolveAddress(ctx, c, place.Name+" "+place.City)
 }
 
-// runDeparturesHere uses browser geolocation to find the user's position.
-func runDeparturesHere(c *client.Client, mode model.TransportMode) error {
+// resolveHere uses browser geolocation to find the user's position.
+func resolveHere() (departureTarget, error) {
 	// Try cache first
 	if hereCacheTTL > 0 {
 		if lat, lon, err := location.LoadCache(hereCacheTTL); err == nil {
-			fmt.Printf("Using cached location (%.6f, %.6f)\n", lat, lon)
-			return showDeparturesAtCoords(c, fmt.Sprintf("%.6f", lon), fmt.Sprintf("%.6f", lat), mode)
+			infof("Using cached location (%.6f, %.6f)\n", lat, lon)
+			return coordsTarget(lat, lon), nil
 		}
 	}
 
-	fmt.Println("Locating you... (opening browser)")
+	infof("Locating you... (opening browser)\n")
 	lat, lon, err := location.GetLocation(30*time.Second, herePort, hereLAN)
 	if err != nil {
-		return fmt.Errorf("could not get location: %w", err)
+		return departureTarget{}, fmt.Errorf("could not get location: %w", err)
 	}
-	fmt.Printf("Found you at %.6f, %.6f\n", lat, lon)
+	infof("Found you at %.6f, %.6f\n", lat, lon)
 
 	if hereCacheTTL > 0 {
 		location.SaveCache(lat, lon)
 	}
 
-	return showDeparturesAtCoords(c, fmt.Sprintf("%.6f", lon), fmt.Sprintf("%.6f", lat), mode)
+	return coordsTarget(lat, lon), nil
 }
 
-// showStopAreaDepartures fetches and displays departures for a specific stop area.
-func showStopAreaDepartures(c *client.Client, stopID, name, city string, mode model.TransportMode) error {
-	label := name
-	if city != "" {
-		label = fmt.Sprintf("\033[1m%s\033[0m (%s)", name, city)
-	} else {
-		label = fmt.Sprintf("\033[1m%s\033[0m", name)
-	}
-	fmt.Println(label)
-	deps, err := c.Departures(stopID, 60, mode.Filter)
-	if err != nil {
-		return fmt.Errorf("fetching departures: %w", err)
-	}
-	display.Departures(deps.Departures, deps.Disruptions, mode.IsAll())
-	fmt.Println()
-	return nil
+// coordsTarget builds a nearby-stops target from WGS84 coordinates.
+func coordsTarget(lat, lon float64) departureTarget {
+	return departureTarget{Lon: fmt.Sprintf("%.6f", lon), Lat: fmt.Sprintf("%.6f", lat)}
 }
 
-// showNearbyDepartures resolves an address to coordinates, then shows nearby departures.
-func showNearbyDepartures(c *client.Client, addressQuery string, mode model.TransportMode) error {
-	fmt.Printf("Finding stops near %s...\n", addressQuery)
-	navResp, err := c.NavitiaPlaces(addressQuery)
+// resolveAddress geocodes an address to coordinates for a nearby lookup.
+func resolveAddress(ctx context.Context, c *client.Client, addressQuery string) (departureTarget, error) {
+	infof("Finding stops near %s...\n", addressQuery)
+	navResp, err := c.NavitiaPlaces(ctx, addressQuery)
 	if err != nil {
-		return fmt.Errorf("resolving address: %w", err)
+		return departureTarget{}, fmt.Errorf("resolving address: %w", err)
 	}
 
-	var lon, lat string
 	for _, np := range navResp.Places {
 		if np.Address != nil {
-			lon, lat = np.Address.Coord.Lon, np.Address.Coord.Lat
-			break
+			return departureTarget{Name: np.Name, Lon: np.Address.Coord.Lon, Lat: np.Address.Coord.Lat}, nil
 		}
 		if np.StopArea != nil {
-			lon, lat = np.StopArea.Coord.Lon, np.StopArea.Coord.Lat
-			break
+			return departureTarget{Name: np.Name, Lon: np.StopArea.Coord.Lon, Lat: np.StopArea.Coord.Lat}, nil
 		}
 	}
-	if lon == "" {
-		return fmt.Errorf("could not resolve coordinates for \"%s\"", addressQuery)
-	}
-
-	return showDeparturesAtCoords(c, lon, lat, mode)
+	return departureTarget{}, fmt.Errorf("could not resolve coordinates for \"%s\"", addressQuery)
 }
 
-// showDeparturesAtCoords finds stops near coordinates and shows departures for each.
-func showDeparturesAtCoords(c *client.Client, lon, lat string, mode model.TransportMode) error {
-	fmt.Printf("Finding stops nearby...\n\n")
-	nearby, err := c.PlacesNearby(lon, lat, 500, mode.Filter)
+// fetchBoards fetches departures for a target from at (zero means now),
+// keeping those matching the target's settings. The target's modes apply
+// when mode is all.
+// A single stop area fails as a whole; nearby stops keep per-stop errors
+// on their board, and those left without departures by the line and
+// direction filters are dropped.
+func fetchBoards(ctx context.Context, c *client.Client, target departureTarget, mode model.ModeSet, at time.Time) ([]stopBoard, error) {
+	now := time.Now()
+	from, realtime := departureWindow(at, now)
+	if mode.IsAll() && target.Modes != nil {
+		mode = target.Modes
+	}
+	// Departures leaving before the walk is over are out of reach
+	reachable := now
+	if !at.IsZero() {
+		reachable = at
+	}
+	reachable = reachable.Add(target.Walk)
+
+	if target.StopID != "" {
+		deps, err := c.Departures(ctx, target.StopID, 60, mode.Filters(), from, realtime)
+		if err != nil {
+			return nil, fmt.Errorf("fetching departures: %w", err)
+		}
+		deps = filterDepartures(deps, target, reachable)
+		return []stopBoard{{ID: target.StopID, Name: target.Name, City: target.City, Resp: deps}}, nil
+	}
+
+	infof("Finding stops nearby...\n\n")
+	nearby, err := c.PlacesNearby(ctx, target.Lon, target.Lat, target.radius(), mode.Filters())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	seen := make(map[string]bool)
@@ -255,35 +428,111 @@ func showDeparturesAtCoords(c *client.Client, lon, lat string, mode model.Transp
 	}
 
 	if len(areas) == 0 {
-		return fmt.Errorf("no stops found within 500m")
+		return nil, fmt.Errorf("no stops found within %dm", target.radius())
 	}
 
-	for _, sa := range areas {
-		fmt.Printf("\033[1m%s\033[0m\n", sa.Name)
-		deps, err := c.Departures(sa.ID, 40, mode.Filter)
-		if err != nil {
-			fmt.Printf("  \033[31mError: %v\033[0m\n", err)
+	boards := make([]stopBoard, len(areas))
+	parallel(len(areas), func(i int) {
+		sa := areas[i]
+		deps, err := c.Departures(ctx, sa.ID, 40, mode.Filters(), from, realtime)
+		if err == nil {
+			deps = filterDepartures(deps, target, reachable)
+		}
+		boards[i] = stopBoard{ID: sa.ID, Name: sa.Name, Resp: deps, Err: err}
+	})
+	if len(target.Lines) > 0 || target.Direction != "" {
+		boards = slices.DeleteFunc(boards, func(b stopBoard) bool {
+			return b.Err == nil && len(b.Resp.Departures) == 0
+		})
+		if len(boards) == 0 {
+			return nil, fmt.Errorf("no departures for %s nearby", describeFilters(target))
+		}
+	}
+	return boards, nil
+}
+
+// filterDepartures keeps the departures matching the target's lines and
+// direction, leaving from reachable on when the target has a walking time.
+// The response is copied, as it may be shared by the cache.
+func filterDepartures(resp *model.DeparturesResponse, target departureTarget, reachable time.Time) *model.DeparturesResponse {
+	if len(target.Lines) == 0 && target.Direction == "" && target.Walk == 0 {
+		return resp
+	}
+	out := &model.DeparturesResponse{Disruptions: resp.Disruptions}
+	for _, d := range resp.Departures {
+		if target.Walk > 0 {
+			if t, err := display.ParseNavitiaTime(d.StopDateTime.DepartureDateTime); err == nil && t.Before(reachable) {
+				continue
+			}
+		}
+		label := model.LineLabel(d.DisplayInformations.Code, d.DisplayInformations.CommercialMode)
+		if !model.MatchLines(label, target.Lines) {
+			continue
+		}
+		if target.Direction != "" && !model.MatchDirection(d.DisplayInformations.Direction, target.Direction) {
+			continue
+		}
+		out.Departures = append(out.Departures, d)
+	}
+	return out
+}
+
+// describeFilters describes a target's filters, e.g. "RER A towards paris".
+func describeFilters(target departureTarget) string {
+	var parts []string
+	if len(target.Lines) > 0 {
+		parts = append(parts, strings.Join(target.Lines, ", "))
+	}
+	if target.Direction != "" {
+		parts = append(parts, "towards "+target.Direction)
+	}
+	return strings.Join(parts, " ")
+}
+
+// renderBoards writes boards as tables, or as records for structured --output.
+// Countdowns are relative to now.
+func renderBoards(w io.Writer, boards []stopBoard, mode model.ModeSet, now time.Time) error {
+	if outputFormat.IsStructured() {
+		var recs []display.DepartureRecord
+		for _, b := range boards {
+			if b.Err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", b.Name, b.Err)
+				continue
+			}
+			recs = append(recs, display.DepartureRecords(b.ID, b.Name, b.Resp.Departures, b.Resp.Disruptions, now)...)
+		}
+		return display.WriteRecords(w, outputFormat, recs)
+	}
+
+	for _, b := range boards {
+		if b.City != "" {
+			fmt.Fprintf(w, "\033[1m%s\033[0m (%s)\n", b.Name, b.City)
+		} else {
+			fmt.Fprintf(w, "\033[1m%s\033[0m\n", b.Name)
+		}
+		if b.Err != nil {
+			fmt.Fprintf(w, "  \033[31mError: %v\033[0m\n", b.Err)
 			continue
 		}
-		display.Departures(deps.Departures, deps.Disruptions, mode.IsAll())
-		fmt.Println()
+		display.Departures(w, b.Resp.Departures, b.Resp.Disruptions, len(mode.Each()) > 1, now)
+		fmt.Fprintln(w)
 	}
 	return nil
 }
 
-// hasTransport checks if a PRIM place has the requested transport mode.
-func hasTransport(p model.PRIMPlace, mode model.TransportMode) bool {
+// hasTransport checks if a PRIM place has one of the requested transport modes.
+func hasTransport(p model.PRIMPlace, mode model.ModeSet) bool {
 	if mode.IsAll() {
 		return true
 	}
 	for _, m := range p.Modes {
-		if model.ModeByDisplayName(m) == mode.Name {
+		if mode.Has(model.ModeByDisplayName(m)) {
 			return true
 		}
 	}
 	for _, l := range p.Lines {
 		for _, m := range l.Mode {
-			if model.ModeByPhysicalID(m.ID) == mode.Name {
+			if mode.Has(model.ModeByPhysicalID(m.ID)) {
 				return true
 			}
 		}
@@ -292,7 +541,7 @@ func hasTransport(p model.PRIMPlace, mode model.TransportMode) bool {
 }
 
 func pickPlace(places []model.PRIMPlace) (model.PRIMPlace, error) {
-	fmt.Println("\nMultiple results found:")
+	infof("\nMultiple results found:\n")
 	for i, p := range places {
 		label := p.Type
 		if label == "StopArea" {
@@ -306,11 +555,11 @@ func pickPlace(places []model.PRIMPlace) (model.PRIMPlace, error) {
 		if p.City != "" {
 			extra += " - " + p.City
 		}
-		fmt.Printf("  %d. %s (%s%s)\n", i+1, p.Name, label, extra)
+		infof("  %d. %s (%s%s)\n", i+1, p.Name, label, extra)
 	}
 
 	for attempts := 0; attempts < 3; attempts++ {
-		fmt.Print("\nPick a number: ")
+		infof("\nPick a number: ")
 		input, _ := stdinReader.ReadString('\n')
 		input = strings.TrimSpace(input)
 
@@ -318,38 +567,55 @@ func pickPlace(places []model.PRIMPlace) (model.PRIMPlace, error) {
 		if err == nil && idx >= 1 && idx <= len(places) {
 			return places[idx-1], nil
 		}
-		fmt.Printf("  Invalid choice. Enter a number between 1 and %d.\n", len(places))
+		infof("  Invalid choice. Enter a number between 1 and %d.\n", len(places))
 	}
 	return model.PRIMPlace{}, fmt.Errorf("too many invalid attempts")
 }
 
 // promptSavePlace offers to save a picked place for quick access next time.
 func promptSavePlace(place model.PRIMPlace) {
-	fmt.Print("\nSave for next time? (name or Enter to skip): ")
+	infof("\nSave for next time? (name or Enter to skip): ")
 	input, _ := stdinReader.ReadString('\n')
 	alias := strings.ToLower(strings.TrimSpace(input))
 	if alias == "" {
 		return
 	}
 
-	if err := savePlace(alias, place); err != nil {
-		fmt.Printf("  Could not save: %v\n", err)
+	if err := savePlace(alias, newSavedPlace(place)); err != nil {
+		infof("  Could not save: %v\n", err)
 		return
 	}
 
-	fmt.Printf("Saved! Next time just run: metro d %s\n", alias)
+	infof("Saved! Next time just run: metro d %s\n", alias)
 }
 
-// showSavedPlace dispatches departures for a saved place, using stored coords when available.
-func showSavedPlace(c *client.Client, saved config.SavedPlace, mode model.TransportMode) error {
-	if saved.Type == "StopArea" {
-		return showStopAreaDepartures(c, saved.ID, saved.Name, saved.City, mode)
+// savedPlaceTarget resolves a saved place, using stored coords when
+// available, with the place's departure settings.
+func savedPlaceTarget(ctx context.Context, c *client.Client, saved config.SavedPlace) (departureTarget, error) {
+	var modes model.ModeSet
+	if len(saved.Modes) > 0 {
+		var err error
+		if modes, err = model.ParseModes(strings.Join(saved.Modes, ","), nil); err != nil {
+			return departureTarget{}, fmt.Errorf("saved place %s: %w", saved.Name, err)
+		}
 	}
-	// Address with stored coordinates: skip geocoding
-	if saved.Lat != 0 && saved.Lon != 0 {
-		return showDeparturesAtCoords(c, fmt.Sprintf("%.6f", saved.Lon), fmt.Sprintf("%.6f", saved.Lat), mode)
+
+	var target departureTarget
+	var err error
+	switch {
+	case saved.Type == "StopArea":
+		target = departureTarget{StopID: saved.ID, Name: saved.Name, City: saved.City}
+	case saved.Lat != 0 && saved.Lon != 0:
+		// Address with stored coordinates: skip geocoding
+		target = coordsTarget(saved.Lat, saved.Lon)
+	default:
+		if target, err = resolveAddress(ctx, c, saved.Name+" "+saved.City); err != nil {
+			return departureTarget{}, err
+		}
 	}
-	return showNearbyDepartures(c, saved.Name+" "+saved.City, mode)
+	target.Modes, target.Lines, target.Direction = modes, saved.Lines, saved.Direction
+	target.Radius, target.Walk = saved.Radius, time.Duration(saved.WalkMinutes)*time.Minute
+	return target, nil
 }
 
 // lookupSavedPlace checks if the query matches a saved place alias (case-insensitive).
@@ -1,17 +1,28 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/cyrilghali/metro-cli/internal/client"
 	"github.com/cyrilghali/metro-cli/internal/display"
 	"github.com/cyrilghali/metro-cli/internal/model"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var (
-	lineFilter     string
-	disruptionMode string
+	lineFilter        string
+	disruptionMode    string
+	disruptionExclude []string
+	upcoming          bool
+	upcomingDays      int
 )
 
 var disruptionsCmd = &cobra.Command{
@@ -32,56 +43,333 @@ Modes:
   tram    Tramway lines T1-T13
   bus     Bus lines
 
+Several modes can be combined with commas (-m metro,rer), and
+--exclude-mode drops modes from the selection (--exclude-mode bus).
+
 Examples:
   metro dis
   metro dis --line M14
   metro dis -m rer
-  metro status --line A`,
+  metro dis -m metro,rer,tram
+  metro status --line A
+
+  # refresh in place until Ctrl-C
+  metro dis -m rer --watch
+  metro dis --watch=2m
+
+  # planned works over the next days
+  metro dis --upcoming
+  metro dis --upcoming --days 14 --line "RER A"
+
+  # full messages, periods and impacted stops
+  metro dis show M14`,
 	RunE: runDisruptions,
 }
 
+var disruptionShowCmd = &cobra.Command{
+	Use:   "show <id|line>",
+	Short: "Show a disruption in full",
+	Long: `Show everything known about a disruption: every message in full, each
+application period in Paris time, the cause, category and tags, and the
+impacted stops.
+
+The argument is a line, showing each of its disruptions, or a disruption
+ID as printed by "metro dis -o json" (a prefix of at least 4 characters
+is enough).
+
+Examples:
+  metro dis show M14
+  metro dis show "RER B"
+  metro dis show d5b0c7a2`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDisruptionShow,
+}
+
 func init() {
 	disruptionsCmd.Flags().StringVar(&lineFilter, "line", "", "filter by line (e.g. M1, A, T3)")
-	disruptionsCmd.Flags().StringVarP(&disruptionMode, "mode", "m", "all", "transport filter (see modes above)")
+	disruptionsCmd.Flags().StringVarP(&disruptionMode, "mode", "m", "all", "transport filter, comma-separated (see modes above)")
+	disruptionsCmd.Flags().StringSliceVar(&disruptionExclude, "exclude-mode", nil, "leave out these transport modes (e.g. bus)")
+	disruptionsCmd.Flags().BoolVar(&upcoming, "upcoming", false, "list planned disruptions instead of the current status")
+	disruptionsCmd.Flags().IntVar(&upcomingDays, "days", 7, "with --upcoming, how many days ahead to look")
+	addWatchFlag(disruptionsCmd)
+	disruptionsCmd.AddCommand(disruptionShowCmd)
 	rootCmd.AddCommand(disruptionsCmd)
 }
 
 func runDisruptions(cmd *cobra.Command, args []string) error {
-	c, err := client.New()
+	ctx := cmd.Context()
+	c, err := newClient()
 	if err != nil {
 		return err
 	}
 
-	mode, err := model.ParseMode(disruptionMode)
+	mode, err := model.ParseModes(disruptionMode, disruptionExclude)
 	if err != nil {
 		return err
 	}
+	if err := checkWatch(); err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("days") && !upcoming {
+		return fmt.Errorf("--days only applies with --upcoming")
+	}
 
-	if mode.IsAll() {
-		return showAllDisruptions(c)
+	if upcoming {
+		if watchInterval > 0 {
+			return fmt.Errorf("--upcoming cannot be combined with --watch")
+		}
+		if upcomingDays < 1 {
+			return fmt.Errorf("--days must be at least 1 (got %d)", upcomingDays)
+		}
+		now := time.Now()
+		until := now.AddDate(0, 0, upcomingDays)
+		statuses, err := fetchUpcoming(ctx, c, mode, now, until)
+		if err != nil {
+			return err
+		}
+		printStale(c)
+		return renderUpcoming(os.Stdout, statuses, now, until)
+	}
+	if watchInterval > 0 {
+		return watchDisruptions(ctx, c, mode)
 	}
 
-	fmt.Printf("Fetching %s disruptions...\n\n", mode.Name)
-	resp, err := c.Lines(mode.Filter, mode.MaxLines)
+	statuses, err := fetchStatuses(ctx, c, mode)
 	if err != nil {
 		return err
 	}
+	printStale(c)
+	return renderStatuses(os.Stdout, statuses)
+}
 
-	display.DisruptionsSummary(resp, lineFilter, mode)
-	return nil
+// modeStatus holds the lines (with disruptions) fetched for one mode.
+type modeStatus struct {
+	Mode model.TransportMode
+	Resp *model.LinesResponse
+	Err  error
+}
+
+// fetchStatuses fetches line status for the selected modes. A single mode
+// fails as a whole; several modes are fetched in parallel and keep
+// per-mode errors.
+func fetchStatuses(ctx context.Context, c *client.Client, mode model.ModeSet) ([]modeStatus, error) {
+	if len(mode) == 1 {
+		m := mode[0]
+		infof("Fetching %s disruptions...\n\n", m.Name)
+		resp, err := c.Lines(ctx, m.Filter, m.MaxLines)
+		if err != nil {
+			return nil, err
+		}
+		return []modeStatus{{Mode: m, Resp: resp}}, nil
+	}
+
+	infof("Fetching disruptions...\n")
+	modes := mode.Each()
+	statuses := make([]modeStatus, len(modes))
+	parallel(len(modes), func(i int) {
+		m := modes[i]
+		resp, err := c.Lines(ctx, m.Filter, m.MaxLines)
+		statuses[i] = modeStatus{Mode: m, Resp: resp, Err: err}
+	})
+	return statuses, nil
 }
 
-func showAllDisruptions(c *client.Client) error {
-	fmt.Println("Fetching disruptions...")
-	for _, name := range model.ModeNames {
-		m := model.Modes[name]
-		resp, err := c.Lines(m.Filter, m.MaxLines)
+// fetchUpcoming fetches the disruptions applying between now and until
+// on the lines of the selected modes, like fetchStatuses.
+func fetchUpcoming(ctx context.Context, c *client.Client, mode model.ModeSet, now, until time.Time) ([]modeStatus, error) {
+	// Start on the hour so the response stays cached a while
+	since := display.FormatNavitiaTime(now.Truncate(time.Hour))
+	end := display.FormatNavitiaTime(until)
+	if len(mode) == 1 {
+		m := mode[0]
+		infof("Fetching planned %s disruptions...\n\n", m.Name)
+		resp, err := c.LineReports(ctx, m.Filter, since, end, m.MaxLines)
 		if err != nil {
-			fmt.Printf("  \033[31mError fetching %s: %v\033[0m\n", name, err)
+			return nil, err
+		}
+		return []modeStatus{{Mode: m, Resp: resp}}, nil
+	}
+
+	infof("Fetching planned disruptions...\n\n")
+	modes := mode.Each()
+	statuses := make([]modeStatus, len(modes))
+	parallel(len(modes), func(i int) {
+		m := modes[i]
+		resp, err := c.LineReports(ctx, m.Filter, since, end, m.MaxLines)
+		statuses[i] = modeStatus{Mode: m, Resp: resp, Err: err}
+	})
+	return statuses, nil
+}
+
+// renderUpcoming writes the disruptions beginning between now and until,
+// by line, or records for structured --output.
+func renderUpcoming(w io.Writer, statuses []modeStatus, now, until time.Time) error {
+	var items []display.LineDisruption
+	for _, st := range statuses {
+		if st.Err != nil {
+			if outputFormat.IsStructured() {
+				fmt.Fprintf(os.Stderr, "Error fetching %s: %v\n", st.Mode.Name, st.Err)
+			} else {
+				fmt.Fprintf(w, "  \033[31mError fetching %s: %v\033[0m\n", st.Mode.Name, st.Err)
+			}
 			continue
 		}
-		display.DisruptionsSummary(resp, lineFilter, m)
-		fmt.Println()
+		items = append(items, display.UpcomingDisruptions(st.Resp, lineFilter, st.Mode, now, until)...)
+	}
+	display.SortUpcoming(items, now, until)
+
+	if outputFormat.IsStructured() {
+		return display.WriteRecords(w, outputFormat, display.UpcomingRecords(items, now, until))
+	}
+	display.Upcoming(w, items, now, until)
+	return nil
+}
+
+// renderStatuses writes line status tables, or records for structured --output.
+func renderStatuses(w io.Writer, statuses []modeStatus) error {
+	if outputFormat.IsStructured() {
+		var recs []display.LineStatusRecord
+		for _, st := range statuses {
+			if st.Err != nil {
+				fmt.Fprintf(os.Stderr, "Error fetching %s: %v\n", st.Mode.Name, st.Err)
+				continue
+			}
+			recs = append(recs, display.LineStatusRecords(st.Resp, lineFilter, st.Mode)...)
+		}
+		return display.WriteRecords(w, outputFormat, recs)
+	}
+
+	for _, st := range statuses {
+		if st.Err != nil {
+			fmt.Fprintf(w, "  \033[31mError fetching %s: %v\033[0m\n", st.Mode.Name, st.Err)
+			continue
+		}
+		display.DisruptionsSummary(w, st.Resp, lineFilter, st.Mode)
+		if len(statuses) > 1 {
+			fmt.Fprintln(w)
+		}
 	}
 	return nil
 }
+
+func runDisruptionShow(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	// A line with a mode ("M14", "RER B") only needs that mode's lines
+	query := strings.TrimSpace(args[0])
+	mode := model.AllModes
+	if name, _ := model.ParseLineLabel(query); name != "" {
+		mode = model.ModeSet{model.Modes[name]}
+	}
+	statuses, err := fetchStatuses(ctx, c, mode)
+	if err != nil {
+		return err
+	}
+	for _, st := range statuses {
+		if st.Err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching %s: %v\n", st.Mode.Name, st.Err)
+		}
+	}
+
+	found := findDisruptions(lineDisruptions(statuses), query)
+	if len(found) == 0 {
+		return fmt.Errorf("no disruption or line matching \"%s\"", query)
+	}
+	printStale(c)
+
+	if outputFormat.IsStructured() {
+		recs := make([]display.DisruptionRecord, len(found))
+		for i, f := range found {
+			recs[i] = display.NewDisruptionRecord(f.Labels, f.Disruption)
+		}
+		return display.WriteRecords(os.Stdout, outputFormat, recs)
+	}
+
+	width := 80
+	if fd := int(os.Stdout.Fd()); term.IsTerminal(fd) {
+		if w, _, err := term.GetSize(fd); err == nil {
+			width = min(w, 100)
+		}
+	}
+	for i, f := range found {
+		if i > 0 {
+			fmt.Println()
+		}
+		display.Disruption(os.Stdout, f.Labels, f.Disruption, width)
+	}
+	return nil
+}
+
+// labeledDisruption is a disruption with the labels of the lines it
+// impacts.
+type labeledDisruption struct {
+	Labels     []string
+	Disruption model.Disruption
+}
+
+// lineDisruptions returns every disruption on the fetched lines, active
+// or not, once each even when it impacts lines of several modes.
+func lineDisruptions(statuses []modeStatus) []labeledDisruption {
+	var out []labeledDisruption
+	index := make(map[string]int)
+	for _, st := range statuses {
+		if st.Err != nil || st.Resp == nil {
+			continue
+		}
+		labels := make(map[string]string)
+		for _, l := range st.Resp.Lines {
+			labels[l.ID] = st.Mode.Prefix + l.Code
+		}
+		for _, d := range st.Resp.Disruptions {
+			i, ok := index[d.ID]
+			if !ok {
+				i = len(out)
+				index[d.ID] = i
+				out = append(out, labeledDisruption{Disruption: d})
+			}
+			for _, obj := range d.ImpactedObjects {
+				if label, ok := labels[obj.PTObject.ID]; ok && !slices.Contains(out[i].Labels, label) {
+					out[i].Labels = append(out[i].Labels, label)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// findDisruptions returns the disruptions on the line named by query, or
+// else the one whose ID is or starts with query.
+func findDisruptions(all []labeledDisruption, query string) []labeledDisruption {
+	var found []labeledDisruption
+	for _, ld := range all {
+		for _, label := range ld.Labels {
+			if model.MatchLine(label, query) {
+				found = append(found, ld)
+				break
+			}
+		}
+	}
+	if len(found) > 0 {
+		// Current disruptions first
+		sort.SliceStable(found, func(i, j int) bool {
+			return found[i].Disruption.Status == "active" && found[j].Disruption.Status != "active"
+		})
+		return found
+	}
+
+	id := strings.ToLower(query)
+	for _, ld := range all {
+		d := ld.Disruption
+		if strings.EqualFold(id, d.DisruptionID) || strings.EqualFold(id, d.ID) {
+			return []labeledDisruption{ld}
+		}
+		if len(id) >= 4 && (strings.HasPrefix(strings.ToLower(d.DisruptionID), id) || strings.HasPrefix(strings.ToLower(d.ID), id)) {
+			found = append(found, ld)
+		}
+	}
+	return found
+}
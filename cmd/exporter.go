@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/client"
+	"github.com/cyrilghali/metro-cli/internal/config"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/metrics"
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exporterListen   string
+	exporterInterval time.Duration
+)
+
+var exporterCmd = &cobra.Command{
+	Use:   "exporter [place...]",
+	Short: "Export line status and departure delays as Prometheus metrics",
+	Long: `Poll line status for every mode, and departures at saved places, and
+serve them as Prometheus metrics on /metrics.
+
+Without arguments, departures are polled for every saved place.
+
+Metrics:
+  metro_lines{mode}                                   lines per mode
+  metro_line_disrupted{mode,line,effect}              1 per disrupted line and effect
+  metro_disrupted_lines{mode,effect}                  disrupted lines per effect
+  metro_next_departure_minutes{place,stop,line,...}   minutes to the next departure
+  metro_departure_delay_minutes{place,stop,line,...}  realtime delay of that departure
+  metro_api_requests_total{endpoint,code}             PRIM requests, retries included
+  metro_api_errors_total{endpoint}                    failed PRIM requests
+  metro_api_request_duration_seconds{endpoint}        PRIM latency histogram
+  metro_poll_errors_total{source}                     failed polls
+  metro_last_poll_timestamp_seconds                   end of the last poll
+
+Examples:
+  metro exporter
+  metro exporter home work --listen :9464 --interval 30s`,
+	RunE: runExporter,
+}
+
+func init() {
+	exporterCmd.Flags().StringVar(&exporterListen, "listen", ":9464", "address to serve /metrics on")
+	exporterCmd.Flags().DurationVar(&exporterInterval, "interval", time.Minute, "how often to poll the API")
+	rootCmd.AddCommand(exporterCmd)
+}
+
+func runExporter(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if exporterInterval < 10*time.Second {
+		return fmt.Errorf("--interval must be at least 10s (got %s)", exporterInterval)
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var places []namedPlace
+	if len(args) > 0 || hasSavedPlaces() {
+		if places, err = resolvePlaces(ctx, c, args, 0); err != nil {
+			return err
+		}
+	}
+	// Every poll fetches fresh data, however long it is cached for
+	c.SetRefresh(true)
+
+	ln, err := net.Listen("tcp", exporterListen)
+	if err != nil {
+		return err
+	}
+
+	quietInfo = true
+	defer func() { quietInfo = false }()
+
+	e := newExporter(c, places)
+	e.poll(ctx)
+	go func() {
+		tick := time.NewTicker(exporterInterval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+				e.poll(ctx)
+			}
+		}
+	}()
+	return serveHTTP(ctx, ln, e.handler())
+}
+
+// hasSavedPlaces reports whether any place is saved.
+func hasSavedPlaces() bool {
+	cfg, err := config.Load()
+	return err == nil && len(cfg.Places) > 0
+}
+
+// exporter polls the API into a metrics registry. When a poll of one
+// mode or place fails, its previous samples are kept.
+type exporter struct {
+	c      *client.Client
+	reg    *metrics.Registry
+	places []namedPlace
+
+	mu    sync.Mutex                            // serializes polls
+	lines map[string][]display.LineStatusRecord // by mode
+	deps  map[string][]departureSample          // by place title
+}
+
+type departureSample struct {
+	labels   metrics.Labels
+	minutes  float64
+	delay    float64
+	realtime bool
+}
+
+func newExporter(c *client.Client, places []namedPlace) *exporter {
+	e := &exporter{
+		c:      c,
+		reg:    metrics.NewRegistry(),
+		places: places,
+		lines:  make(map[string][]display.LineStatusRecord),
+		deps:   make(map[string][]departureSample),
+	}
+	c.SetObserver(e.observe)
+	return e
+}
+
+// observe records one PRIM request.
+func (e *exporter) observe(r client.Request) {
+	e.reg.Add("metro_api_requests_total", "PRIM API requests, retries included.",
+		metrics.Labels{"endpoint": r.Endpoint, "code": strconv.Itoa(r.Status)}, 1)
+	if r.Status != http.StatusOK {
+		e.reg.Add("metro_api_errors_total", "PRIM API requests that failed or returned an error status.",
+			metrics.Labels{"endpoint": r.Endpoint}, 1)
+	}
+	e.reg.Observe("metro_api_request_duration_seconds", "PRIM API request latency.",
+		metrics.Labels{"endpoint": r.Endpoint}, r.Duration.Seconds())
+}
+
+func (e *exporter) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		e.reg.WriteTo(w)
+	})
+	return mux
+}
+
+// poll fetches line status for every mode and departures for every place,
+// then updates the gauges.
+func (e *exporter) poll(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	lines := make([][]display.LineStatusRecord, len(model.ModeNames))
+	lineErrs := make([]error, len(model.ModeNames))
+	parallel(len(model.ModeNames), func(i int) {
+		m := model.Modes[model.ModeNames[i]]
+		resp, err := e.c.Lines(ctx, m.Filter, m.MaxLines)
+		if err != nil {
+			lineErrs[i] = err
+			return
+		}
+		lines[i] = display.LineStatusRecords(resp, "", m)
+	})
+	for i, name := range model.ModeNames {
+		if lineErrs[i] != nil {
+			e.pollError("lines", name, lineErrs[i])
+			continue
+		}
+		e.lines[name] = lines[i]
+	}
+
+	// One place at a time, as fetchBoards fans out over nearby stops
+	for _, p := range e.places {
+		deps, err := e.fetchDepartures(ctx, p)
+		if err != nil {
+			e.pollError("departures", p.Title, err)
+			continue
+		}
+		e.deps[p.Title] = deps
+	}
+
+	e.update()
+}
+
+func (e *exporter) pollError(source, what string, err error) {
+	e.reg.Add("metro_poll_errors_total", "Failed polls by source.", metrics.Labels{"source": source}, 1)
+	fmt.Fprintf(os.Stderr, "%s %s: %v\n", time.Now().Format("15:04:05"), what, err)
+}
+
+// fetchDepartures returns the next departure of each line and direction
+// at a place. A nearby stop that failed counts a poll error and is
+// skipped; the place fails only when every stop did.
+func (e *exporter) fetchDepartures(ctx context.Context, p namedPlace) ([]departureSample, error) {
+	// The next departure is the next one, however long the walk
+	target := p.Target
+	target.Walk = 0
+	boards, err := fetchBoards(ctx, e.c, target, model.AllModes, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	seen := make(map[string]bool)
+	var samples []departureSample
+	if len(boards) > 0 && !slices.ContainsFunc(boards, func(b stopBoard) bool { return b.Err == nil }) {
+		return nil, boards[0].Err
+	}
+	for _, b := range boards {
+		if b.Err != nil {
+			e.pollError("departures", p.Title+" · "+b.Name, b.Err)
+			continue
+		}
+		for _, r := range display.DepartureRecords(b.ID, b.Name, b.Resp.Departures, b.Resp.Disruptions, now) {
+			key := r.StopID + "|" + r.Line + "|" + r.Direction
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			samples = append(samples, departureSample{
+				labels: metrics.Labels{
+					"place": p.Title, "stop": r.StopName, "line": r.Line, "mode": r.Mode, "direction": r.Direction,
+				},
+				minutes:  float64(r.Minutes),
+				delay:    float64(r.Delay),
+				realtime: r.Realtime,
+			})
+		}
+	}
+	return samples, nil
+}
+
+// update rebuilds the gauges from the latest samples.
+func (e *exporter) update() {
+	var lineCounts, disrupted, byEffect []metrics.Sample
+	for _, name := range model.ModeNames {
+		recs, ok := e.lines[name]
+		if !ok {
+			continue
+		}
+		total := make(map[string]bool)
+		seen := make(map[string]bool)
+		effects := make(map[string]int)
+		for _, r := range recs {
+			total[r.Line] = true
+			if r.Status != "disrupted" || seen[r.Line+"|"+r.Severity] {
+				continue
+			}
+			seen[r.Line+"|"+r.Severity] = true
+			effects[r.Severity]++
+			disrupted = append(disrupted, metrics.Sample{
+				Labels: metrics.Labels{"mode": name, "line": r.Line, "effect": r.Severity}, Value: 1,
+			})
+		}
+		lineCounts = append(lineCounts, metrics.Sample{Labels: metrics.Labels{"mode": name}, Value: float64(len(total))})
+		for effect, n := range effects {
+			byEffect = append(byEffect, metrics.Sample{Labels: metrics.Labels{"mode": name, "effect": effect}, Value: float64(n)})
+		}
+	}
+	e.reg.SetGauges("metro_lines", "Lines per mode.", lineCounts)
+	e.reg.SetGauges("metro_line_disrupted", "1 for each line with an active disruption of this effect.", disrupted)
+	e.reg.SetGauges("metro_disrupted_lines", "Lines with an active disruption, by effect.", byEffect)
+
+	var next, delays []metrics.Sample
+	for _, p := range e.places {
+		for _, s := range e.deps[p.Title] {
+			next = append(next, metrics.Sample{Labels: s.labels, Value: s.minutes})
+			if s.realtime {
+				delays = append(delays, metrics.Sample{Labels: s.labels, Value: s.delay})
+			}
+		}
+	}
+	e.reg.SetGauges("metro_next_departure_minutes", "Minutes until the next departure per line and direction.", next)
+	e.reg.SetGauges("metro_departure_delay_minutes", "Realtime delay of the next departure per line and direction.", delays)
+
+	e.reg.SetGauges("metro_last_poll_timestamp_seconds", "Unix time of the end of the last poll.",
+		[]metrics.Sample{{Value: float64(time.Now().Unix())}})
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cyrilghali/metro-cli/internal/client"
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/cyrilghali/metro-cli/internal/primtest"
+)
+
+func scrape(t *testing.T, e *exporter) string {
+	t.Helper()
+	srv := httptest.NewServer(e.handler())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestExporter(t *testing.T) {
+	srv := setupFakePRIM(t)
+	quietInfo = true
+	t.Cleanup(func() { quietInfo = false })
+
+	c, err := newClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	places := []namedPlace{{Title: "home", Target: departureTarget{StopID: chatelet.ID, Name: chatelet.Name}}}
+	e := newExporter(c, places)
+	e.poll(context.Background())
+
+	out := scrape(t, e)
+	for _, want := range []string{
+		`metro_lines{mode="rer"} 2`,
+		`metro_line_disrupted{effect="SIGNIFICANT_DELAYS",line="M14",mode="metro"} 1`,
+		`metro_disrupted_lines{effect="SIGNIFICANT_DELAYS",mode="metro"} 1`,
+		`metro_next_departure_minutes{direction="La Défense (Grande Arche)",line="M1",mode="metro",place="home",stop="Châtelet"}`,
+		`metro_departure_delay_minutes{direction="La Défense (Grande Arche)",line="M1",mode="metro",place="home",stop="Châtelet"} 2`,
+		`metro_api_requests_total{code="200",endpoint="departures"} 1`,
+		`metro_api_request_duration_seconds_count{endpoint="lines"}`,
+		"metro_last_poll_timestamp_seconds ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q:\n%s", want, out)
+		}
+	}
+	// Scheduled-only departures have no realtime delay
+	if strings.Contains(out, `metro_departure_delay_minutes{direction="Château de Vincennes"`) {
+		t.Error("delay exported for a departure without realtime data")
+	}
+
+	// A failed poll keeps the previous line status and counts the error
+	c.SetCache(nil, client.CacheOptions{})
+	for range model.ModeNames {
+		srv.Inject("/v2/navitia/lines", primtest.Response{Status: 400, Body: `{"message":"bad filter"}`})
+	}
+	e.places = nil
+	e.poll(context.Background())
+	out = scrape(t, e)
+	for _, want := range []string{
+		`metro_line_disrupted{effect="SIGNIFICANT_DELAYS",line="M14",mode="metro"} 1`,
+		`metro_poll_errors_total{source="lines"} 5`,
+		`metro_api_errors_total{endpoint="lines"} 5`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("after a failed poll, metrics missing %q:\n%s", want, out)
+		}
+	}
+
+	// A nearby stop that fails doesn't drop the others
+	srv.Inject("/v2/navitia/stop_areas/stop_area:IDFM:474151/", primtest.Response{Status: 400, Body: `{"message":"bad request"}`})
+	e.places = []namedPlace{{Title: "near", Target: departureTarget{Lon: "2.347", Lat: "48.859"}}}
+	e.poll(context.Background())
+	out = scrape(t, e)
+	for _, want := range []string{
+		`metro_next_departure_minutes{direction="La Défense (Grande Arche)",line="M1",mode="metro",place="near",stop="Châtelet"}`,
+		`metro_poll_errors_total{source="departures"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("with a failed stop, metrics missing %q:\n%s", want, out)
+		}
+	}
+}
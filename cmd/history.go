@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/client"
+	"github.com/cyrilghali/metro-cli/internal/config"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/history"
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyLine  string
+	historySince string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Report recorded disruptions per line",
+	Long: `Report the disruptions recorded on each line: how many, how many hours
+of service were disrupted (modified service or worse, overlaps counted
+once), the worst severity, and the worst days.
+
+Recording is opt-in. Once enabled, every command that fetches line status
+or departures (dis, d, tui, alert, exporter, ...) records the disruptions
+it sees in a local log. Keep "metro alert" or "metro exporter" running to
+record continuously.
+
+--since takes days ("30d"), weeks ("2w"), hours ("12h") or a date
+("2026-09-01"). With --line, every disruption of the period is listed.
+
+Examples:
+  metro history enable
+  metro history
+  metro history --line "RER B" --since 30d
+  metro history --since 2026-09-01 -o csv`,
+	Args: cobra.NoArgs,
+	RunE: runHistory,
+}
+
+var historyEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Start recording disruptions",
+	Args:  cobra.NoArgs,
+	RunE:  func(cmd *cobra.Command, args []string) error { return setHistory(true) },
+}
+
+var historyDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Stop recording disruptions (the log is kept)",
+	Args:  cobra.NoArgs,
+	RunE:  func(cmd *cobra.Command, args []string) error { return setHistory(false) },
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyLine, "line", "", "only this line (e.g. M14, \"RER B\")")
+	historyCmd.Flags().StringVar(&historySince, "since", "30d", "period to report (e.g. 7d, 2w, 2026-09-01)")
+	historyCmd.AddCommand(historyEnableCmd)
+	historyCmd.AddCommand(historyDisableCmd)
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	since, err := parseSince(historySince, now)
+	if err != nil {
+		return err
+	}
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	entries, err := history.Load(path)
+	if err != nil {
+		return fmt.Errorf("reading history: %w", err)
+	}
+
+	if len(entries) == 0 && !outputFormat.IsStructured() {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		if !cfg.History {
+			fmt.Println("No disruptions recorded: recording is off.")
+			fmt.Println("\nTurn it on with:")
+			fmt.Println("  metro history enable")
+			return nil
+		}
+	}
+
+	summary := display.SummarizeHistory(entries, historyLine, since, now)
+	if outputFormat.IsStructured() {
+		return display.WriteRecords(os.Stdout, outputFormat, display.HistoryRecords(summary))
+	}
+	display.History(os.Stdout, summary, historyLine != "")
+	return nil
+}
+
+func setHistory(on bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	cfg.History = on
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	if on {
+		fmt.Printf("Recording disruptions to %s\n", path)
+		fmt.Println("Report with: metro history")
+	} else {
+		fmt.Printf("Stopped recording. The log is kept in %s\n", path)
+	}
+	return nil
+}
+
+// historyPath returns the disruption log path.
+func historyPath() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", fmt.Errorf("no data directory for the history: %w", err)
+	}
+	return filepath.Join(dir, history.FileName), nil
+}
+
+// recordHistory makes c record the disruptions it fetches. Recording is
+// best effort: a failing write never fails the command.
+func recordHistory(c *client.Client) error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	log := history.Open(path)
+	c.SetDisruptionObserver(func(lines []model.Line, disruptions []model.Disruption) {
+		_ = log.Add(historyEntries(lines, disruptions, time.Now()))
+	})
+	return nil
+}
+
+// historyEntries turns fetched disruptions into log entries, labeled with
+// the lines they impact. Disruptions on none of lines are left out.
+func historyEntries(lines []model.Line, disruptions []model.Disruption, now time.Time) []history.Entry {
+	labels := make(map[string]string, len(lines))
+	for _, l := range lines {
+		mode := ""
+		if l.CommercialMode != nil {
+			mode = l.CommercialMode.Name
+		}
+		labels[l.ID] = model.LineLabel(l.Code, mode)
+	}
+
+	var entries []history.Entry
+	for _, d := range disruptions {
+		e := history.Entry{
+			DisruptionID:       d.DisruptionID,
+			FirstSeen:          now,
+			LastSeen:           now,
+			Severity:           d.Severity,
+			Effects:            []string{d.Severity.Effect},
+			ApplicationPeriods: d.ApplicationPeriods,
+			Cause:              d.Cause,
+			Message:            display.DisruptionMessage(d),
+		}
+		if e.DisruptionID == "" {
+			e.DisruptionID = d.ID
+		}
+		for _, obj := range d.ImpactedObjects {
+			if label, ok := labels[obj.PTObject.ID]; ok {
+				e.Lines = append(e.Lines, label)
+			}
+		}
+		if len(e.Lines) > 0 {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// parseSince parses a --since value relative to now: "30d", "2w", a Go
+// duration ("12h"), or a date ("2026-09-01", midnight Paris time).
+func parseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.ParseInLocation("2006-01-02", s, display.Paris()); err == nil {
+		return t, nil
+	}
+	if n := len(s); n > 1 && (s[n-1] == 'd' || s[n-1] == 'w') {
+		if v, err := strconv.Atoi(s[:n-1]); err == nil && v > 0 {
+			days := v
+			if s[n-1] == 'w' {
+				days *= 7
+			}
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (e.g. 30d, 2w, 12h or 2026-09-01)", s)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/config"
+	"github.com/cyrilghali/metro-cli/internal/history"
+)
+
+func TestHistoryRecordsAndReports(t *testing.T) {
+	setupFakePRIM(t)
+	saveTestPlaces(t, "home", map[string]config.SavedPlace{"home": chatelet})
+
+	// Nothing is recorded until history is enabled
+	if _, err := runCLI(t, "dis", "-m", "metro"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := runCLI(t, "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "recording is off") {
+		t.Errorf("expected a hint to enable recording, got:\n%s", out)
+	}
+
+	if _, err := runCLI(t, "history", "enable"); err != nil {
+		t.Fatal(err)
+	}
+	// The M14 disruption is seen both in line status and at Châtelet
+	for _, args := range [][]string{{"dis", "-m", "metro"}, {"d", "home"}, {"dis", "-m", "rer"}} {
+		if _, err := runCLI(t, args...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+
+	path, err := historyPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := history.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1: %+v", len(entries), entries)
+	}
+	e := entries[0]
+	if e.DisruptionID != "d5b0c7a2-1111" || strings.Join(e.Lines, ",") != "M14" || e.Severity.Effect != "SIGNIFICANT_DELAYS" ||
+		len(e.ApplicationPeriods) != 1 || !strings.HasPrefix(e.Message, "Métro 14") {
+		t.Errorf("entry = %+v", e)
+	}
+
+	// The fixture disruption applies on 25 Feb 2026, 05:00 to 23:59
+	out, err = runCLI(t, "history", "--line", "m14", "--since", "2026-02-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Disruptions since Sun 1 Feb 2026", "M14", "19.0h", "Worst days", "Wed 25 Feb", "Trafic perturbé"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, err = runCLI(t, "history", "--since", "2026-02-01", "-o", "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "line,disruptions,disrupted_hours,worst_severity\nM14,1,18.98,SIGNIFICANT_DELAYS\n"; out != want {
+		t.Errorf("CSV = %q, want %q", out, want)
+	}
+
+	out, err = runCLI(t, "history", "--line", "RER B", "--since", "2026-02-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "No disruptions recorded") {
+		t.Errorf("expected no RER B disruptions, got:\n%s", out)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"30d", now.AddDate(0, 0, -30)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"12h", now.Add(-12 * time.Hour)},
+		{"2026-09-01", time.Date(2026, 8, 31, 22, 0, 0, 0, time.UTC)}, // Paris midnight
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.input, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, %v; want %v", tt.input, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "d", "-3d", "yesterday", "0w"} {
+		if _, err := parseSince(bad, now); err == nil {
+			t.Errorf("parseSince(%q) should fail", bad)
+		}
+	}
+}
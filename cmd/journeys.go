@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/client"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	journeyHere   bool
+	journeyDepart string
+	journeyArrive string
+	journeyCount  int
+)
+
+var journeysCmd = &cobra.Command{
+	Use:     "go <from> <to>",
+	Aliases: []string{"journey", "route"},
+	Short:   "Plan a journey between two places",
+	Long: `Plan a journey between two stations, addresses or saved places.
+Shows the best itineraries with each section (walk, line, transfer),
+the total duration and the number of transfers.
+
+Use quotes for multi-word places. With --here, your current location
+is the starting point and only the destination is needed.
+
+Times accept "HH:MM" (next occurrence), "tomorrow HH:MM" or
+"YYYY-MM-DD HH:MM".
+
+Aliases: journey, route
+
+Examples:
+  metro go home work
+  metro go chatelet "gare de lyon"
+  metro go "73 rue rivoli" work
+  metro go --here work
+  metro go home work --depart 08:30
+  metro go home work --arrive 09:00
+  metro go home work --depart "tomorrow 07:45"
+  metro go home work --arrive "2026-03-02 09:00"`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if journeyHere {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
+	RunE: runJourneys,
+}
+
+func init() {
+	journeysCmd.Flags().BoolVar(&journeyHere, "here", false, "start from your current location (opens a browser tab)")
+	journeysCmd.Flags().StringVar(&journeyDepart, "depart", "", "leave at this time (HH:MM, \"tomorrow HH:MM\" or YYYY-MM-DD HH:MM)")
+	journeysCmd.Flags().StringVar(&journeyArrive, "arrive", "", "arrive by this time (HH:MM, \"tomorrow HH:MM\" or YYYY-MM-DD HH:MM)")
+	journeysCmd.Flags().IntVarP(&journeyCount, "count", "n", 3, "number of itineraries to show")
+	journeysCmd.MarkFlagsMutuallyExclusive("depart", "arrive")
+	rootCmd.AddCommand(journeysCmd)
+}
+
+func runJourneys(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if journeyCount < 1 {
+		return fmt.Errorf("--count must be at least 1 (got %d)", journeyCount)
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var datetime string
+	arriveBy := journeyArrive != ""
+	if when := journeyDepart + journeyArrive; when != "" {
+		t, err := parseWhen(when, time.Now())
+		if err != nil {
+			return err
+		}
+		datetime = display.FormatNavitiaTime(t)
+	}
+
+	var from departureTarget
+	if journeyHere {
+		from, err = resolveHere()
+	} else {
+		from, err = resolveEndpoint(ctx, c, args[0])
+		args = args[1:]
+	}
+	if err != nil {
+		return err
+	}
+	to, err := resolveEndpoint(ctx, c, args[0])
+	if err != nil {
+		return err
+	}
+
+	infof("Planning journey...\n\n")
+	resp, err := c.Journeys(ctx, from.journeyPlace(), to.journeyPlace(), datetime, arriveBy, journeyCount)
+	if err != nil {
+		return err
+	}
+
+	printStale(c)
+	if outputFormat.IsStructured() {
+		return display.WriteRecords(os.Stdout, outputFormat, display.JourneyRecords(resp.Journeys))
+	}
+	display.Journeys(os.Stdout, resp.Journeys)
+	return nil
+}
+
+// resolveEndpoint resolves a journey endpoint from a saved place alias,
+// or by searching stations and addresses.
+func resolveEndpoint(ctx context.Context, c *client.Client, query string) (departureTarget, error) {
+	if saved, ok := lookupSavedPlace(query); ok {
+		return savedPlaceTarget(ctx, c, saved)
+	}
+	place, err := searchPlace(ctx, c, query, model.AllModes)
+	if err != nil {
+		return departureTarget{}, err
+	}
+	return placeTarget(ctx, c, place)
+}
+
+// journeyPlace returns the Navitia from/to value for a target:
+// the stop area ID, or "lon;lat" coordinates.
+func (t departureTarget) journeyPlace() string {
+	if t.StopID != "" {
+		return t.StopID
+	}
+	return t.Lon + ";" + t.Lat
+}
+
+// parseWhen parses a user-supplied time in Paris local time. A bare
+// "HH:MM" means its next occurrence (today, or tomorrow if already past);
+// "today HH:MM" and "tomorrow HH:MM" pick the day explicitly.
+func parseWhen(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	loc := display.Paris()
+	now = now.In(loc)
+
+	if f := strings.Fields(s); len(f) == 2 {
+		days := -1
+		switch strings.ToLower(f[0]) {
+		case "today":
+			days = 0
+		case "tomorrow":
+			days = 1
+		}
+		if days >= 0 {
+			t, err := time.ParseInLocation("15:04", f[1], loc)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid time %q (use HH:MM after %s)", s, f[0])
+			}
+			d := now.AddDate(0, 0, days)
+			return time.Date(d.Year(), d.Month(), d.Day(), t.Hour(), t.Minute(), 0, 0, loc), nil
+		}
+	}
+
+	if t, err := time.ParseInLocation("15:04", s, loc); err == nil {
+		at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+		if at.Before(now.Truncate(time.Minute)) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use HH:MM, \"tomorrow HH:MM\" or YYYY-MM-DD HH:MM)", s)
+}
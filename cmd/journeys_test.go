@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/display"
+)
+
+func TestParseWhen(t *testing.T) {
+	loc := display.Paris()
+	now := time.Date(2026, 2, 25, 15, 0, 0, 0, loc)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"18:30", "2026-02-25 18:30"},
+		{"07:45", "2026-02-26 07:45"},
+		{"today 07:45", "2026-02-25 07:45"},
+		{"tomorrow 07:45", "2026-02-26 07:45"},
+		{"Tomorrow 7:45", "2026-02-26 07:45"},
+		{"2026-03-02 09:00", "2026-03-02 09:00"},
+		{"2026-03-02T09:00", "2026-03-02 09:00"},
+		{"2026-03-02T08:00:00Z", "2026-03-02 09:00"},
+	}
+	for _, tt := range tests {
+		got, err := parseWhen(tt.input, now)
+		if err != nil {
+			t.Errorf("parseWhen(%q) unexpected error: %v", tt.input, err)
+			continue
+		}
+		if s := got.In(loc).Format("2006-01-02 15:04"); s != tt.want {
+			t.Errorf("parseWhen(%q) = %s, want %s", tt.input, s, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"soon", "tomorrow", "tomorrow morning", "25:00"} {
+		if _, err := parseWhen(bad, now); err == nil {
+			t.Errorf("parseWhen(%q) expected error", bad)
+		}
+	}
+}
+
+func TestDepartureWindow(t *testing.T) {
+	now := time.Date(2026, 2, 25, 15, 0, 0, 0, display.Paris())
+
+	if from, realtime := departureWindow(time.Time{}, now); from != "" || !realtime {
+		t.Errorf("zero time: got %q, %v; want now, realtime", from, realtime)
+	}
+	if from, realtime := departureWindow(now.Add(20*time.Minute), now); from != "20260225T152000" || !realtime {
+		t.Errorf("in 20 min: got %q, %v; want realtime", from, realtime)
+	}
+	if from, realtime := departureWindow(now.Add(17*time.Hour), now); from != "20260226T080000" || realtime {
+		t.Errorf("tomorrow: got %q, %v; want base schedule", from, realtime)
+	}
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cyrilghali/metro-cli/internal/client"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var lineReverse bool
+
+var lineCmd = &cobra.Command{
+	Use:   "line <line>",
+	Short: "Show a line's branches and stops",
+	Long: `Show a line's branches and its stops in order, with the other lines
+serving each stop. Stops impacted by a current disruption are marked.
+
+Each branch is a column: a dot means the branch stops there, which
+answers "does this train stop at X?". Short runs within a longer branch
+are not shown. --reverse shows the other direction.
+
+Examples:
+  metro line M13
+  metro line "RER B"
+  metro line T3a --reverse`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runLine,
+}
+
+func init() {
+	lineCmd.Flags().BoolVar(&lineReverse, "reverse", false, "show the opposite direction")
+	rootCmd.AddCommand(lineCmd)
+}
+
+func runLine(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	// "metro line RER B" works unquoted
+	query := strings.Join(args, " ")
+	label, line, disruptions, err := findLine(ctx, c, query)
+	if err != nil {
+		return err
+	}
+	infof("Fetching %s stops...\n\n", label)
+	diagram, err := fetchLineDiagram(ctx, c, label, line, disruptions, lineReverse)
+	if err != nil {
+		return err
+	}
+	printStale(c)
+
+	if outputFormat.IsStructured() {
+		return display.WriteRecords(os.Stdout, outputFormat, display.LineStopRecords(diagram))
+	}
+	display.Line(os.Stdout, diagram)
+	return nil
+}
+
+// findLine resolves a line label to a single line, with the disruptions
+// returned along with it.
+func findLine(ctx context.Context, c *client.Client, query string) (string, model.Line, []model.Disruption, error) {
+	resp, err := c.Lines(ctx, model.LineFilter(query), 10)
+	if err != nil {
+		return "", model.Line{}, nil, err
+	}
+	var labels []string
+	var matches []model.Line
+	for _, l := range resp.Lines {
+		mode := ""
+		if l.CommercialMode != nil {
+			mode = l.CommercialMode.Name
+		}
+		label := model.LineLabel(l.Code, mode)
+		if model.MatchLine(label, query) {
+			labels = append(labels, label)
+			matches = append(matches, l)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", model.Line{}, nil, fmt.Errorf("no line matching \"%s\"", query)
+	case 1:
+		return labels[0], matches[0], resp.Disruptions, nil
+	}
+	return "", model.Line{}, nil, fmt.Errorf("\"%s\" matches several lines: %s", query, strings.Join(labels, ", "))
+}
+
+// fetchLineDiagram fetches a line's routes in one direction, their stops
+// and the stops' connections. Routes without a direction type count as
+// forward.
+func fetchLineDiagram(ctx context.Context, c *client.Client, label string, line model.Line, disruptions []model.Disruption, reverse bool) (display.LineDiagram, error) {
+	resp, err := c.LineRoutes(ctx, line.ID)
+	if err != nil {
+		return display.LineDiagram{}, err
+	}
+	var routes []model.Route
+	for _, r := range resp.Routes {
+		if (r.DirectionType == "backward") == reverse {
+			routes = append(routes, r)
+		}
+	}
+	if len(routes) == 0 {
+		return display.LineDiagram{}, fmt.Errorf("no routes found for %s", label)
+	}
+
+	stops := make([]display.RouteStops, len(routes))
+	errs := make([]error, len(routes))
+	var stopAreas *model.StopAreasResponse
+	var stopAreasErr error
+	parallel(len(routes)+1, func(i int) {
+		if i == len(routes) {
+			stopAreas, stopAreasErr = c.LineStopAreas(ctx, line.ID)
+			return
+		}
+		stops[i].Route = routes[i]
+		sched, err := c.RouteSchedules(ctx, routes[i].ID)
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		for _, rs := range sched.RouteSchedules {
+			for _, row := range rs.Table.Rows {
+				if sa := row.StopPoint.StopArea; sa != nil && (len(stops[i].Stops) == 0 || stops[i].Stops[len(stops[i].Stops)-1].ID != sa.ID) {
+					stops[i].Stops = append(stops[i].Stops, *sa)
+				}
+			}
+		}
+	})
+	for _, err := range errs {
+		if err != nil {
+			return display.LineDiagram{}, err
+		}
+	}
+	// Connections are a nice-to-have
+	var areas []model.StopArea
+	if stopAreasErr != nil {
+		fmt.Fprintf(os.Stderr, "Connections unavailable: %v\n", stopAreasErr)
+	} else {
+		areas = stopAreas.StopAreas
+	}
+	return display.NewLineDiagram(label, line, stops, areas, disruptions), nil
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import "sync"
+
+// maxConcurrentRequests bounds how many API calls a fan-out runs at once,
+// to stay friendly with PRIM rate limits.
+const maxConcurrentRequests = 4
+
+// parallel calls fn(i) for every i in [0, n) on at most
+// maxConcurrentRequests goroutines and waits for all of them. Callers
+// write results into index-addressed slots, so output order stays
+// deterministic regardless of completion order.
+func parallel(n int, fn func(i int)) {
+	sem := make(chan struct{}, maxConcurrentRequests)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
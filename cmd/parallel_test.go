@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelOrderAndBound(t *testing.T) {
+	const n = 20
+	results := make([]int, n)
+	var running, peak atomic.Int32
+
+	parallel(n, func(i int) {
+		cur := running.Add(1)
+		for {
+			p := peak.Load()
+			if cur <= p || peak.CompareAndSwap(p, cur) {
+				break
+			}
+		}
+		// Finish in reverse order to make sure results don't depend on timing.
+		time.Sleep(time.Duration(n-i) * time.Millisecond)
+		results[i] = i * i
+		running.Add(-1)
+	})
+
+	for i, r := range results {
+		if r != i*i {
+			t.Errorf("results[%d] = %d, want %d", i, r, i*i)
+		}
+	}
+	if p := peak.Load(); p > maxConcurrentRequests {
+		t.Errorf("peak concurrency %d exceeds limit %d", p, maxConcurrentRequests)
+	}
+}
+
+func TestParallelZero(t *testing.T) {
+	parallel(0, func(int) { t.Error("fn must not be called") })
+}
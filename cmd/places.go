@@ -2,14 +2,26 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
-	"github.com/cyrilghali/metro-cli/internal/client"
 	"github.com/cyrilghali/metro-cli/internal/config"
+	"github.com/cyrilghali/metro-cli/internal/display"
 	"github.com/cyrilghali/metro-cli/internal/model"
 	"github.com/spf13/cobra"
 )
 
+var (
+	placeModes     string
+	placeLines     []string
+	placeDirection string
+	placeRadius    int
+	placeWalk      int
+)
+
 var placesCmd = &cobra.Command{
 	Use:   "places",
 	Short: "Manage saved places",
@@ -22,7 +34,10 @@ Examples:
   metro places                          # list saved places
   metro places save home chatelet       # save "chatelet" as "home"
   metro places save work "la defense"   # save "la defense" as "work"
+  metro places save work "la defense" --line "RER A" --direction marne
+  metro places edit work --walk 6       # change a saved place's settings
   metro places default home             # set default for "metro d"
+  metro commute add work --time 16:00-21:00 --to home
   metro places remove home              # remove saved place
 
   metro d home                          # use saved place directly
@@ -35,14 +50,38 @@ var placesSaveCmd = &cobra.Command{
 	Short: "Save a place with a name",
 	Long: `Search for a station or address and save it under a short alias.
 
+The flags are stored with the place and apply every time it is used:
+--mode, --line and --direction filter its departures as for "metro d",
+--radius widens or narrows the nearby stops search around an address,
+and --walk hides departures leaving before you can reach the platform.
+Change them later with "metro places edit".
+
 Examples:
   metro places save home chatelet
   metro places save work "gare de lyon"
-  metro places save gym "73 rue rivoli"`,
+  metro places save gym "73 rue rivoli" --radius 800
+  metro places save work "la defense" --line "RER A" --direction marne --walk 6`,
 	Args: cobra.MinimumNArgs(2),
 	RunE: runPlacesSave,
 }
 
+var placesEditCmd = &cobra.Command{
+	Use:   "edit <alias>",
+	Short: "Change a saved place's settings",
+	Long: `Change the departure settings of a saved place.
+
+With flags, only the given settings change; an empty value (--line "",
+--radius 0) clears one. Without flags, each setting is asked in turn:
+Enter keeps the current value and "-" clears it.
+
+Examples:
+  metro places edit work
+  metro places edit work --walk 4
+  metro places edit home -m metro,rer --direction ""`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPlacesEdit,
+}
+
 var placesRemoveCmd = &cobra.Command{
 	Use:     "remove <alias>",
 	Aliases: []string{"rm", "delete"},
@@ -55,6 +94,7 @@ var placesDefaultCmd = &cobra.Command{
 	Use:   "default <alias>",
 	Short: "Set the default place for \"metro d\" with no arguments",
 	Long: `Mark a saved place as the default, used when you run "metro d" with no station.
+A commute rule applying at the time wins over it, see "metro commute".
 
 Examples:
   metro places default home
@@ -64,7 +104,15 @@ Examples:
 }
 
 func init() {
+	for _, c := range []*cobra.Command{placesSaveCmd, placesEditCmd} {
+		c.Flags().StringVarP(&placeModes, "mode", "m", "", "transport modes shown here, comma-separated (e.g. metro,rer)")
+		c.Flags().StringSliceVar(&placeLines, "line", nil, "only show these lines here, comma-separated (e.g. M1,RER A)")
+		c.Flags().StringVar(&placeDirection, "direction", "", "only show departures towards this direction here (fuzzy)")
+		c.Flags().IntVar(&placeRadius, "radius", 0, fmt.Sprintf("nearby stops search radius in meters (default %d)", defaultRadius))
+		c.Flags().IntVar(&placeWalk, "walk", 0, "minutes to walk to the platform; sooner departures are hidden")
+	}
 	placesCmd.AddCommand(placesSaveCmd)
+	placesCmd.AddCommand(placesEditCmd)
 	placesCmd.AddCommand(placesRemoveCmd)
 	placesCmd.AddCommand(placesDefaultCmd)
 	rootCmd.AddCommand(placesCmd)
@@ -76,6 +124,10 @@ func runPlacesList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
+	if outputFormat.IsStructured() {
+		return display.WriteRecords(os.Stdout, outputFormat, placeRecords(cfg))
+	}
+
 	if len(cfg.Places) == 0 {
 		fmt.Println("No saved places.")
 		fmt.Println("\nSave one with:")
@@ -97,7 +149,11 @@ func runPlacesList(cmd *cobra.Command, args []string) error {
 		if cfg.DefaultPlace == alias {
 			def = " (default)"
 		}
-		fmt.Printf("  \033[1m%-12s\033[0m %s (%s%s)%s\n", alias, p.Name, label, city, def)
+		settings := placeSettings(p)
+		if settings != "" {
+			settings = "  \033[2m" + settings + "\033[0m"
+		}
+		fmt.Printf("  \033[1m%-12s\033[0m %s (%s%s)%s%s\n", alias, p.Name, label, city, def, settings)
 	}
 	fmt.Println("\nUse with: metro d <alias>")
 
@@ -105,16 +161,23 @@ func runPlacesList(cmd *cobra.Command, args []string) error {
 }
 
 func runPlacesSave(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
 	alias := strings.ToLower(args[0])
 	query := strings.Join(args[1:], " ")
 
-	c, err := client.New()
+	// Check the settings before searching
+	var settings config.SavedPlace
+	if err := applyPlaceFlags(cmd, &settings); err != nil {
+		return err
+	}
+
+	c, err := newClient()
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Searching for \"%s\"...\n", query)
-	places, err := c.SearchPlaces(query)
+	infof("Searching for \"%s\"...\n", query)
+	places, err := c.SearchPlaces(ctx, query)
 	if err != nil {
 		return err
 	}
@@ -142,7 +205,10 @@ func runPlacesSave(cmd *cobra.Command, args []string) error {
 	}
 
 	// Save to config
-	if err := savePlace(alias, place); err != nil {
+	saved := newSavedPlace(place)
+	saved.Modes, saved.Lines, saved.Direction = settings.Modes, settings.Lines, settings.Direction
+	saved.Radius, saved.WalkMinutes = settings.Radius, settings.WalkMinutes
+	if err := savePlace(alias, saved); err != nil {
 		return err
 	}
 
@@ -151,6 +217,9 @@ func runPlacesSave(cmd *cobra.Command, args []string) error {
 		city = " (" + place.City + ")"
 	}
 	fmt.Printf("\nSaved \"%s\" as \033[1m%s\033[0m%s\n", alias, place.Name, city)
+	if settings := placeSettings(saved); settings != "" {
+		fmt.Printf("Settings: %s\n", settings)
+	}
 	fmt.Printf("Now use: metro d %s\n", alias)
 	return nil
 }
@@ -174,12 +243,18 @@ func runPlacesRemove(cmd *cobra.Command, args []string) error {
 	if cfg.DefaultPlace == alias {
 		cfg.DefaultPlace = ""
 	}
+	// Drop the commute rules starting there
+	rules := len(cfg.Commute)
+	cfg.Commute = slices.DeleteFunc(cfg.Commute, func(r config.CommuteRule) bool { return r.Place == alias })
 
 	if err := config.Save(cfg); err != nil {
 		return fmt.Errorf("saving config: %w", err)
 	}
 
 	fmt.Printf("Removed \"%s\" (%s)\n", alias, name)
+	if n := rules - len(cfg.Commute); n > 0 {
+		fmt.Printf("Removed %d commute rule(s) using it\n", n)
+	}
 	return nil
 }
 
@@ -206,17 +281,200 @@ func runPlacesDefault(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// savePlace persists a PRIMPlace as a saved place under the given alias.
-func savePlace(alias string, place model.PRIMPlace) error {
+func runPlacesEdit(cmd *cobra.Command, args []string) error {
+	alias := strings.ToLower(args[0])
+
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
-	if cfg.Places == nil {
-		cfg.Places = make(map[string]config.SavedPlace)
+	saved, ok := cfg.Places[alias]
+	if !ok {
+		return fmt.Errorf("no saved place named \"%s\"\nSave one first: metro places save %s <station>", alias, alias)
+	}
+
+	if cmd.Flags().NFlag() > 0 {
+		err = applyPlaceFlags(cmd, &saved)
+	} else {
+		err = promptPlaceSettings(&saved)
+	}
+	if err != nil {
+		return err
+	}
+	if err := savePlace(alias, saved); err != nil {
+		return err
+	}
+
+	settings := placeSettings(saved)
+	if settings == "" {
+		settings = "none"
+	}
+	fmt.Printf("\nUpdated \"%s\" (%s)\nSettings: %s\n", alias, saved.Name, settings)
+	return nil
+}
+
+// applyPlaceFlags sets the place settings given as flags on cmd.
+func applyPlaceFlags(cmd *cobra.Command, saved *config.SavedPlace) error {
+	flags := cmd.Flags()
+	if flags.Changed("mode") {
+		modes, err := parsePlaceModes(placeModes)
+		if err != nil {
+			return err
+		}
+		saved.Modes = modes
+	}
+	if flags.Changed("line") {
+		saved.Lines = cleanList(placeLines)
+	}
+	if flags.Changed("direction") {
+		saved.Direction = strings.TrimSpace(placeDirection)
+	}
+	if flags.Changed("radius") {
+		if err := checkRadius(placeRadius); err != nil {
+			return err
+		}
+		saved.Radius = placeRadius
+	}
+	if flags.Changed("walk") {
+		if err := checkWalk(placeWalk); err != nil {
+			return err
+		}
+		saved.WalkMinutes = placeWalk
+	}
+	return nil
+}
+
+// promptPlaceSettings asks for each setting in turn. Enter keeps the
+// current value and "-" clears it.
+func promptPlaceSettings(saved *config.SavedPlace) error {
+	infof("Editing %s. Enter keeps the current value, \"-\" clears it.\n\n", saved.Name)
+	ask := func(label, current string) (string, bool) {
+		if current == "" {
+			current = "none"
+		}
+		infof("%s [%s]: ", label, current)
+		input, _ := stdinReader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		switch input {
+		case "":
+			return "", false
+		case "-":
+			return "", true
+		}
+		return input, true
+	}
+
+	if v, ok := ask("Modes (e.g. metro,rer)", strings.Join(saved.Modes, ",")); ok {
+		modes, err := parsePlaceModes(v)
+		if err != nil {
+			return err
+		}
+		saved.Modes = modes
+	}
+	if v, ok := ask("Lines (e.g. M1,RER A)", strings.Join(saved.Lines, ",")); ok {
+		saved.Lines = cleanList(strings.Split(v, ","))
+	}
+	if v, ok := ask("Direction", saved.Direction); ok {
+		saved.Direction = v
+	}
+	if v, ok := ask("Nearby radius in meters", formatSetting(saved.Radius)); ok {
+		n, err := parseSetting(v, "radius")
+		if err == nil {
+			err = checkRadius(n)
+		}
+		if err != nil {
+			return err
+		}
+		saved.Radius = n
+	}
+	if v, ok := ask("Walk to the platform in minutes", formatSetting(saved.WalkMinutes)); ok {
+		n, err := parseSetting(v, "walk")
+		if err == nil {
+			err = checkWalk(n)
+		}
+		if err != nil {
+			return err
+		}
+		saved.WalkMinutes = n
+	}
+	return nil
+}
+
+// parsePlaceModes validates a mode list for a saved place. "all" or
+// nothing stores no modes.
+func parsePlaceModes(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	set, err := model.ParseModes(s, nil)
+	if err != nil || set.IsAll() {
+		return nil, err
+	}
+	return strings.Split(set.String(), ","), nil
+}
+
+// cleanList trims items and drops empty ones.
+func cleanList(items []string) []string {
+	var out []string
+	for _, it := range items {
+		if it = strings.TrimSpace(it); it != "" {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+func formatSetting(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func parseSetting(s, name string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: want a whole number", name, s)
 	}
+	return n, nil
+}
+
+func checkRadius(m int) error {
+	if m < 0 || m > 5000 {
+		return fmt.Errorf("radius must be between 0 and 5000 meters (got %d)", m)
+	}
+	return nil
+}
+
+func checkWalk(min int) error {
+	if min < 0 || min > 60 {
+		return fmt.Errorf("walk must be between 0 and 60 minutes (got %d)", min)
+	}
+	return nil
+}
+
+// placeSettings describes a place's departure settings, e.g.
+// "metro,rer · RER A towards marne · 800m · 6 min walk".
+func placeSettings(p config.SavedPlace) string {
+	var parts []string
+	if len(p.Modes) > 0 {
+		parts = append(parts, strings.Join(p.Modes, ","))
+	}
+	if f := describeFilters(departureTarget{Lines: p.Lines, Direction: p.Direction}); f != "" {
+		parts = append(parts, f)
+	}
+	if p.Radius > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", p.Radius))
+	}
+	if p.WalkMinutes > 0 {
+		parts = append(parts, fmt.Sprintf("%d min walk", p.WalkMinutes))
+	}
+	return strings.Join(parts, " · ")
+}
 
-	cfg.Places[alias] = config.SavedPlace{
+// newSavedPlace converts a PRIMPlace to a saved place without filters.
+func newSavedPlace(place model.PRIMPlace) config.SavedPlace {
+	return config.SavedPlace{
 		Name: place.Name,
 		Type: place.Type,
 		ID:   place.ID,
@@ -224,9 +482,53 @@ func savePlace(alias string, place model.PRIMPlace) error {
 		Lat:  place.Y,
 		Lon:  place.X,
 	}
+}
+
+// savePlace persists a saved place under the given alias.
+func savePlace(alias string, saved config.SavedPlace) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if cfg.Places == nil {
+		cfg.Places = make(map[string]config.SavedPlace)
+	}
+
+	cfg.Places[alias] = saved
 
 	if err := config.Save(cfg); err != nil {
 		return fmt.Errorf("saving config: %w", err)
 	}
 	return nil
 }
+
+// placeRecords converts saved places to structured records, sorted by alias.
+func placeRecords(cfg *config.Config) []display.PlaceRecord {
+	aliases := make([]string, 0, len(cfg.Places))
+	for alias := range cfg.Places {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	recs := make([]display.PlaceRecord, 0, len(aliases))
+	for _, alias := range aliases {
+		p := cfg.Places[alias]
+		recs = append(recs, display.PlaceRecord{
+			Alias:   alias,
+			Name:    p.Name,
+			Type:    p.Type,
+			ID:      p.ID,
+			City:    p.City,
+			Lat:     p.Lat,
+			Lon:     p.Lon,
+			Default: cfg.DefaultPlace == alias,
+
+			Modes:       p.Modes,
+			Lines:       p.Lines,
+			Direction:   p.Direction,
+			Radius:      p.Radius,
+			WalkMinutes: p.WalkMinutes,
+		})
+	}
+	return recs
+}
@@ -1,26 +1,172 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/cyrilghali/metro-cli/internal/client"
+	"github.com/cyrilghali/metro-cli/internal/config"
+	"github.com/cyrilghali/metro-cli/internal/display"
 	"github.com/spf13/cobra"
 )
 
 // Version is set at build time via ldflags.
 var Version = "dev"
 
+var (
+	outputFlag   string
+	outputFormat = display.FormatTable
+
+	offline        bool
+	maxStale       time.Duration
+	requestTimeout time.Duration
+
+	// quietInfo silences progress messages, e.g. while a watch loop redraws.
+	quietInfo bool
+)
+
 var rootCmd = &cobra.Command{
 	Use:               "metro",
 	Short:             "Paris metro departures and disruptions",
 	Long:              "A CLI tool to check next metro departures near you and current traffic disruptions in Paris.",
 	Version:           Version,
 	CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		f, err := display.ParseFormat(outputFlag)
+		if err != nil {
+			return err
+		}
+		outputFormat = f
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "table", "output format: table, json, yaml, csv")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "use cached data only, never the network")
+	rootCmd.PersistentFlags().DurationVar(&maxStale, "max-stale", 0, "show cached data up to this old when the network fails (e.g. 1h)")
+	rootCmd.PersistentFlags().DurationVar(&requestTimeout, "timeout", client.DefaultTimeout, "deadline for each API request attempt")
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// The first Ctrl-C cancels the command context, aborting requests in
+	// flight and stopping watch loops; a second one kills the process as
+	// usual (e.g. while waiting at an interactive prompt).
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		if ctx.Err() != nil {
+			os.Exit(130)
+		}
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
+
+// newClient creates an API client. The base URL comes from PRIM_BASE_URL,
+// then base_url in the config file, then the public PRIM endpoint.
+// Responses are cached on disk; --offline and --max-stale control when
+// stale cached data may be shown instead, and --timeout bounds each
+// request attempt. With history on, fetched disruptions are recorded
+// (see metro history).
+func newClient() (*client.Client, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	base := os.Getenv("PRIM_BASE_URL")
+	if base == "" {
+		base = cfg.BaseURL
+	}
+	if requestTimeout <= 0 {
+		return nil, fmt.Errorf("--timeout must be positive (got %s)", requestTimeout)
+	}
+	c, err := client.New(base)
+	if err != nil {
+		return nil, err
+	}
+	c.SetTimeout(requestTimeout)
+	if dir, err := client.DefaultCacheDir(); err == nil {
+		c.SetCache(client.NewCache(dir), client.CacheOptions{Offline: offline, MaxStale: maxStale})
+	} else if offline {
+		return nil, fmt.Errorf("--offline needs a cache directory: %w", err)
+	}
+	// Offline data was recorded when it was fetched
+	if cfg.History && !offline {
+		if err := recordHistory(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// staleBanner returns a warning when cached data was shown instead of
+// fresh data (offline or network failure), or "" if all data was fresh.
+func staleBanner(c *client.Client) string {
+	msg := staleMessage(c)
+	if msg == "" {
+		return ""
+	}
+	return "\033[33m! " + msg + "\033[0m"
+}
+
+// staleMessage is staleBanner without formatting, e.g. for HTML.
+func staleMessage(c *client.Client) string {
+	at, ok := c.TakeStale()
+	if !ok {
+		return ""
+	}
+	reason := "network unavailable"
+	if offline {
+		reason = "offline"
+	}
+	return fmt.Sprintf("%s: showing data from %s (%s)", reason, formatAge(time.Since(at)), at.Format("15:04"))
+}
+
+// printStale prints the stale data banner, if any (see infoOut).
+func printStale(c *client.Client) {
+	if msg := staleBanner(c); msg != "" {
+		fmt.Fprintf(infoOut(), "%s\n\n", msg)
+	}
+}
+
+// formatAge returns "just now", "4 min ago", "2h ago" or "3 days ago".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < 90*time.Minute:
+		return fmt.Sprintf("%d min ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d days ago", int(d.Hours()/24))
+	}
+}
+
+// infoOut is where progress and prompt messages go. With a structured
+// --output format they move to stderr so stdout stays machine-readable.
+func infoOut() io.Writer {
+	if quietInfo {
+		return io.Discard
+	}
+	if outputFormat.IsStructured() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// infof prints a progress message (see infoOut).
+func infof(format string, a ...any) {
+	fmt.Fprintf(infoOut(), format, a...)
+}
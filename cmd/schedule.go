@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/client"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+// serviceDayHour is when a transport day begins in Paris local time.
+// Trains running after midnight belong to the previous day's timetable.
+const serviceDayHour = 4
+
+// maxArrivals caps the arrivals fetched for one day at a stop.
+const maxArrivals = 1000
+
+var (
+	timetableLine string
+	timetableDate string
+)
+
+var scheduleCmd = &cobra.Command{
+	Use:     "schedule [station]",
+	Aliases: []string{"timetable"},
+	Short:   "Show the full day's timetable at a station",
+	Long: `Show the scheduled departures of a whole day at a station, hour by hour,
+with the first and last service of each line and direction.
+
+The day runs from 04:00 to 04:00, so late-night trains after midnight
+are included. For today, past times are dimmed and the next one is
+highlighted. An address uses the nearest station.
+
+Dates accept "today" (default), "tomorrow", a weekday ("sat") or
+"YYYY-MM-DD".
+
+Aliases: timetable
+
+Examples:
+  metro schedule chatelet --line M1
+  metro schedule home --line "RER A" --date sat
+  metro schedule "gare de lyon" --line M14 --date 2026-03-02`,
+	RunE: runSchedule,
+}
+
+var arrivalsCmd = &cobra.Command{
+	Use:   "arrivals [station]",
+	Short: "Show the full day's arrivals at a station",
+	Long: `Show the scheduled arrivals of a whole day at a station, hour by hour,
+with the first and last arrival of each line and direction.
+
+Unlike "metro schedule", this includes trains ending their run at the
+station, e.g. the last train back to a terminus.
+
+Examples:
+  metro arrivals "la defense" --line M1
+  metro arrivals home --date tomorrow`,
+	RunE: runArrivals,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{scheduleCmd, arrivalsCmd} {
+		cmd.Flags().StringVar(&timetableLine, "line", "", "only this line (e.g. M1, RER A, T3a)")
+		cmd.Flags().StringVar(&timetableDate, "date", "today", "day to show (today, tomorrow, sat, YYYY-MM-DD)")
+		rootCmd.AddCommand(cmd)
+	}
+}
+
+func runSchedule(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	day, err := serviceDay(timetableDate, time.Now())
+	if err != nil {
+		return err
+	}
+	target, err := resolveStation(ctx, c, args)
+	if err != nil {
+		return err
+	}
+
+	infof("Fetching timetable...\n\n")
+	resp, err := c.StopSchedules(ctx, target.StopID, display.FormatNavitiaTime(day), 24*time.Hour, timetableFilter())
+	if err != nil {
+		return err
+	}
+
+	printStale(c)
+	return renderTimetables(os.Stdout, target, "departures", day, display.ScheduleTimetables(resp.StopSchedules))
+}
+
+func runArrivals(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	day, err := serviceDay(timetableDate, time.Now())
+	if err != nil {
+		return err
+	}
+	target, err := resolveStation(ctx, c, args)
+	if err != nil {
+		return err
+	}
+
+	infof("Fetching arrivals...\n\n")
+	resp, err := c.Arrivals(ctx, target.StopID, display.FormatNavitiaTime(day), 24*time.Hour, maxArrivals, timetableFilter())
+	if err != nil {
+		return err
+	}
+
+	printStale(c)
+	return renderTimetables(os.Stdout, target, "arrivals", day, display.ArrivalTimetables(resp.Arrivals))
+}
+
+// timetableFilter returns the Navitia filter for --line, if set.
+func timetableFilter() string {
+	if timetableLine == "" {
+		return ""
+	}
+	return model.LineFilter(timetableLine)
+}
+
+// resolveStation resolves the arguments to a single stop area, like
+// departures do. Addresses and --here use the nearest stop area.
+func resolveStation(ctx context.Context, c *client.Client, args []string) (departureTarget, error) {
+	target, err := resolveDepartureTarget(ctx, c, args, model.AllModes)
+	if err != nil || target.StopID != "" {
+		return target, err
+	}
+
+	infof("Finding the nearest station...\n")
+	nearby, err := c.PlacesNearby(ctx, target.Lon, target.Lat, target.radius(), nil)
+	if err != nil {
+		return departureTarget{}, err
+	}
+	for _, pn := range nearby.PlacesNearby {
+		if pn.StopPoint != nil && pn.StopPoint.StopArea != nil {
+			sa := pn.StopPoint.StopArea
+			return departureTarget{StopID: sa.ID, Name: sa.Name}, nil
+		}
+	}
+	return departureTarget{}, fmt.Errorf("no station found within %dm", target.radius())
+}
+
+// serviceDay returns the start of the transport day named by s, in Paris
+// time. "today" before 04:00 still means the day that started yesterday.
+func serviceDay(s string, now time.Time) (time.Time, error) {
+	loc := display.Paris()
+	// Built from the wall clock, as adding hours to midnight is off by
+	// one on daylight saving days
+	start := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), serviceDayHour, 0, 0, 0, loc)
+	}
+	today := now.In(loc)
+	if today.Before(start(today)) {
+		today = today.AddDate(0, 0, -1)
+	}
+
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "", "today":
+		return start(today), nil
+	case "tomorrow":
+		return start(today.AddDate(0, 0, 1)), nil
+	}
+	for i := 0; i < 7; i++ {
+		d := today.AddDate(0, 0, i)
+		name := strings.ToLower(d.Weekday().String())
+		if len(s) >= 3 && strings.HasPrefix(name, s) {
+			return start(d), nil
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		return start(t), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (use today, tomorrow, a weekday or YYYY-MM-DD)", s)
+}
+
+// renderTimetables writes a station's timetables, or records for
+// structured --output. kind is "departures" or "arrivals".
+func renderTimetables(w io.Writer, target departureTarget, kind string, day time.Time, tts []display.Timetable) error {
+	if outputFormat.IsStructured() {
+		return display.WriteRecords(w, outputFormat, display.TimetableRecords(target.StopID, target.Name, tts))
+	}
+
+	name := fmt.Sprintf("\033[1m%s\033[0m", target.Name)
+	if target.City != "" {
+		name += fmt.Sprintf(" (%s)", target.City)
+	}
+	fmt.Fprintf(w, "%s · %s on %s\n\n", name, kind, day.Format("Mon 2 Jan"))
+	display.Timetables(w, tts, time.Now())
+	return nil
+}
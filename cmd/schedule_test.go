@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/display"
+)
+
+func TestServiceDay(t *testing.T) {
+	loc := display.Paris()
+	// Wednesday 25 Feb 2026
+	afternoon := time.Date(2026, 2, 25, 15, 0, 0, 0, loc)
+	lateNight := time.Date(2026, 2, 26, 1, 30, 0, 0, loc)
+
+	tests := []struct {
+		input string
+		now   time.Time
+		want  string
+	}{
+		{"today", afternoon, "2026-02-25 04:00"},
+		{"", afternoon, "2026-02-25 04:00"},
+		{"today", lateNight, "2026-02-25 04:00"},
+		{"tomorrow", afternoon, "2026-02-26 04:00"},
+		{"tomorrow", lateNight, "2026-02-26 04:00"},
+		{"wed", afternoon, "2026-02-25 04:00"},
+		{"Saturday", afternoon, "2026-02-28 04:00"},
+		{"tue", afternoon, "2026-03-03 04:00"},
+		{"2026-07-14", afternoon, "2026-07-14 04:00"},
+		// Daylight saving changes at 02:00 or 03:00
+		{"2026-03-29", afternoon, "2026-03-29 04:00"},
+		{"2026-10-25", afternoon, "2026-10-25 04:00"},
+		{"today", time.Date(2026, 3, 29, 3, 30, 0, 0, loc), "2026-03-28 04:00"},
+		{"today", time.Date(2026, 3, 29, 4, 30, 0, 0, loc), "2026-03-29 04:00"},
+		{"today", time.Date(2026, 10, 25, 3, 30, 0, 0, loc), "2026-10-24 04:00"},
+	}
+	for _, tt := range tests {
+		got, err := serviceDay(tt.input, tt.now)
+		if err != nil {
+			t.Errorf("serviceDay(%q) unexpected error: %v", tt.input, err)
+			continue
+		}
+		if s := got.Format("2006-01-02 15:04"); s != tt.want {
+			t.Errorf("serviceDay(%q, %s) = %s, want %s", tt.input, tt.now.Format("Mon 15:04"), s, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"soon", "w", "2026-13-01"} {
+		if _, err := serviceDay(bad, afternoon); err == nil {
+			t.Errorf("serviceDay(%q) expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/client"
+	"github.com/cyrilghali/metro-cli/internal/config"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var serveAddr string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve departures and line status as a local JSON API",
+	Long: `Run an HTTP server exposing departures, line status and saved places as
+JSON, so wall displays, home automation and phone shortcuts can share one
+PRIM token and one response cache.
+
+Endpoints:
+  GET /departures?place=home    departures at a saved place, station or
+                                address (default place if omitted);
+                                also accepts mode=metro or mode=metro,rer
+  GET /disruptions?mode=rer     line status; also accepts line=A
+  GET /places                   saved places
+  GET /health                   liveness check
+
+Records use the same fields as --output json. Saved places are re-read
+on every request, so "metro places save" takes effect immediately.
+
+Examples:
+  metro serve
+  metro serve --addr 127.0.0.1:9000
+  curl 'localhost:8080/departures?place=home&mode=metro'`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "address to listen on")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return err
+	}
+	quietInfo = true
+	defer func() { quietInfo = false }()
+	return serveHTTP(ctx, ln, newServeMux(c))
+}
+
+// serveHTTP serves h on ln until ctx is cancelled (Ctrl-C), then waits
+// briefly for requests in flight.
+func serveHTTP(ctx context.Context, ln net.Listener, h http.Handler) error {
+	srv := &http.Server{Handler: logRequests(h), ReadHeaderTimeout: 10 * time.Second}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+	fmt.Fprintf(os.Stderr, "Listening on http://%s (Ctrl-C to stop)\n", ln.Addr())
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+// newServeMux returns the API handlers. All requests share c, and with it
+// the on-disk response cache.
+func newServeMux(c *client.Client) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("GET /departures", jsonHandler(func(r *http.Request) (any, error) {
+		return serveDepartures(r.Context(), c, r.URL.Query().Get("place"), r.URL.Query().Get("mode"))
+	}))
+	mux.Handle("GET /disruptions", jsonHandler(func(r *http.Request) (any, error) {
+		return serveDisruptions(r.Context(), c, r.URL.Query().Get("mode"), r.URL.Query().Get("line"))
+	}))
+	mux.Handle("GET /places", jsonHandler(func(r *http.Request) (any, error) {
+		return servePlaces()
+	}))
+	mux.Handle("GET /health", jsonHandler(func(r *http.Request) (any, error) {
+		return map[string]string{"status": "ok", "version": Version}, nil
+	}))
+	return mux
+}
+
+// httpError is an error with the HTTP status to answer with. Other errors
+// are upstream failures (502).
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string { return e.err.Error() }
+
+func badRequest(err error) error { return &httpError{http.StatusBadRequest, err} }
+
+func notFound(err error) error { return &httpError{http.StatusNotFound, err} }
+
+// jsonHandler writes fn's result as JSON, or {"error": "..."} with a
+// status code matching the error.
+func jsonHandler(fn func(r *http.Request) (any, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		body, err := fn(r)
+		status := http.StatusOK
+		if err != nil {
+			status = http.StatusBadGateway
+			var he *httpError
+			var qe *client.QuotaError
+			switch {
+			case errors.As(err, &he):
+				status = he.status
+			case errors.As(err, &qe):
+				status = http.StatusServiceUnavailable
+			}
+			body = map[string]string{"error": err.Error()}
+		}
+		w.WriteHeader(status)
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(body)
+	})
+}
+
+// statusRecorder captures the response status for logging.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests logs one line per request to stderr.
+func logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+		fmt.Fprintf(os.Stderr, "%s %s %s %d %s\n", start.Format("15:04:05"), r.Method, r.URL.RequestURI(), rec.status, time.Since(start).Round(time.Millisecond))
+	})
+}
+
+// departuresResponse is the body of GET /departures.
+type departuresResponse struct {
+	Place      string                    `json:"place"`
+	Name       string                    `json:"name"`
+	Departures []display.DepartureRecord `json:"departures"`
+	Errors     []string                  `json:"errors,omitempty"`
+}
+
+func serveDepartures(ctx context.Context, c *client.Client, place, modeName string) (*departuresResponse, error) {
+	mode, err := parseServeMode(modeName)
+	if err != nil {
+		return nil, err
+	}
+	target, err := serveTarget(ctx, c, place, mode)
+	if err != nil {
+		return nil, err
+	}
+	boards, err := fetchBoards(ctx, c, target, mode, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &departuresResponse{Place: place, Name: target.Name, Departures: []display.DepartureRecord{}}
+	now := time.Now()
+	for _, b := range boards {
+		if b.Err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %v", b.Name, b.Err))
+			continue
+		}
+		resp.Departures = append(resp.Departures, display.DepartureRecords(b.ID, b.Name, b.Resp.Departures, b.Resp.Disruptions, now)...)
+	}
+	return resp, nil
+}
+
+// serveTarget resolves a place query without prompting: a saved alias,
+// else the first matching stop area or address. An empty query is the
+// commute or default place.
+func serveTarget(ctx context.Context, c *client.Client, place string, mode model.ModeSet) (departureTarget, error) {
+	if place == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return departureTarget{}, fmt.Errorf("loading config: %w", err)
+		}
+		alias, _ := commutePlace(cfg, time.Now())
+		saved, ok := cfg.Places[alias]
+		if !ok {
+			return departureTarget{}, badRequest(fmt.Errorf("no place given and no default place set"))
+		}
+		return savedPlaceTarget(ctx, c, saved)
+	}
+	if saved, ok := lookupSavedPlace(place); ok {
+		return savedPlaceTarget(ctx, c, saved)
+	}
+
+	results, err := c.SearchPlaces(ctx, place)
+	if err != nil {
+		return departureTarget{}, err
+	}
+	candidates := placeCandidates(results.Places, mode)
+	if len(candidates) == 0 {
+		return departureTarget{}, notFound(fmt.Errorf("no stops or addresses found for \"%s\"", place))
+	}
+	return placeTarget(ctx, c, candidates[0])
+}
+
+// disruptionsResponse is the body of GET /disruptions.
+type disruptionsResponse struct {
+	Mode   string                     `json:"mode"`
+	Lines  []display.LineStatusRecord `json:"lines"`
+	Errors []string                   `json:"errors,omitempty"`
+}
+
+func serveDisruptions(ctx context.Context, c *client.Client, modeName, line string) (*disruptionsResponse, error) {
+	mode, err := parseServeMode(modeName)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := fetchStatuses(ctx, c, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &disruptionsResponse{Mode: mode.String(), Lines: []display.LineStatusRecord{}}
+	for _, st := range statuses {
+		if st.Err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %v", st.Mode.Name, st.Err))
+			continue
+		}
+		resp.Lines = append(resp.Lines, display.LineStatusRecords(st.Resp, line, st.Mode)...)
+	}
+	return resp, nil
+}
+
+// placesResponse is the body of GET /places.
+type placesResponse struct {
+	Default string                `json:"default,omitempty"`
+	Places  []display.PlaceRecord `json:"places"`
+}
+
+func servePlaces() (*placesResponse, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	return &placesResponse{Default: cfg.DefaultPlace, Places: placeRecords(cfg)}, nil
+}
+
+// parseServeMode parses the mode query parameter, a comma-separated list
+// as for -m, "all" when empty.
+func parseServeMode(s string) (model.ModeSet, error) {
+	if s == "" {
+		s = "all"
+	}
+	mode, err := model.ParseModes(strings.ToLower(s), nil)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	return mode, nil
+}
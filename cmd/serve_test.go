@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cyrilghali/metro-cli/internal/config"
+)
+
+// getJSON fetches path from srv and decodes the body into v.
+func getJSON(t *testing.T, srv *httptest.Server, path string, v any) int {
+	t.Helper()
+	resp, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%s: Content-Type = %q", path, ct)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("%s: decoding body: %v", path, err)
+	}
+	return resp.StatusCode
+}
+
+func TestServe(t *testing.T) {
+	setupFakePRIM(t)
+	saveTestPlaces(t, "home", map[string]config.SavedPlace{"home": chatelet})
+	quietInfo = true
+	t.Cleanup(func() { quietInfo = false })
+
+	c, err := newClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newServeMux(c))
+	defer srv.Close()
+
+	var deps departuresResponse
+	if status := getJSON(t, srv, "/departures?place=home", &deps); status != http.StatusOK {
+		t.Fatalf("/departures status = %d", status)
+	}
+	if deps.Name != "Châtelet" || len(deps.Departures) == 0 || deps.Departures[0].StopID != chatelet.ID {
+		t.Errorf("/departures = %+v", deps)
+	}
+
+	// No place: the default one; a station name is searched
+	for _, path := range []string{"/departures", "/departures?place=gare+de+lyon&mode=metro"} {
+		var resp departuresResponse
+		if status := getJSON(t, srv, path, &resp); status != http.StatusOK || len(resp.Departures) == 0 {
+			t.Errorf("%s: status %d, %d departures", path, status, len(resp.Departures))
+		}
+	}
+
+	var dis disruptionsResponse
+	if status := getJSON(t, srv, "/disruptions?mode=rer", &dis); status != http.StatusOK {
+		t.Fatalf("/disruptions status = %d", status)
+	}
+	if dis.Mode != "rer" || len(dis.Lines) == 0 {
+		t.Errorf("/disruptions = %+v", dis)
+	}
+	for _, l := range dis.Lines {
+		if l.Mode != "rer" {
+			t.Errorf("/disruptions?mode=rer returned %s line %s", l.Mode, l.Line)
+		}
+	}
+
+	var places placesResponse
+	getJSON(t, srv, "/places", &places)
+	if places.Default != "home" || len(places.Places) != 1 || places.Places[0].Alias != "home" {
+		t.Errorf("/places = %+v", places)
+	}
+
+	var health map[string]string
+	if status := getJSON(t, srv, "/health", &health); status != http.StatusOK || health["status"] != "ok" {
+		t.Errorf("/health = %d %v", status, health)
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	setupFakePRIM(t)
+	quietInfo = true
+	t.Cleanup(func() { quietInfo = false })
+
+	c, err := newClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newServeMux(c))
+	defer srv.Close()
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/departures", http.StatusBadRequest}, // no default place
+		{"/departures?place=nowhere", http.StatusNotFound},
+		{"/disruptions?mode=boat", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		var body map[string]string
+		if status := getJSON(t, srv, tt.path, &body); status != tt.status || body["error"] == "" {
+			t.Errorf("%s = %d %v, want %d with an error", tt.path, status, body, tt.status)
+		}
+	}
+}
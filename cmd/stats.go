@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/client"
+	"github.com/cyrilghali/metro-cli/internal/config"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/cyrilghali/metro-cli/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsInterval time.Duration
+	statsLine     string
+	statsSince    string
+	statsBetween  string
+	statsOnTime   time.Duration
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Record departures and report how punctual they are",
+	Long: `Measure punctuality from realtime departures.
+
+"metro stats record" samples the departures at your places and stores the
+last realtime estimate of every departure before it leaves. "metro stats
+report" then compares those with the timetable.
+
+Examples:
+  metro stats record home --interval 1m
+  metro stats report
+  metro stats report home --line "RER B" --between 08:00-08:45`,
+}
+
+var statsRecordCmd = &cobra.Command{
+	Use:   "record [place...]",
+	Short: "Sample departures at places until Ctrl-C",
+	Long: `Sample realtime departures at places every --interval and record each
+departure once it has left, with its scheduled and last estimated times.
+Departures without realtime data are not recorded.
+
+Without arguments, every saved place is sampled. The interval bounds the
+precision: the last estimate is at most one interval before departure.
+
+Examples:
+  metro stats record home
+  metro stats record home work --interval 1m`,
+	RunE: runStatsRecord,
+}
+
+var statsReportCmd = &cobra.Command{
+	Use:   "report [place]",
+	Short: "Report delays per line and direction",
+	Long: `Report the recorded departures' punctuality per line and direction:
+median and 90th percentile delay, and the share of departures on time
+(at most --on-time late). The worst hours of the week come after.
+
+--between restricts the report to departures scheduled in a time window
+and breaks them down by 5-minute slot, to compare e.g. leaving at 8:10
+with 8:25.
+
+The place filters on the recorded place or stop name. --since takes days
+("30d"), weeks ("2w"), hours ("12h") or a date ("2026-09-01").
+
+Examples:
+  metro stats report
+  metro stats report home --line M1 --since 2w
+  metro stats report --line "RER B" --between 08:00-09:00 -o csv`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runStatsReport,
+}
+
+func init() {
+	statsRecordCmd.Flags().DurationVar(&statsInterval, "interval", 2*time.Minute, "how often to sample departures")
+	statsReportCmd.Flags().StringVar(&statsLine, "line", "", "only this line (e.g. M1, \"RER B\")")
+	statsReportCmd.Flags().StringVar(&statsSince, "since", "30d", "period to report (e.g. 7d, 2w, 2026-09-01)")
+	statsReportCmd.Flags().StringVar(&statsBetween, "between", "", "departures scheduled in this window, by time slot (e.g. 08:00-09:00)")
+	statsReportCmd.Flags().DurationVar(&statsOnTime, "on-time", time.Minute, "how late a departure may be and still count as on time")
+	statsCmd.AddCommand(statsRecordCmd)
+	statsCmd.AddCommand(statsReportCmd)
+	rootCmd.AddCommand(statsCmd)
+}
+
+// statsPath returns the departure samples log path.
+func statsPath() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", fmt.Errorf("no data directory for statistics: %w", err)
+	}
+	return filepath.Join(dir, stats.FileName), nil
+}
+
+func runStatsRecord(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if statsInterval < 30*time.Second {
+		return fmt.Errorf("--interval must be at least 30s (got %s)", statsInterval)
+	}
+	path, err := statsPath()
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	places, err := resolvePlaces(ctx, c, args, 0)
+	if err != nil {
+		return err
+	}
+
+	titles := make([]string, len(places))
+	for i, p := range places {
+		titles[i] = p.Title
+	}
+	infof("Recording departures at %s every %s to %s (Ctrl-C to stop)\n\n", strings.Join(titles, ", "), statsInterval, path)
+
+	quietInfo = true
+	defer func() { quietInfo = false }()
+
+	r := &statsRecorder{c: c, places: places, log: stats.Open(path), tracker: stats.NewTracker()}
+	tick := time.NewTicker(statsInterval)
+	defer tick.Stop()
+	for {
+		r.sample(ctx, time.Now(), os.Stdout)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick.C:
+		}
+	}
+}
+
+// statsRecorder samples departures at places and logs them once they
+// have left.
+type statsRecorder struct {
+	c       *client.Client
+	places  []namedPlace
+	log     *stats.Log
+	tracker *stats.Tracker
+	total   int
+}
+
+// sample takes one sampling at now and logs the departures that left
+// since the previous one.
+func (r *statsRecorder) sample(ctx context.Context, now time.Time, out io.Writer) {
+	var samples []stats.Sample
+	for _, p := range r.places {
+		// Sample every departure, not just those reachable on foot
+		target := p.Target
+		target.Walk = 0
+		boards, err := fetchBoards(ctx, r.c, target, model.AllModes, time.Time{})
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", p.Title, err)
+			}
+			continue
+		}
+		for _, b := range boards {
+			if b.Err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", b.Name, b.Err)
+				continue
+			}
+			samples = append(samples, departureSamples(p.Title, b, now)...)
+		}
+	}
+
+	left := r.tracker.Observe(samples, now)
+	if len(left) == 0 {
+		return
+	}
+	if err := r.log.Add(left); err != nil {
+		fmt.Fprintf(os.Stderr, "recording departures: %v\n", err)
+		return
+	}
+	r.total += len(left)
+	fmt.Fprintf(out, "%s  recorded %d departures (%d in total)\n", now.In(display.Paris()).Format("15:04"), len(left), r.total)
+}
+
+// departureSamples returns the realtime departures on a board.
+func departureSamples(place string, b stopBoard, now time.Time) []stats.Sample {
+	var samples []stats.Sample
+	for _, d := range b.Resp.Departures {
+		sdt := d.StopDateTime
+		if sdt.DataFreshness != "realtime" {
+			continue
+		}
+		expected, err := display.ParseNavitiaTime(sdt.DepartureDateTime)
+		if err != nil {
+			continue
+		}
+		scheduled, err := display.ParseNavitiaTime(sdt.BaseDateTime)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, stats.Sample{
+			Place:      place,
+			StopID:     b.ID,
+			Stop:       b.Name,
+			Line:       model.LineLabel(d.DisplayInformations.Code, d.DisplayInformations.CommercialMode),
+			Direction:  d.DisplayInformations.Direction,
+			Scheduled:  scheduled,
+			Expected:   expected,
+			ObservedAt: now,
+		})
+	}
+	return samples
+}
+
+func runStatsReport(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	since, err := parseSince(statsSince, now)
+	if err != nil {
+		return err
+	}
+	if statsOnTime < 0 {
+		return fmt.Errorf("--on-time must not be negative (got %s)", statsOnTime)
+	}
+	var from, to time.Duration
+	if statsBetween != "" {
+		if from, to, err = parseBetween(statsBetween); err != nil {
+			return err
+		}
+	}
+	path, err := statsPath()
+	if err != nil {
+		return err
+	}
+	samples, err := stats.Load(path, since)
+	if err != nil {
+		return fmt.Errorf("reading statistics: %w", err)
+	}
+
+	place := ""
+	if len(args) > 0 {
+		place = args[0]
+	}
+	var matchLine func(string) bool
+	if statsLine != "" {
+		matchLine = func(label string) bool { return model.MatchLine(label, statsLine) }
+	}
+	samples = stats.Filter(samples, place, matchLine)
+	if statsBetween != "" {
+		samples = scheduledBetween(samples, from, to)
+	}
+
+	report := display.StatsReport{
+		Since:      since,
+		Until:      now,
+		Slack:      statsOnTime,
+		Departures: len(samples),
+		Lines:      stats.ByLine(samples, statsOnTime),
+		WorstHours: stats.WorstHours(samples, display.Paris(), statsOnTime, 5, 5),
+	}
+	if statsBetween != "" {
+		report.Slots = stats.BySlot(samples, display.Paris(), 5*time.Minute, statsOnTime)
+	}
+	if outputFormat.IsStructured() {
+		return display.WriteRecords(os.Stdout, outputFormat, display.StatsRecords(report))
+	}
+	display.Stats(os.Stdout, report)
+	return nil
+}
+
+// parseBetween parses a time of day window like "08:00-09:00" into
+// offsets from midnight.
+func parseBetween(s string) (from, to time.Duration, err error) {
+	a, b, ok := strings.Cut(s, "-")
+	if ok {
+		from, err = parseClock(a)
+		if err == nil {
+			to, err = parseClock(b)
+		}
+	}
+	if !ok || err != nil || to <= from {
+		return 0, 0, fmt.Errorf("invalid --between %q (e.g. 08:00-09:00)", s)
+	}
+	return from, to, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// scheduledBetween keeps the samples scheduled in [from, to) after
+// midnight, Paris time.
+func scheduledBetween(samples []stats.Sample, from, to time.Duration) []stats.Sample {
+	var out []stats.Sample
+	for _, s := range samples {
+		t := s.Scheduled.In(display.Paris())
+		offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		if offset >= from && offset < to {
+			out = append(out, s)
+		}
+	}
+	return out
+}
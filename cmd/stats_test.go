@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/stats"
+)
+
+func TestStatsRecordAndReport(t *testing.T) {
+	setupFakePRIM(t)
+	quietInfo = true
+	t.Cleanup(func() { quietInfo = false })
+
+	c, err := newClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := statsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The walking time must not hide departures from the samples
+	r := &statsRecorder{
+		c:       c,
+		places:  []namedPlace{{Title: "home · Châtelet", Target: departureTarget{StopID: chatelet.ID, Name: chatelet.Name, Walk: 5 * time.Minute}}},
+		log:     stats.Open(path),
+		tracker: stats.NewTracker(),
+	}
+
+	// Fixture departures: M1 14:30 (+2) and 14:36, M14 14:34, all realtime,
+	// and M1 14:33 to Vincennes from the base schedule
+	at := func(hhmm string) time.Time {
+		t, _ := time.ParseInLocation("20060102 15:04", "20260225 "+hhmm, display.Paris())
+		return t
+	}
+	var out bytes.Buffer
+	r.sample(context.Background(), at("14:31"), &out)
+	if out.Len() != 0 {
+		t.Errorf("nothing has left at 14:31, got %q", out.String())
+	}
+	r.sample(context.Background(), at("14:35"), &out)
+	if !strings.Contains(out.String(), "14:35  recorded 2 departures (2 in total)") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	samples, err := stats.Load(path, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 2 || samples[0].Line != "M1" || samples[0].Delay() != 2*time.Minute || samples[1].Line != "M14" {
+		t.Fatalf("samples = %+v", samples)
+	}
+
+	report, err := runCLI(t, "stats", "report", "--since", "2026-02-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Punctuality since Sun 1 Feb 2026", "2 departures", "La Défense", "Saint-Denis Pleyel", "+2"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+
+	csv, err := runCLI(t, "stats", "report", "home", "--line", "1", "--since", "2026-02-01", "--between", "14:00-15:00", "-o", "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "line,direction,departures,median_delay_minutes,p90_delay_minutes,on_time_share,scheduled_at\n" +
+		"M1,La Défense (Grande Arche),1,2,2,0.000,14:30\n"
+	if csv != want {
+		t.Errorf("CSV = %q, want %q", csv, want)
+	}
+}
+
+func TestParseBetween(t *testing.T) {
+	from, to, err := parseBetween("08:00-09:30")
+	if err != nil || from != 8*time.Hour || to != 9*time.Hour+30*time.Minute {
+		t.Errorf("parseBetween = %s, %s, %v", from, to, err)
+	}
+	for _, bad := range []string{"08:00", "09:00-08:00", "8h-9h", ""} {
+		if _, _, err := parseBetween(bad); err == nil {
+			t.Errorf("parseBetween(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,694 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cyrilghali/metro-cli/internal/client"
+	"github.com/cyrilghali/metro-cli/internal/config"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// maxPlaces is how many places fit side by side on the dashboard and
+// the HTML board.
+const maxPlaces = 4
+
+var (
+	tuiRefresh time.Duration
+	tuiMode    string
+	tuiExclude []string
+)
+
+var tuiCmd = &cobra.Command{
+	Use:     "tui [place...]",
+	Aliases: []string{"dashboard"},
+	Short:   "Full-screen dashboard of departures and line status",
+	Long: `Show departures for several places side by side, with the network
+disruption summary below, refreshed automatically until you quit.
+
+Without arguments, all saved places are shown (default place first, up
+to 4). Places can be saved aliases, stations or addresses.
+
+Keys:
+  m / M        next / previous transport mode
+  ↑ ↓  j k     select a disruption
+  enter        show the selected disruption's full text (esc to close)
+  p            search a place and add it to the board
+  r            refresh now
+  q            quit
+
+Aliases: dashboard
+
+Examples:
+  metro tui
+  metro tui home work
+  metro tui home -m rer --refresh 1m`,
+	RunE: runTUI,
+}
+
+func init() {
+	tuiCmd.Flags().DurationVar(&tuiRefresh, "refresh", defaultWatchInterval, "how often to refetch departures and line status")
+	tuiCmd.Flags().StringVarP(&tuiMode, "mode", "m", "all", "initial transport filter, comma-separated (m cycles it)")
+	tuiCmd.Flags().StringSliceVar(&tuiExclude, "exclude-mode", nil, "leave out these transport modes (e.g. bus)")
+	rootCmd.AddCommand(tuiCmd)
+}
+
+func runTUI(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if outputFormat.IsStructured() {
+		return fmt.Errorf("metro tui cannot be combined with --output %s", outputFormat)
+	}
+	if tuiRefresh < 5*time.Second {
+		return fmt.Errorf("--refresh interval must be at least 5s (got %s)", tuiRefresh)
+	}
+	inFd, outFd := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if !term.IsTerminal(inFd) || !term.IsTerminal(outFd) {
+		return fmt.Errorf("metro tui needs an interactive terminal")
+	}
+
+	mode, err := model.ParseModes(tuiMode, tuiExclude)
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	places, err := resolvePlaces(ctx, c, args, maxPlaces)
+	if err != nil {
+		return err
+	}
+	// Refreshes, periodic or with r, must not be served from the cache
+	c.SetRefresh(true)
+
+	quietInfo = true
+	defer func() { quietInfo = false }()
+
+	state, err := term.MakeRaw(inFd)
+	if err != nil {
+		return fmt.Errorf("setting up terminal: %w", err)
+	}
+	defer term.Restore(inFd, state)
+
+	// Alternate screen, hidden cursor; both restored on exit.
+	fmt.Print("\033[?1049h\033[?25l")
+	defer fmt.Print("\033[?25h\033[?1049l")
+
+	keys := make(chan string)
+	go readKeys(os.Stdin, keys)
+
+	d := newDashboard(c, places, mode)
+	return d.run(ctx, keys, func(now time.Time) {
+		w, h, err := term.GetSize(outFd)
+		if err != nil {
+			w, h = 100, 30
+		}
+		writeRawFrame(os.Stdout, d.render(w, h, now))
+	})
+}
+
+// namedPlace is one column of the dashboard or HTML board.
+type namedPlace struct {
+	Title  string
+	Target departureTarget
+}
+
+// resolvePlaces resolves the places to show: the arguments, or every saved
+// place with the default first, keeping at most limit (0 for all).
+func resolvePlaces(ctx context.Context, c *client.Client, args []string, limit int) ([]namedPlace, error) {
+	var places []namedPlace
+	if len(args) > 0 {
+		for _, arg := range args {
+			var target departureTarget
+			var err error
+			if saved, ok := lookupSavedPlace(arg); ok {
+				target, err = savedPlaceTarget(ctx, c, saved)
+			} else {
+				var place model.PRIMPlace
+				if place, err = searchPlace(ctx, c, arg, model.AllModes); err == nil {
+					target, err = placeTarget(ctx, c, place)
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+			places = append(places, namedPlace{Title: placeTitle(arg, target), Target: target})
+		}
+	} else {
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, fmt.Errorf("loading config: %w", err)
+		}
+		aliases := make([]string, 0, len(cfg.Places))
+		for alias := range cfg.Places {
+			aliases = append(aliases, alias)
+		}
+		sort.Slice(aliases, func(i, j int) bool {
+			if (aliases[i] == cfg.DefaultPlace) != (aliases[j] == cfg.DefaultPlace) {
+				return aliases[i] == cfg.DefaultPlace
+			}
+			return aliases[i] < aliases[j]
+		})
+		for _, alias := range aliases {
+			target, err := savedPlaceTarget(ctx, c, cfg.Places[alias])
+			if err != nil {
+				return nil, err
+			}
+			places = append(places, namedPlace{Title: placeTitle(alias, target), Target: target})
+		}
+	}
+
+	if len(places) == 0 {
+		return nil, fmt.Errorf("no saved places to show\nPass places (e.g. chatelet) or save some: metro places save home chatelet")
+	}
+	if limit > 0 && len(places) > limit {
+		places = places[:limit]
+	}
+	return places, nil
+}
+
+// placeTitle returns "alias · Name", or just the name when they match.
+func placeTitle(alias string, target departureTarget) string {
+	if target.Name == "" || strings.EqualFold(alias, target.Name) {
+		return alias
+	}
+	return alias + " · " + target.Name
+}
+
+type tuiView int
+
+const (
+	viewBoard tuiView = iota
+	viewDetail
+	viewPicker
+)
+
+// effect is work the event loop must start after a key or update.
+type effect int
+
+const (
+	effNone effect = iota
+	effQuit
+	effFetch
+	effSearch
+	effAddPlace
+)
+
+// dashboard holds the TUI state. It is only touched by the event loop;
+// fetches run in the background and hand back an update to apply.
+type dashboard struct {
+	c      *client.Client
+	places []namedPlace
+	mode   model.ModeSet
+
+	gen      int // bumped on each fetch, so late results are dropped
+	loading  bool
+	boards   [][]stopBoard // per place
+	statuses []modeStatus
+	updated  time.Time
+	err      error
+	stale    string
+
+	view     tuiView
+	selected int // disruption cursor
+	scroll   int // detail view offset
+
+	query     string
+	searching bool
+	results   []model.PRIMPlace
+	picked    int
+	pickErr   error
+}
+
+// update is the result of background work, applied by the event loop.
+type update func(d *dashboard) effect
+
+func newDashboard(c *client.Client, places []namedPlace, mode model.ModeSet) *dashboard {
+	return &dashboard{c: c, places: places, mode: mode}
+}
+
+// run is the event loop: it draws every second, refetches every
+// tuiRefresh and handles keys until q, Ctrl-C or ctx is cancelled.
+func (d *dashboard) run(ctx context.Context, keys <-chan string, draw func(now time.Time)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates := make(chan update)
+	start := func(eff effect) {
+		var work func(context.Context) update
+		switch eff {
+		case effFetch:
+			d.gen++
+			d.loading = true
+			work = d.fetchFn(d.gen)
+		case effSearch:
+			d.searching = true
+			work = d.searchFn(d.query)
+		case effAddPlace:
+			work = d.addPlaceFn(d.results[d.picked])
+		default:
+			return
+		}
+		go func() {
+			u := work(ctx)
+			select {
+			case updates <- u:
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	start(effFetch)
+	refresh := time.NewTicker(tuiRefresh)
+	defer refresh.Stop()
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+
+	for {
+		draw(time.Now())
+
+		eff := effNone
+		select {
+		case <-ctx.Done():
+			return nil
+		case k, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			eff = d.handleKey(k)
+		case u := <-updates:
+			eff = u(d)
+		case <-refresh.C:
+			eff = effFetch
+		case <-tick.C:
+		}
+
+		if eff == effQuit {
+			return nil
+		}
+		if eff == effFetch {
+			refresh.Reset(tuiRefresh)
+		}
+		start(eff)
+	}
+}
+
+// fetchFn fetches departures for every place and line status for the
+// current mode.
+func (d *dashboard) fetchFn(gen int) func(context.Context) update {
+	c, mode := d.c, d.mode
+	places := append([]namedPlace(nil), d.places...)
+	return func(ctx context.Context) update {
+		// One place at a time: fetchBoards already fans out over nearby
+		// stops, and nesting would exceed maxConcurrentRequests
+		boards := make([][]stopBoard, len(places))
+		errs := make([]error, len(places))
+		for i, p := range places {
+			boards[i], errs[i] = fetchBoards(ctx, c, p.Target, mode, time.Time{})
+		}
+		statuses, err := fetchStatuses(ctx, c, mode)
+		stale := staleBanner(c)
+
+		return func(d *dashboard) effect {
+			if gen != d.gen {
+				return effNone
+			}
+			d.loading = false
+			for i, e := range errs {
+				if e != nil {
+					boards[i] = []stopBoard{{Name: places[i].Target.Name, Err: e}}
+				}
+			}
+			d.boards = boards
+			d.err = err
+			if err == nil {
+				d.statuses = statuses
+			}
+			d.stale = stale
+			d.updated = time.Now()
+			d.selected = min(d.selected, max(0, len(d.disruptions())-1))
+			return effNone
+		}
+	}
+}
+
+// searchFn looks up stations and addresses for the place picker.
+func (d *dashboard) searchFn(query string) func(context.Context) update {
+	c := d.c
+	return func(ctx context.Context) update {
+		resp, err := c.SearchPlaces(ctx, query)
+		var results []model.PRIMPlace
+		if err == nil {
+			results = placeCandidates(resp.Places, model.AllModes)
+		}
+		return func(d *dashboard) effect {
+			if d.query != query {
+				return effNone
+			}
+			d.searching = false
+			d.results, d.picked, d.pickErr = results, 0, err
+			if err == nil && len(results) == 0 {
+				d.pickErr = fmt.Errorf("no stops or addresses found for \"%s\"", query)
+			}
+			return effNone
+		}
+	}
+}
+
+// addPlaceFn resolves a picked place and adds it to the board, dropping
+// the first column when the board is full.
+func (d *dashboard) addPlaceFn(place model.PRIMPlace) func(context.Context) update {
+	c := d.c
+	return func(ctx context.Context) update {
+		target, err := placeTarget(ctx, c, place)
+		return func(d *dashboard) effect {
+			if err != nil {
+				d.pickErr = err
+				return effNone
+			}
+			d.places = append(d.places, namedPlace{Title: place.Name, Target: target})
+			if len(d.places) > maxPlaces {
+				d.places = d.places[1:]
+			}
+			d.view = viewBoard
+			return effFetch
+		}
+	}
+}
+
+// disruptions lists the active disruptions for the current data.
+func (d *dashboard) disruptions() []display.LineDisruption {
+	var items []display.LineDisruption
+	for _, st := range d.statuses {
+		items = append(items, display.LineDisruptions(st.Resp, st.Mode)...)
+	}
+	return items
+}
+
+// tuiModes is the order m cycles through.
+var tuiModes = append([]string{"all"}, model.ModeNames...)
+
+func (d *dashboard) cycleMode(step int) {
+	i := 0
+	for j, name := range tuiModes {
+		if name == d.mode.String() {
+			i = j
+		}
+	}
+	i = (i + step + len(tuiModes)) % len(tuiModes)
+	d.mode, _ = model.ParseModes(tuiModes[i], nil)
+	d.selected = 0
+}
+
+// handleKey updates the state for a key press and returns the work to start.
+func (d *dashboard) handleKey(k string) effect {
+	if k == "ctrl-c" {
+		return effQuit
+	}
+	switch d.view {
+	case viewDetail:
+		switch k {
+		case "esc", "enter", "q":
+			d.view = viewBoard
+		case "down", "j":
+			d.scroll++
+		case "up", "k":
+			d.scroll = max(0, d.scroll-1)
+		}
+		return effNone
+
+	case viewPicker:
+		switch k {
+		case "esc":
+			d.view = viewBoard
+		case "enter":
+			if len(d.results) > 0 && !d.searching {
+				return effAddPlace
+			}
+			if strings.TrimSpace(d.query) != "" {
+				return effSearch
+			}
+		case "down":
+			d.picked = min(d.picked+1, max(0, len(d.results)-1))
+		case "up":
+			d.picked = max(0, d.picked-1)
+		case "backspace":
+			if d.query != "" {
+				_, size := utf8.DecodeLastRuneInString(d.query)
+				d.query = d.query[:len(d.query)-size]
+				d.results, d.pickErr = nil, nil
+			}
+		default:
+			if utf8.RuneCountInString(k) == 1 {
+				d.query += k
+				d.results, d.pickErr = nil, nil
+			}
+		}
+		return effNone
+	}
+
+	switch k {
+	case "q":
+		return effQuit
+	case "r":
+		return effFetch
+	case "m":
+		d.cycleMode(1)
+		return effFetch
+	case "M":
+		d.cycleMode(-1)
+		return effFetch
+	case "down", "j":
+		d.selected = min(d.selected+1, max(0, len(d.disruptions())-1))
+	case "up", "k":
+		d.selected = max(0, d.selected-1)
+	case "enter":
+		if len(d.disruptions()) > 0 {
+			d.view, d.scroll = viewDetail, 0
+		}
+	case "p", "/":
+		d.view = viewPicker
+		d.query, d.results, d.pickErr, d.searching = "", nil, nil, false
+	}
+	return effNone
+}
+
+// render returns exactly height lines of exactly width columns.
+func (d *dashboard) render(width, height int, now time.Time) []string {
+	header := fmt.Sprintf("\033[1mmetro\033[0m  mode \033[36m%s\033[0m", d.mode)
+	switch {
+	case d.updated.IsZero():
+		header += "  \033[2mloading…\033[0m"
+	case d.loading:
+		header += fmt.Sprintf("  \033[2mupdated %s · refreshing…\033[0m", d.updated.Format("15:04:05"))
+	default:
+		header += fmt.Sprintf("  \033[2mupdated %s\033[0m", d.updated.Format("15:04:05"))
+	}
+	if d.stale != "" {
+		header += "  " + d.stale
+	}
+	if d.err != nil {
+		header += fmt.Sprintf("  \033[31mRefresh failed: %v\033[0m", d.err)
+	}
+
+	var footer string
+	var body []string
+	bodyHeight := max(0, height-3)
+	switch d.view {
+	case viewDetail:
+		footer = "↑↓ scroll · esc back · ctrl-c quit"
+		items := d.disruptions()
+		if d.selected < len(items) {
+			body = display.DisruptionDetail(items[d.selected], width)
+		}
+		body = body[min(d.scroll, len(body)):]
+	case viewPicker:
+		footer = "type a station or address · enter search / add · ↑↓ choose · esc back"
+		body = d.renderPicker(width)
+	default:
+		footer = "m mode · ↑↓ select · enter details · p add place · r refresh · q quit"
+		body = d.renderBoard(width, bodyHeight, now)
+	}
+
+	lines := []string{display.FitWidth(header, width), display.FitWidth("", width)}
+	for i := 0; i < bodyHeight; i++ {
+		line := ""
+		if i < len(body) {
+			line = body[i]
+		}
+		lines = append(lines, display.FitWidth(line, width))
+	}
+	lines = append(lines, display.FitWidth("\033[2m"+footer+"\033[0m", width))
+	return lines[:min(len(lines), height)]
+}
+
+// renderBoard lays out the place columns above the disruption pane.
+func (d *dashboard) renderBoard(width, height int, now time.Time) []string {
+	const sep = " \033[2m│\033[0m "
+	n := len(d.places)
+	colWidth := max(10, (width-(n-1)*3)/n)
+
+	columns := make([][]string, n)
+	rows := 0
+	for i, p := range d.places {
+		var deps []model.Departure
+		var disruptions []model.Disruption
+		var errs []string
+		if i < len(d.boards) {
+			for _, b := range pruneDeparted(d.boards[i], now, p.Target.Walk) {
+				if b.Err != nil {
+					errs = append(errs, b.Err.Error())
+					continue
+				}
+				deps = append(deps, b.Resp.Departures...)
+				disruptions = append(disruptions, b.Resp.Disruptions...)
+			}
+		}
+		col := display.PlacePane(p.Title, deps, disruptions, now, colWidth)
+		for _, e := range errs {
+			col = append(col, display.FitWidth("\033[31m"+e+"\033[0m", colWidth))
+		}
+		columns[i] = col
+		rows = max(rows, len(col))
+	}
+
+	// Departures get up to 60% of the height; disruptions the rest.
+	rows = min(rows, max(3, height*3/5))
+	var lines []string
+	for r := 0; r < rows; r++ {
+		cells := make([]string, n)
+		for i, col := range columns {
+			cells[i] = display.FitWidth("", colWidth)
+			if r < len(col) {
+				cells[i] = col[r]
+			}
+		}
+		lines = append(lines, strings.Join(cells, sep))
+	}
+
+	items := d.disruptions()
+	title := fmt.Sprintf("\033[1mDisruptions\033[0m \033[2m(%d) ", len(items))
+	lines = append(lines, "", display.FitWidth(title+strings.Repeat("─", width)+"\033[0m", width))
+	lines = append(lines, display.DisruptionPane(items, d.selected, width, height-len(lines))...)
+	return lines
+}
+
+// renderPicker shows the search prompt and results.
+func (d *dashboard) renderPicker(width int) []string {
+	lines := []string{fmt.Sprintf("\033[1mAdd a place:\033[0m %s\033[7m \033[0m", d.query), ""}
+	switch {
+	case d.searching:
+		lines = append(lines, "\033[2mSearching…\033[0m")
+	case d.pickErr != nil:
+		lines = append(lines, "\033[31m"+d.pickErr.Error()+"\033[0m")
+	}
+	for i, p := range d.results {
+		cursor := "  "
+		if i == d.picked {
+			cursor = "\033[36m▸\033[0m "
+		}
+		label := p.Name
+		if lines := linesList(p); lines != "" {
+			label += " \033[2m[" + lines + "]\033[0m"
+		}
+		if p.City != "" {
+			label += " - " + p.City
+		}
+		lines = append(lines, display.FitWidth(cursor+label, width))
+	}
+	return lines
+}
+
+// writeRawFrame redraws the screen in raw mode, where "\n" doesn't return
+// the cursor to the first column.
+func writeRawFrame(w io.Writer, lines []string) {
+	io.WriteString(w, "\033[H"+strings.Join(lines, "\r\n")+"\033[J")
+}
+
+// readKeys turns raw terminal input into key names ("up", "enter", "q",
+// ...) until r fails, then closes keys.
+func readKeys(r io.Reader, keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			keys <- k
+		}
+	}
+}
+
+// parseKeys splits a chunk of raw input into key names. Arrow keys arrive
+// as escape sequences, modified ones too ("ESC [1;5A"); other sequences
+// (PgUp is "ESC [5~") are skipped whole. A lone escape is the Esc key.
+func parseKeys(b []byte) []string {
+	var keys []string
+	for len(b) > 0 {
+		switch {
+		case b[0] == 0x1b && len(b) >= 2 && b[1] == '[':
+			// CSI: parameter and intermediate bytes up to a final byte
+			end := 2
+			for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+				end++
+			}
+			if end == len(b) {
+				return keys // truncated sequence
+			}
+			if k := arrowKey(b[end]); k != "" {
+				keys = append(keys, k)
+			}
+			b = b[end+1:]
+		case b[0] == 0x1b && len(b) >= 3 && b[1] == 'O':
+			if k := arrowKey(b[2]); k != "" {
+				keys = append(keys, k)
+			}
+			b = b[3:]
+		case b[0] == 0x1b:
+			keys = append(keys, "esc")
+			b = b[1:]
+		case b[0] == 0x03:
+			keys = append(keys, "ctrl-c")
+			b = b[1:]
+		case b[0] == '\r' || b[0] == '\n':
+			keys = append(keys, "enter")
+			b = b[1:]
+		case b[0] == 0x7f || b[0] == 0x08:
+			keys = append(keys, "backspace")
+			b = b[1:]
+		case b[0] < 0x20:
+			b = b[1:]
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, string(r))
+			b = b[size:]
+		}
+	}
+	return keys
+}
+
+// arrowKey names the arrow key ending an escape sequence, or "".
+func arrowKey(final byte) string {
+	switch final {
+	case 'A':
+		return "up"
+	case 'B':
+		return "down"
+	case 'C':
+		return "right"
+	case 'D':
+		return "left"
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/config"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"q", []string{"q"}},
+		{"\x1b[A\x1b[B", []string{"up", "down"}},
+		{"\x1b", []string{"esc"}},
+		{"\r", []string{"enter"}},
+		{"\x03", []string{"ctrl-c"}},
+		{"ga\x7f", []string{"g", "a", "backspace"}},
+		{"dé", []string{"d", "é"}},
+		{"\x01", nil},
+		{"\x1bOA", []string{"up"}},
+		{"\x1b[5~q", []string{"q"}},
+		{"\x1b[3~\x1b[6~j", []string{"j"}},
+		{"\x1b[1;5Cq", []string{"right", "q"}},
+		{"\x1b[1;", nil},
+	}
+	for _, tt := range tests {
+		if got := parseKeys([]byte(tt.in)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseKeys(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// applyFetch runs a dashboard fetch synchronously.
+func applyFetch(t *testing.T, d *dashboard) {
+	t.Helper()
+	d.gen++
+	d.fetchFn(d.gen)(context.Background())(d)
+}
+
+func TestDashboard(t *testing.T) {
+	setupFakePRIM(t)
+	saveTestPlaces(t, "home", map[string]config.SavedPlace{"home": chatelet})
+
+	c, err := newClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	places, err := resolvePlaces(context.Background(), c, nil, maxPlaces)
+	if err != nil {
+		t.Fatalf("resolvePlaces: %v", err)
+	}
+	d := newDashboard(c, places, model.AllModes)
+	applyFetch(t, d)
+
+	now := time.Date(2026, 2, 25, 14, 30, 0, 0, display.Paris())
+	screen := strings.Join(d.render(120, 30, now), "\n")
+	for _, want := range []string{"home · Châtelet", "M1", "La Défense", "M14", "Disruptions", "▸"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("board missing %q:\n%s", want, screen)
+		}
+	}
+	if lines := d.render(120, 30, now); len(lines) != 30 {
+		t.Errorf("render returned %d lines, want 30", len(lines))
+	}
+
+	if eff := d.handleKey("enter"); eff != effNone || d.view != viewDetail {
+		t.Fatalf("enter: view = %v, effect = %v", d.view, eff)
+	}
+	detail := strings.Join(d.render(120, 30, now), "\n")
+	if !strings.Contains(detail, "Saint-Lazare") {
+		t.Errorf("detail missing disruption text:\n%s", detail)
+	}
+	d.handleKey("esc")
+
+	if eff := d.handleKey("m"); eff != effFetch || d.mode.String() != "metro" {
+		t.Errorf("m: mode = %s, effect = %v", d.mode.String(), eff)
+	}
+	if eff := d.handleKey("M"); eff != effFetch || d.mode.String() != "all" {
+		t.Errorf("M: mode = %s, effect = %v", d.mode.String(), eff)
+	}
+	if eff := d.handleKey("q"); eff != effQuit {
+		t.Errorf("q: effect = %v, want quit", eff)
+	}
+}
+
+func TestDashboardPicker(t *testing.T) {
+	setupFakePRIM(t)
+	c, err := newClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := newDashboard(c, []namedPlace{{Title: "chatelet", Target: departureTarget{StopID: chatelet.ID, Name: chatelet.Name}}}, model.AllModes)
+
+	d.handleKey("p")
+	for _, k := range []string{"l", "y", "x", "backspace", "o", "n"} {
+		d.handleKey(k)
+	}
+	if d.query != "lyon" {
+		t.Fatalf("query = %q, want lyon", d.query)
+	}
+	if eff := d.handleKey("enter"); eff != effSearch {
+		t.Fatalf("enter: effect = %v, want search", eff)
+	}
+	d.searchFn(d.query)(context.Background())(d)
+	if len(d.results) != 1 || d.results[0].Name != "Gare de Lyon" {
+		t.Fatalf("results = %+v", d.results)
+	}
+	if !strings.Contains(strings.Join(d.render(100, 20, time.Now()), "\n"), "Gare de Lyon") {
+		t.Error("picker does not show the result")
+	}
+
+	if eff := d.handleKey("enter"); eff != effAddPlace {
+		t.Fatalf("enter: effect = %v, want add place", eff)
+	}
+	if eff := d.addPlaceFn(d.results[d.picked])(context.Background())(d); eff != effFetch {
+		t.Errorf("add place: effect = %v, want fetch", eff)
+	}
+	if len(d.places) != 2 || d.places[1].Title != "Gare de Lyon" || d.view != viewBoard {
+		t.Errorf("places = %+v, view = %v", d.places, d.view)
+	}
+}
+
+func TestPruneDeparted(t *testing.T) {
+	at := func(hhmm string) time.Time {
+		t, _ := time.ParseInLocation("20060102 15:04", "20260225 "+hhmm, display.Paris())
+		return t
+	}
+	dep := func(hhmm string) model.Departure {
+		return model.Departure{StopDateTime: model.StopDateTime{DepartureDateTime: "20260225T" + strings.Replace(hhmm, ":", "", 1) + "00"}}
+	}
+	boards := []stopBoard{{Resp: &model.DeparturesResponse{Departures: []model.Departure{dep("14:29"), dep("14:30"), dep("14:33"), dep("14:36")}}}}
+
+	kept := func(walk time.Duration) int {
+		return len(pruneDeparted(boards, at("14:30").Add(10*time.Second), walk)[0].Resp.Departures)
+	}
+	if n := kept(0); n != 3 {
+		t.Errorf("without a walk, kept %d departures, want 3", n)
+	}
+	if n := kept(5 * time.Minute); n != 1 {
+		t.Errorf("with a 5 min walk, kept %d departures, want 1", n)
+	}
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/client"
+	"github.com/cyrilghali/metro-cli/internal/display"
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+// defaultWatchInterval is used when --watch is given without a value.
+const defaultWatchInterval = 30 * time.Second
+
+var watchInterval time.Duration
+
+// addWatchFlag registers --watch on cmd. A bare --watch refreshes every
+// defaultWatchInterval; --watch=1m sets a custom interval.
+func addWatchFlag(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&watchInterval, "watch", 0, "refresh in place until Ctrl-C (--watch or --watch=1m)")
+	cmd.Flags().Lookup("watch").NoOptDefVal = defaultWatchInterval.String()
+}
+
+// watchDepartures redraws a departure board until Ctrl-C. Departures are
+// refetched every watchInterval; countdowns are recomputed every second
+// from the last fetched times.
+func watchDepartures(ctx context.Context, c *client.Client, target departureTarget, mode model.ModeSet) error {
+	// Departures are cached for longer than the shortest interval
+	c.SetRefresh(true)
+	var boards []stopBoard
+	var stale string
+	fetch := func() error {
+		b, err := fetchBoards(ctx, c, target, mode, time.Time{})
+		if err != nil {
+			return err
+		}
+		boards, stale = b, staleBanner(c)
+		return nil
+	}
+	draw := func(w io.Writer, now time.Time) {
+		if stale != "" {
+			fmt.Fprintf(w, "%s\n\n", stale)
+		}
+		renderBoards(w, pruneDeparted(boards, now, target.Walk), mode, now)
+	}
+	return watchLoop(ctx, fetch, draw)
+}
+
+// watchDisruptions redraws the line status summary until Ctrl-C.
+func watchDisruptions(ctx context.Context, c *client.Client, mode model.ModeSet) error {
+	// Line status is cached for longer than the interval
+	c.SetRefresh(true)
+	var statuses []modeStatus
+	var stale string
+	fetch := func() error {
+		s, err := fetchStatuses(ctx, c, mode)
+		if err != nil {
+			return err
+		}
+		statuses, stale = s, staleBanner(c)
+		return nil
+	}
+	draw := func(w io.Writer, now time.Time) {
+		if stale != "" {
+			fmt.Fprintf(w, "%s\n\n", stale)
+		}
+		renderStatuses(w, statuses)
+	}
+	return watchLoop(ctx, fetch, draw)
+}
+
+// checkWatch rejects --watch combined with a structured --output format.
+func checkWatch() error {
+	if watchInterval > 0 && outputFormat.IsStructured() {
+		return fmt.Errorf("--watch cannot be combined with --output %s", outputFormat)
+	}
+	if watchInterval > 0 && watchInterval < 5*time.Second {
+		return fmt.Errorf("--watch interval must be at least 5s (got %s)", watchInterval)
+	}
+	return nil
+}
+
+// watchLoop fetches once, then redraws the screen in place every second
+// and refetches every watchInterval until ctx is cancelled (Ctrl-C).
+// A failed refresh keeps the previous data on screen with an error banner.
+func watchLoop(ctx context.Context, fetch func() error, draw func(w io.Writer, now time.Time)) error {
+	if err := fetch(); err != nil {
+		return err
+	}
+
+	quietInfo = true
+	defer func() { quietInfo = false }()
+
+	// Clear once and hide the cursor; later frames overwrite in place.
+	fmt.Print("\033[H\033[2J\033[?25l")
+	defer fmt.Print("\033[?25h\n")
+
+	updated := time.Now()
+	var fetchErr error
+
+	refresh := time.NewTicker(watchInterval)
+	defer refresh.Stop()
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+
+	for {
+		now := time.Now()
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "\033[2mEvery %s · updated %s · Ctrl-C to quit\033[0m\n", watchInterval, updated.Format("15:04:05"))
+		if fetchErr != nil {
+			fmt.Fprintf(&buf, "\033[31mRefresh failed: %v\033[0m\n", fetchErr)
+		}
+		fmt.Fprintln(&buf)
+		draw(&buf, now)
+		writeFrame(os.Stdout, buf.String())
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-refresh.C:
+			if fetchErr = fetch(); fetchErr == nil {
+				updated = time.Now()
+			} else if ctx.Err() != nil {
+				return nil
+			}
+		case <-tick.C:
+		}
+	}
+}
+
+// writeFrame redraws the screen from the top-left corner, clearing the
+// rest of each line and everything below the frame, in a single write to
+// avoid flicker.
+func writeFrame(w io.Writer, frame string) {
+	frame = strings.ReplaceAll(frame, "\n", "\033[K\n")
+	io.WriteString(w, "\033[H"+frame+"\033[J")
+}
+
+// pruneDeparted drops departures that left more than 30s before now, or
+// with a walking time those leaving before the walk is over, so boards
+// stay accurate between fetches.
+func pruneDeparted(boards []stopBoard, now time.Time, walk time.Duration) []stopBoard {
+	cutoff := now.Add(-30 * time.Second)
+	if walk > 0 {
+		cutoff = now.Add(walk)
+	}
+	pruned := make([]stopBoard, len(boards))
+	for i, b := range boards {
+		pruned[i] = b
+		if b.Resp == nil {
+			continue
+		}
+		resp := *b.Resp
+		resp.Departures = nil
+		for _, d := range b.Resp.Departures {
+			t, err := display.ParseNavitiaTime(d.StopDateTime.DepartureDateTime)
+			if err == nil && t.Before(cutoff) {
+				continue
+			}
+			resp.Departures = append(resp.Departures, d)
+		}
+		pruned[i].Resp = &resp
+	}
+	return pruned
+}
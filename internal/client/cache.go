@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache TTLs per kind of data. Realtime departures go stale quickly;
+// timetables, places and stop areas barely change.
+const (
+	ttlDepartures = 20 * time.Second
+	ttlJourneys   = time.Minute
+	ttlLines      = 5 * time.Minute
+	ttlSchedules  = time.Hour
+	ttlPlaces     = 7 * 24 * time.Hour
+)
+
+// Cache stores raw API responses on disk, one file per request URL.
+type Cache struct {
+	dir string
+	now func() time.Time // replaced in tests
+}
+
+type cacheEntry struct {
+	URL       string          `json:"url"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Body      json.RawMessage `json:"body"`
+}
+
+// NewCache returns a cache rooted at dir. The directory is created on
+// first write.
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir, now: time.Now}
+}
+
+// DefaultCacheDir returns the XDG cache directory for metro
+// (e.g. ~/.cache/metro on Linux, ~/Library/Caches/metro on macOS).
+func DefaultCacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "metro"), nil
+}
+
+// Dir returns the cache directory.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Get returns the cached body for url and when it was fetched.
+func (c *Cache) Get(url string) ([]byte, time.Time, bool) {
+	data, err := os.ReadFile(c.path(url))
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil || e.URL != url {
+		return nil, time.Time{}, false
+	}
+	return e.Body, e.FetchedAt, true
+}
+
+// Put stores body for url. Writes go through a temp file so a concurrent
+// reader never sees a partial entry.
+func (c *Cache) Put(url string, body []byte) error {
+	if !json.Valid(body) {
+		return nil
+	}
+	data, err := json.Marshal(cacheEntry{URL: url, FetchedAt: c.now(), Body: body})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(url))
+}
+
+func (c *Cache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// CacheOptions controls how cached data is used when fresh data can't be
+// fetched.
+type CacheOptions struct {
+	// Offline never touches the network and serves cached data of any age
+	// (bounded by MaxStale if set).
+	Offline bool
+	// MaxStale is how old cached data may be when served because the
+	// network failed. Zero disables the fallback.
+	MaxStale time.Duration
+}
+
+// SetCache enables the on-disk response cache.
+func (c *Client) SetCache(cache *Cache, opts CacheOptions) {
+	c.cache = cache
+	c.offline = opts.Offline
+	c.maxStale = opts.MaxStale
+}
+
+// SetRefresh makes every request go to the network, however fresh the
+// cached entry, for pollers whose interval is shorter than the cache
+// TTLs. Responses are still cached and the stale fallback still applies.
+func (c *Client) SetRefresh(on bool) {
+	c.refresh = on
+}
+
+// TakeStale reports the fetch time of the oldest stale cached response
+// served since the last call, and resets it. ok is false if all data was
+// fresh.
+func (c *Client) TakeStale() (at time.Time, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	at, c.staleAt = c.staleAt, time.Time{}
+	return at, !at.IsZero()
+}
+
+// Session returns a client sharing c's settings, cache and observers
+// that tracks stale responses on its own, so concurrent callers (such as
+// HTTP handlers) each TakeStale only what they were served.
+func (c *Client) Session() *Client {
+	return &Client{
+		apiKey:             c.apiKey,
+		baseURL:            c.baseURL,
+		http:               c.http,
+		timeout:            c.timeout,
+		maxAttempts:        c.maxAttempts,
+		sleep:              c.sleep,
+		cache:              c.cache,
+		offline:            c.offline,
+		maxStale:           c.maxStale,
+		refresh:            c.refresh,
+		observe:            c.observe,
+		observeDisruptions: c.observeDisruptions,
+	}
+}
+
+func (c *Client) markStale(at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.staleAt.IsZero() || at.Before(c.staleAt) {
+		c.staleAt = at
+	}
+}
+
+// cachedGet serves u from the cache while younger than ttl (unless
+// refreshing), otherwise fetches it. Stale entries are served (and
+// recorded for TakeStale) when offline, or when the fetch fails and the
+// entry is within MaxStale.
+func (c *Client) cachedGet(ctx context.Context, u string, ttl time.Duration) ([]byte, error) {
+	if c.cache == nil || (ttl == 0 && !c.offline) {
+		return c.doGet(ctx, u)
+	}
+
+	cached, fetchedAt, ok := c.cache.Get(u)
+	age := c.cache.now().Sub(fetchedAt)
+	if ok && age < ttl && !c.refresh {
+		return cached, nil
+	}
+
+	if c.offline {
+		if ok && (c.maxStale == 0 || age <= c.maxStale) {
+			c.markStale(fetchedAt)
+			return cached, nil
+		}
+		return nil, fmt.Errorf("offline: no cached data for this request")
+	}
+
+	body, err := c.doGet(ctx, u)
+	if err == nil {
+		_ = c.cache.Put(u, body) // best effort
+		return body, nil
+	}
+	if ok && c.maxStale > 0 && age <= c.maxStale && ctx.Err() == nil {
+		c.markStale(fetchedAt)
+		return cached, nil
+	}
+	return nil, err
+}
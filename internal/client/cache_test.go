@@ -0,0 +1,153 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/primtest"
+)
+
+func TestCachePutGet(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	if _, _, ok := cache.Get("http://x/a"); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+	if err := cache.Put("http://x/a", []byte(`{"a":1}`)); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	body, at, ok := cache.Get("http://x/a")
+	if !ok || string(body) != `{"a":1}` || time.Since(at) > time.Minute {
+		t.Errorf("Get = %q, %v, %v", body, at, ok)
+	}
+	if _, _, ok := cache.Get("http://x/b"); ok {
+		t.Error("expected miss for another URL")
+	}
+}
+
+// newCachedClient returns a test client with a cache whose clock can be
+// moved with the returned function.
+func newCachedClient(t *testing.T, opts CacheOptions) (*Client, *primtest.Server, func(time.Duration)) {
+	t.Helper()
+	c, srv := newTestClient(t)
+	noSleep(c)
+	cache := NewCache(t.TempDir())
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	c.SetCache(cache, opts)
+	return c, srv, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestCacheFreshHit(t *testing.T) {
+	c, srv, advance := newCachedClient(t, CacheOptions{})
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Lines(ctx, "", 10); err != nil {
+			t.Fatalf("Lines: %v", err)
+		}
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("expected second call served from cache, got %d requests", n)
+	}
+
+	advance(ttlLines + time.Second)
+	if _, err := c.Lines(ctx, "", 10); err != nil {
+		t.Fatalf("Lines: %v", err)
+	}
+	if n := len(srv.Requests()); n != 2 {
+		t.Errorf("expected refetch after TTL, got %d requests", n)
+	}
+	if _, stale := c.TakeStale(); stale {
+		t.Error("fresh data must not be reported stale")
+	}
+
+	c.SetRefresh(true)
+	if _, err := c.Lines(ctx, "", 10); err != nil {
+		t.Fatalf("Lines: %v", err)
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("expected refresh to skip the fresh entry, got %d requests", n)
+	}
+	if _, err := c.Session().Lines(ctx, "", 10); err != nil {
+		t.Fatalf("Lines: %v", err)
+	}
+	if n := len(srv.Requests()); n != 4 {
+		t.Errorf("expected a session to keep refreshing, got %d requests", n)
+	}
+}
+
+func TestCacheStaleFallback(t *testing.T) {
+	c, srv, advance := newCachedClient(t, CacheOptions{MaxStale: time.Hour})
+	if _, err := c.Departures(ctx, "stop_area:IDFM:71264", 10, nil, "", true); err != nil {
+		t.Fatalf("Departures: %v", err)
+	}
+
+	advance(4 * time.Minute)
+	for i := 0; i < 3; i++ {
+		srv.Inject("/v2/navitia/stop_areas/", primtest.Response{Status: 503, Body: "down"})
+	}
+	resp, err := c.Departures(ctx, "stop_area:IDFM:71264", 10, nil, "", true)
+	if err != nil {
+		t.Fatalf("expected stale fallback, got %v", err)
+	}
+	if len(resp.Departures) != 4 {
+		t.Errorf("expected cached departures, got %d", len(resp.Departures))
+	}
+	at, stale := c.TakeStale()
+	if !stale || time.Since(at) > time.Minute {
+		t.Errorf("expected stale marker at first fetch time, got %v %v", at, stale)
+	}
+	if _, stale := c.TakeStale(); stale {
+		t.Error("TakeStale must reset")
+	}
+
+	// A session keeps its stale marker to itself
+	s1, s2 := c.Session(), c.Session()
+	srv.Inject("/v2/navitia/stop_areas/", primtest.Response{Status: 503, Body: "down"})
+	srv.Inject("/v2/navitia/stop_areas/", primtest.Response{Status: 503, Body: "down"})
+	srv.Inject("/v2/navitia/stop_areas/", primtest.Response{Status: 503, Body: "down"})
+	if _, err := s1.Departures(ctx, "stop_area:IDFM:71264", 10, nil, "", true); err != nil {
+		t.Fatalf("expected stale fallback, got %v", err)
+	}
+	if _, stale := s2.TakeStale(); stale {
+		t.Error("another session must not see the stale marker")
+	}
+	if _, stale := c.TakeStale(); stale {
+		t.Error("the parent client must not see the stale marker")
+	}
+	if _, stale := s1.TakeStale(); !stale {
+		t.Error("expected the session's stale marker")
+	}
+
+	// Beyond MaxStale the error comes through.
+	advance(2 * time.Hour)
+	for i := 0; i < 3; i++ {
+		srv.Inject("/v2/navitia/stop_areas/", primtest.Response{Status: 503, Body: "down"})
+	}
+	if _, err := c.Departures(ctx, "stop_area:IDFM:71264", 10, nil, "", true); err == nil {
+		t.Error("expected error beyond max stale")
+	}
+}
+
+func TestCacheOffline(t *testing.T) {
+	c, srv, advance := newCachedClient(t, CacheOptions{})
+	if _, err := c.Lines(ctx, "", 10); err != nil {
+		t.Fatalf("Lines: %v", err)
+	}
+
+	c.offline = true
+	advance(24 * time.Hour)
+	if _, err := c.Lines(ctx, "", 10); err != nil {
+		t.Fatalf("expected cached data offline, got %v", err)
+	}
+	if _, stale := c.TakeStale(); !stale {
+		t.Error("expected stale marker offline")
+	}
+	_, err := c.Lines(ctx, "physical_mode.id=physical_mode:Bus", 10)
+	if err == nil || !strings.Contains(err.Error(), "offline") {
+		t.Errorf("expected offline error for uncached request, got %v", err)
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("offline mode must not hit the network, got %d requests", n)
+	}
+}
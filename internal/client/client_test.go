@@ -0,0 +1,467 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"github.com/cyrilghali/metro-cli/internal/primtest"
+)
+
+var ctx = context.Background()
+
+func newTestClient(t *testing.T) (*Client, *primtest.Server) {
+	t.Helper()
+	srv := primtest.NewServer()
+	t.Cleanup(srv.Close)
+	t.Setenv("PRIM_TOKEN", "test-token")
+	c, err := New(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c, srv
+}
+
+func TestNewDefaultBaseURL(t *testing.T) {
+	t.Setenv("PRIM_TOKEN", "test-token")
+	c, err := New("")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if c.baseURL != DefaultBaseURL {
+		t.Errorf("baseURL = %q, want %q", c.baseURL, DefaultBaseURL)
+	}
+}
+
+func TestNewMissingToken(t *testing.T) {
+	t.Setenv("PRIM_TOKEN", "")
+	if _, err := New(""); err == nil {
+		t.Error("expected error when PRIM_TOKEN is not set")
+	}
+}
+
+func TestDepartures(t *testing.T) {
+	c, srv := newTestClient(t)
+	resp, err := c.Departures(ctx, "stop_area:IDFM:71264", 10, []string{"physical_mode.id=physical_mode:Metro"}, "", true)
+	if err != nil {
+		t.Fatalf("Departures: %v", err)
+	}
+	if len(resp.Departures) != 4 || len(resp.Disruptions) != 1 {
+		t.Errorf("got %d departures, %d disruptions", len(resp.Departures), len(resp.Disruptions))
+	}
+	req := srv.Requests()[0]
+	for _, want := range []string{"/v2/navitia/stop_areas/stop_area:IDFM:71264/departures", "count=10", "data_freshness=realtime", "filter=physical_mode.id"} {
+		if !strings.Contains(req, want) {
+			t.Errorf("request %q missing %q", req, want)
+		}
+	}
+}
+
+func TestDeparturesSeveralModes(t *testing.T) {
+	c, srv := newTestClient(t)
+	filters := []string{model.Modes["metro"].Filter, model.Modes["rer"].Filter}
+	resp, err := c.Departures(ctx, "stop_area:IDFM:71264", 6, filters, "", true)
+	if err != nil {
+		t.Fatalf("Departures: %v", err)
+	}
+	// The fixture answers both requests: merged, truncated to count, and
+	// the shared disruption kept once
+	if len(resp.Departures) != 6 || len(resp.Disruptions) != 1 {
+		t.Errorf("got %d departures, %d disruptions", len(resp.Departures), len(resp.Disruptions))
+	}
+	for i := 1; i < len(resp.Departures); i++ {
+		if resp.Departures[i].StopDateTime.DepartureDateTime < resp.Departures[i-1].StopDateTime.DepartureDateTime {
+			t.Errorf("departures not in time order at %d", i)
+		}
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 2 || !strings.Contains(reqs[0], "Metro") || !strings.Contains(reqs[1], "RapidTransit") {
+		t.Errorf("requests = %q", reqs)
+	}
+}
+
+func TestPlacesNearbySeveralModes(t *testing.T) {
+	c, srv := newTestClient(t)
+	filters := []string{model.Modes["metro"].Filter, model.Modes["bus"].Filter}
+	resp, err := c.PlacesNearby(ctx, "2.347", "48.858", 500, filters)
+	if err != nil {
+		t.Fatalf("PlacesNearby: %v", err)
+	}
+	single, err := c.PlacesNearby(ctx, "2.347", "48.858", 500, nil)
+	if err != nil {
+		t.Fatalf("PlacesNearby: %v", err)
+	}
+	if len(resp.PlacesNearby) != len(single.PlacesNearby) {
+		t.Errorf("got %d places, want %d without duplicates", len(resp.PlacesNearby), len(single.PlacesNearby))
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("got %d requests, want 3", n)
+	}
+}
+
+func TestLineReports(t *testing.T) {
+	c, srv := newTestClient(t)
+	resp, err := c.LineReports(ctx, "physical_mode.id=physical_mode:RapidTransit", "20260220T120000", "20260227T120000", 25)
+	if err != nil {
+		t.Fatalf("LineReports: %v", err)
+	}
+	if len(resp.Lines) != 1 || resp.Lines[0].Code != "A" || len(resp.Disruptions) != 2 {
+		t.Errorf("got lines %+v, %d disruptions", resp.Lines, len(resp.Disruptions))
+	}
+	req := srv.Requests()[0]
+	for _, want := range []string{"/v2/navitia/line_reports", "since=20260220T120000", "until=20260227T120000"} {
+		if !strings.Contains(req, want) {
+			t.Errorf("request %q missing %q", req, want)
+		}
+	}
+}
+
+func TestLineRoutesAndStops(t *testing.T) {
+	c, _ := newTestClient(t)
+	routes, err := c.LineRoutes(ctx, "line:IDFM:C01743")
+	if err != nil {
+		t.Fatalf("LineRoutes: %v", err)
+	}
+	if len(routes.Routes) != 4 || routes.Routes[3].DirectionType != "backward" {
+		t.Fatalf("got routes %+v", routes.Routes)
+	}
+	sched, err := c.RouteSchedules(ctx, routes.Routes[0].ID)
+	if err != nil {
+		t.Fatalf("RouteSchedules: %v", err)
+	}
+	rows := sched.RouteSchedules[0].Table.Rows
+	if rows[0].StopPoint.StopArea == nil || rows[0].StopPoint.StopArea.Name != "Robinson" {
+		t.Errorf("first row = %+v", rows[0])
+	}
+	areas, err := c.LineStopAreas(ctx, "line:IDFM:C01743")
+	if err != nil {
+		t.Fatalf("LineStopAreas: %v", err)
+	}
+	if len(areas.StopAreas) != 11 || len(areas.StopAreas[0].Lines) == 0 {
+		t.Errorf("got %d stop areas", len(areas.StopAreas))
+	}
+}
+
+func TestDeparturesBaseSchedule(t *testing.T) {
+	c, srv := newTestClient(t)
+	if _, err := c.Departures(ctx, "stop_area:IDFM:71264", 10, nil, "20260226T074500", false); err != nil {
+		t.Fatalf("Departures: %v", err)
+	}
+	req := srv.Requests()[0]
+	for _, want := range []string{"from_datetime=20260226T074500", "data_freshness=base_schedule"} {
+		if !strings.Contains(req, want) {
+			t.Errorf("request %q missing %q", req, want)
+		}
+	}
+}
+
+func TestStopSchedulesPages(t *testing.T) {
+	c, srv := newTestClient(t)
+	resp, err := c.StopSchedules(ctx, primtest.HubStopArea, "20260225T040000", 24*time.Hour, "")
+	if err != nil {
+		t.Fatalf("StopSchedules: %v", err)
+	}
+	if len(resp.StopSchedules) != 12 {
+		t.Errorf("expected every route of the hub, got %d", len(resp.StopSchedules))
+	}
+	if reqs := srv.Requests(); len(reqs) != 1 || !strings.Contains(reqs[0], "count=100") {
+		t.Errorf("expected a single page of 100 routes, got %q", reqs)
+	}
+
+	// Pages are followed when the routes don't fit on one
+	defer func(n int) { stopSchedulesPerPage = n }(stopSchedulesPerPage)
+	stopSchedulesPerPage = 5
+	resp, err = c.StopSchedules(ctx, primtest.HubStopArea, "20260225T040000", 24*time.Hour, "")
+	if err != nil {
+		t.Fatalf("StopSchedules: %v", err)
+	}
+	if len(resp.StopSchedules) != 12 || resp.StopSchedules[11].DisplayInformations.Code != "N11" {
+		t.Errorf("expected 12 routes over 3 pages, got %d", len(resp.StopSchedules))
+	}
+	if reqs := srv.Requests()[1:]; len(reqs) != 3 || !strings.Contains(reqs[2], "start_page=2") {
+		t.Errorf("unexpected page requests %q", reqs)
+	}
+}
+
+func TestSearchPlaces(t *testing.T) {
+	c, _ := newTestClient(t)
+	resp, err := c.SearchPlaces(ctx, "gare de lyon")
+	if err != nil {
+		t.Fatalf("SearchPlaces: %v", err)
+	}
+	if len(resp.Places) != 1 || resp.Places[0].Name != "Gare de Lyon" {
+		t.Errorf("unexpected places: %+v", resp.Places)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	c, _ := newTestClient(t)
+	_, err := c.navitia(ctx, "unknown_endpoint", nil, 0)
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+// noSleep records backoff waits instead of sleeping.
+func noSleep(c *Client) *[]time.Duration {
+	var waits []time.Duration
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return &waits
+}
+
+func TestRetryOnServerError(t *testing.T) {
+	c, srv := newTestClient(t)
+	waits := noSleep(c)
+	srv.Inject("/v2/navitia/lines",
+		primtest.Response{Status: 502, Body: `{"message":"bad gateway"}`},
+		primtest.Response{Status: 503, Body: `{"message":"unavailable"}`},
+	)
+
+	resp, err := c.Lines(ctx, "physical_mode.id=physical_mode:Metro", 20)
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if len(resp.Lines) != 2 {
+		t.Errorf("expected fixture lines, got %d", len(resp.Lines))
+	}
+	if len(*waits) != 2 {
+		t.Fatalf("expected 2 backoff waits, got %v", *waits)
+	}
+	if (*waits)[0] < retryBaseWait/2 || (*waits)[0] >= retryBaseWait {
+		t.Errorf("first backoff %s outside jitter range", (*waits)[0])
+	}
+	if (*waits)[1] < retryBaseWait || (*waits)[1] >= 2*retryBaseWait {
+		t.Errorf("second backoff %s outside jitter range", (*waits)[1])
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	c, srv := newTestClient(t)
+	noSleep(c)
+	for i := 0; i < 3; i++ {
+		srv.Inject("/v2/navitia/lines", primtest.Response{Status: 500, Body: "boom"})
+	}
+
+	_, err := c.Lines(ctx, "", 10)
+	if err == nil || !strings.Contains(err.Error(), "API error 500") {
+		t.Errorf("expected API error after retries, got %v", err)
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("expected 3 attempts, got %d", n)
+	}
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+	c, srv := newTestClient(t)
+	noSleep(c)
+	srv.Inject("/v2/navitia/lines", primtest.Response{Status: 400, Body: `{"message":"bad filter"}`})
+
+	if _, err := c.Lines(ctx, "bogus", 10); err == nil {
+		t.Error("expected error for 400")
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("expected a single attempt, got %d", n)
+	}
+}
+
+func TestRateLimitShortRetryAfter(t *testing.T) {
+	c, srv := newTestClient(t)
+	waits := noSleep(c)
+	srv.Inject("/v2/navitia/lines", primtest.Response{
+		Status: 429,
+		Body:   `{"message":"Rate limit exceeded ! You reach the limit of 5 requests per 1 seconds"}`,
+		Header: map[string]string{"Retry-After": "2"},
+	})
+
+	if _, err := c.Lines(ctx, "", 10); err != nil {
+		t.Fatalf("expected success after waiting, got %v", err)
+	}
+	if len(*waits) != 1 || (*waits)[0] != 2*time.Second {
+		t.Errorf("expected a single 2s wait from Retry-After, got %v", *waits)
+	}
+}
+
+func TestRateLimitWithoutRetryAfter(t *testing.T) {
+	c, srv := newTestClient(t)
+	waits := noSleep(c)
+	// The body mentions a quota, but only Retry-After decides
+	srv.Inject("/v2/navitia/lines", primtest.Response{
+		Status: 429,
+		Body:   `{"message":"Quota exceeded ! You reach the limit of 5 requests per 1 seconds"}`,
+	})
+
+	if _, err := c.Lines(ctx, "", 10); err != nil {
+		t.Fatalf("expected success after backing off, got %v", err)
+	}
+	if len(*waits) != 1 || (*waits)[0] >= retryBaseWait {
+		t.Errorf("expected a single backoff wait, got %v", *waits)
+	}
+}
+
+func TestDailyQuotaExhausted(t *testing.T) {
+	c, srv := newTestClient(t)
+	noSleep(c)
+	srv.Inject("/v2/navitia/lines", primtest.Response{
+		Status: 429,
+		Body:   `{"message":"Quota exceeded ! You reach the limit of 20000 requests per 1 days"}`,
+		Header: map[string]string{"Retry-After": "18000"},
+	})
+
+	_, err := c.Lines(ctx, "", 10)
+	var qerr *QuotaError
+	if !errors.As(err, &qerr) {
+		t.Fatalf("expected *QuotaError, got %v", err)
+	}
+	if qerr.RetryAfter != 5*time.Hour || qerr.ResetAt.IsZero() {
+		t.Errorf("unexpected quota error: %+v", qerr)
+	}
+	if !strings.HasPrefix(err.Error(), "fetching lines: PRIM quota exhausted, resets at") {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("a long Retry-After must not be retried, got %d attempts", n)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 2, 25, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{"Wed, 25 Feb 2026 14:01:00 GMT", time.Minute},
+		{"Wed, 25 Feb 2026 13:00:00 GMT", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.in, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCanceledContext(t *testing.T) {
+	c, srv := newTestClient(t)
+	noSleep(c)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err := c.Lines(canceled, "", 10)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if n := len(srv.Requests()); n != 0 {
+		t.Errorf("expected no request to reach the server, got %d", n)
+	}
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	release := make(chan struct{})
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+	t.Setenv("PRIM_TOKEN", "test-token")
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noSleep(c)
+	c.SetTimeout(20 * time.Millisecond)
+
+	_, err = c.Lines(ctx, "", 10)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a deadline error, got %v", err)
+	}
+	if n := attempts.Load(); n != 3 {
+		t.Errorf("each attempt should get its own deadline and be retried, got %d attempts", n)
+	}
+}
+
+func TestCancelDuringBackoff(t *testing.T) {
+	c, srv := newTestClient(t)
+	callCtx, cancel := context.WithCancel(ctx)
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return sleepCtx(ctx, time.Hour)
+	}
+	srv.Inject("/v2/navitia/lines", primtest.Response{Status: 503, Body: "unavailable"})
+
+	_, err := c.Lines(callCtx, "", 10)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("expected no retry after cancel, got %d requests", n)
+	}
+}
+
+func TestObserver(t *testing.T) {
+	c, srv := newTestClient(t)
+	noSleep(c)
+	var reqs []Request
+	c.SetObserver(func(r Request) { reqs = append(reqs, r) })
+	srv.Inject("/v2/navitia/lines", primtest.Response{Status: 502, Body: `{"message":"bad gateway"}`})
+
+	if _, err := c.Lines(ctx, "", 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.Departures(ctx, "stop_area:IDFM:71264", 10, nil, "", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reqs) != 3 {
+		t.Fatalf("observed %d requests, want 3: %+v", len(reqs), reqs)
+	}
+	want := []struct {
+		endpoint string
+		status   int
+	}{{"lines", 502}, {"lines", 200}, {"departures", 200}}
+	for i, w := range want {
+		if reqs[i].Endpoint != w.endpoint || reqs[i].Status != w.status {
+			t.Errorf("request %d = %s %d, want %s %d", i, reqs[i].Endpoint, reqs[i].Status, w.endpoint, w.status)
+		}
+	}
+}
+
+func TestDisruptionObserver(t *testing.T) {
+	c, _ := newTestClient(t)
+	var lines []model.Line
+	var disruptions []model.Disruption
+	c.SetDisruptionObserver(func(l []model.Line, d []model.Disruption) {
+		lines = append(lines, l...)
+		disruptions = append(disruptions, d...)
+	})
+
+	if _, err := c.Departures(ctx, "stop_area:IDFM:71264", 10, nil, "", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(disruptions) != 1 || disruptions[0].DisruptionID != "d5b0c7a2-1111" {
+		t.Errorf("observed disruptions %+v", disruptions)
+	}
+	// One line per line serving the stop, with its mode
+	var labels []string
+	for _, l := range lines {
+		labels = append(labels, model.LineLabel(l.Code, l.CommercialMode.Name))
+	}
+	if strings.Join(labels, ",") != "M1,M14" {
+		t.Errorf("observed lines %v, want M1,M14", labels)
+	}
+}
@@ -1,25 +1,74 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"net/url"
+	"sort"
 
 	"github.com/cyrilghali/metro-cli/internal/model"
 )
 
 // Departures fetches next departures at a stop area, optionally filtered by mode.
-// If modeFilter is empty, all transport modes are returned.
-func (c *Client) Departures(stopAreaID string, count int, modeFilter string) (*model.DeparturesResponse, error) {
+// Each of modeFilters is a separate request, and the departures are merged
+// in time order, keeping count; no filter returns all transport modes.
+// fromDatetime (a Navitia local time, empty for now) starts the search
+// later; realtime selects realtime data rather than the base schedule.
+func (c *Client) Departures(ctx context.Context, stopAreaID string, count int, modeFilters []string, fromDatetime string, realtime bool) (*model.DeparturesResponse, error) {
+	if len(modeFilters) == 0 {
+		modeFilters = []string{""}
+	}
+	merged := &model.DeparturesResponse{}
+	seen := make(map[string]bool)
+	for _, filter := range modeFilters {
+		resp, err := c.departures(ctx, stopAreaID, count, filter, fromDatetime, realtime)
+		if err != nil {
+			return nil, err
+		}
+		merged.Departures = append(merged.Departures, resp.Departures...)
+		for _, d := range resp.Disruptions {
+			if !seen[d.ID] {
+				seen[d.ID] = true
+				merged.Disruptions = append(merged.Disruptions, d)
+			}
+		}
+	}
+	if len(modeFilters) > 1 {
+		// Navitia times in the same zone sort as strings
+		sort.SliceStable(merged.Departures, func(i, j int) bool {
+			return merged.Departures[i].StopDateTime.DepartureDateTime < merged.Departures[j].StopDateTime.DepartureDateTime
+		})
+		if len(merged.Departures) > count {
+			merged.Departures = merged.Departures[:count]
+		}
+	}
+	if c.observeDisruptions != nil && len(merged.Disruptions) > 0 {
+		c.observeDisruptions(departureLines(merged.Departures), merged.Disruptions)
+	}
+	return merged, nil
+}
+
+// departures makes a single departures request.
+func (c *Client) departures(ctx context.Context, stopAreaID string, count int, modeFilter, fromDatetime string, realtime bool) (*model.DeparturesResponse, error) {
 	path := fmt.Sprintf("stop_areas/%s/departures", url.PathEscape(stopAreaID))
 	params := url.Values{}
 	params.Set("count", fmt.Sprintf("%d", count))
-	params.Set("data_freshness", "realtime")
+	ttl := ttlDepartures
+	if realtime {
+		params.Set("data_freshness", "realtime")
+	} else {
+		params.Set("data_freshness", "base_schedule")
+		ttl = ttlSchedules
+	}
 	params.Set("depth", "2")
 	if modeFilter != "" {
 		params.Set("filter", modeFilter)
 	}
+	if fromDatetime != "" {
+		params.Set("from_datetime", fromDatetime)
+	}
 
-	data, err := c.navitia(path, params)
+	data, err := c.navitia(ctx, path, params, ttl)
 	if err != nil {
 		return nil, fmt.Errorf("fetching departures: %w", err)
 	}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 
@@ -9,7 +10,7 @@ import (
 
 // Lines fetches lines with their associated disruptions, optionally filtered by mode.
 // If modeFilter is empty, all lines are returned (paginated).
-func (c *Client) Lines(modeFilter string, count int) (*model.LinesResponse, error) {
+func (c *Client) Lines(ctx context.Context, modeFilter string, count int) (*model.LinesResponse, error) {
 	params := url.Values{}
 	if modeFilter != "" {
 		params.Set("filter", modeFilter)
@@ -17,9 +18,70 @@ func (c *Client) Lines(modeFilter string, count int) (*model.LinesResponse, erro
 	params.Set("count", fmt.Sprintf("%d", count))
 	params.Set("depth", "1")
 
-	data, err := c.navitia("lines", params)
+	data, err := c.navitia(ctx, "lines", params, ttlLines)
 	if err != nil {
 		return nil, fmt.Errorf("fetching lines: %w", err)
 	}
-	return decode[model.LinesResponse](data)
+	resp, err := decode[model.LinesResponse](data)
+	if err == nil && c.observeDisruptions != nil && len(resp.Disruptions) > 0 {
+		c.observeDisruptions(resp.Lines, resp.Disruptions)
+	}
+	return resp, err
+}
+
+// LineReports fetches the disrupted lines and their disruptions applying
+// between since and until (Navitia local times), planned works included.
+// The lines are returned as a LinesResponse, like Lines.
+func (c *Client) LineReports(ctx context.Context, modeFilter, since, until string, count int) (*model.LinesResponse, error) {
+	params := url.Values{}
+	if modeFilter != "" {
+		params.Set("filter", modeFilter)
+	}
+	params.Set("since", since)
+	params.Set("until", until)
+	params.Set("count", fmt.Sprintf("%d", count))
+
+	data, err := c.navitia(ctx, "line_reports", params, ttlLines)
+	if err != nil {
+		return nil, fmt.Errorf("fetching line reports: %w", err)
+	}
+	reports, err := decode[model.LineReportsResponse](data)
+	if err != nil {
+		return nil, err
+	}
+	resp := &model.LinesResponse{Disruptions: reports.Disruptions, Pagination: reports.Pagination}
+	for _, r := range reports.LineReports {
+		resp.Lines = append(resp.Lines, r.Line)
+	}
+	return resp, nil
+}
+
+// SetDisruptionObserver calls fn with the disruptions of every Lines and
+// Departures response, cached ones included, and the lines they were
+// returned with (e.g. to record disruption history). fn may be called
+// concurrently.
+func (c *Client) SetDisruptionObserver(fn func([]model.Line, []model.Disruption)) {
+	c.observeDisruptions = fn
+}
+
+// departureLines returns the lines serving deps, with their commercial
+// mode taken from the display informations when the route lacks it.
+func departureLines(deps []model.Departure) []model.Line {
+	var lines []model.Line
+	seen := make(map[string]bool)
+	for _, d := range deps {
+		if d.Route.Line == nil || seen[d.Route.Line.ID] {
+			continue
+		}
+		seen[d.Route.Line.ID] = true
+		line := *d.Route.Line
+		if line.CommercialMode == nil {
+			line.CommercialMode = &model.Mode{Name: d.DisplayInformations.CommercialMode}
+		}
+		if line.Code == "" {
+			line.Code = d.DisplayInformations.Code
+		}
+		lines = append(lines, line)
+	}
+	return lines
 }
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+// Journeys plans itineraries between two places. from and to are Navitia
+// place IDs (e.g. "stop_area:IDFM:71264") or "lon;lat" coordinates.
+// datetime is a Navitia local time ("20260225T143000"); empty means now.
+// If arriveBy is true, datetime is the latest arrival instead of the
+// earliest departure.
+func (c *Client) Journeys(ctx context.Context, from, to, datetime string, arriveBy bool, count int) (*model.JourneysResponse, error) {
+	params := url.Values{}
+	params.Set("from", from)
+	params.Set("to", to)
+	if datetime != "" {
+		params.Set("datetime", datetime)
+		if arriveBy {
+			params.Set("datetime_represents", "arrival")
+		} else {
+			params.Set("datetime_represents", "departure")
+		}
+	}
+	params.Set("count", fmt.Sprintf("%d", count))
+	params.Set("depth", "1")
+
+	data, err := c.navitia(ctx, "journeys", params, ttlJourneys)
+	if err != nil {
+		return nil, fmt.Errorf("fetching journeys: %w", err)
+	}
+	return decode[model.JourneysResponse](data)
+}
@@ -1,20 +1,23 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"net/url"
+	"sort"
+	"strconv"
 
 	"github.com/cyrilghali/metro-cli/internal/model"
 )
 
 // SearchPlaces searches for places (stops, addresses) matching a query string.
 // Uses the PRIM custom /marketplace/places endpoint (returns line info).
-func (c *Client) SearchPlaces(query string) (*model.PRIMPlacesResponse, error) {
+func (c *Client) SearchPlaces(ctx context.Context, query string) (*model.PRIMPlacesResponse, error) {
 	params := url.Values{}
 	params.Set("q", query)
 	params.Set("coverage", "fr-idf")
 
-	data, err := c.prim("places", params)
+	data, err := c.prim(ctx, "places", params, ttlPlaces)
 	if err != nil {
 		return nil, fmt.Errorf("searching places: %w", err)
 	}
@@ -23,14 +26,14 @@ func (c *Client) SearchPlaces(query string) (*model.PRIMPlacesResponse, error) {
 
 // NavitiaPlaces searches using Navitia's places endpoint (returns WGS84 coords).
 // Used for addresses where we need proper lon/lat for nearby lookups.
-func (c *Client) NavitiaPlaces(query string) (*model.NavitiaPlacesResponse, error) {
+func (c *Client) NavitiaPlaces(ctx context.Context, query string) (*model.NavitiaPlacesResponse, error) {
 	params := url.Values{}
 	params.Set("q", query)
 	params.Add("type[]", "address")
 	params.Add("type[]", "stop_area")
 	params.Set("count", "5")
 
-	data, err := c.navitia("places", params)
+	data, err := c.navitia(ctx, "places", params, ttlPlaces)
 	if err != nil {
 		return nil, fmt.Errorf("navitia places: %w", err)
 	}
@@ -38,8 +41,38 @@ func (c *Client) NavitiaPlaces(query string) (*model.NavitiaPlacesResponse, erro
 }
 
 // PlacesNearby finds stop points near given coordinates, optionally filtered by mode.
-// If modeFilter is empty, all stop points are returned.
-func (c *Client) PlacesNearby(lon, lat string, radius int, modeFilter string) (*model.PlacesNearbyResponse, error) {
+// Each of modeFilters is a separate request, and the places are merged
+// nearest first; no filter returns all stop points.
+func (c *Client) PlacesNearby(ctx context.Context, lon, lat string, radius int, modeFilters []string) (*model.PlacesNearbyResponse, error) {
+	if len(modeFilters) == 0 {
+		modeFilters = []string{""}
+	}
+	merged := &model.PlacesNearbyResponse{}
+	seen := make(map[string]bool)
+	for _, filter := range modeFilters {
+		resp, err := c.placesNearby(ctx, lon, lat, radius, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range resp.PlacesNearby {
+			if !seen[p.ID] {
+				seen[p.ID] = true
+				merged.PlacesNearby = append(merged.PlacesNearby, p)
+			}
+		}
+	}
+	if len(modeFilters) > 1 {
+		sort.SliceStable(merged.PlacesNearby, func(i, j int) bool {
+			a, _ := strconv.Atoi(merged.PlacesNearby[i].Distance)
+			b, _ := strconv.Atoi(merged.PlacesNearby[j].Distance)
+			return a < b
+		})
+	}
+	return merged, nil
+}
+
+// placesNearby makes a single places_nearby request.
+func (c *Client) placesNearby(ctx context.Context, lon, lat string, radius int, modeFilter string) (*model.PlacesNearbyResponse, error) {
 	path := fmt.Sprintf("coords/%s;%s/places_nearby", url.PathEscape(lon), url.PathEscape(lat))
 	params := url.Values{}
 	params.Set("distance", fmt.Sprintf("%d", radius))
@@ -50,7 +83,7 @@ func (c *Client) PlacesNearby(lon, lat string, radius int, modeFilter string) (*
 	params.Set("count", "30")
 	params.Set("depth", "2")
 
-	data, err := c.navitia(path, params)
+	data, err := c.navitia(ctx, path, params, ttlPlaces)
 	if err != nil {
 		return nil, fmt.Errorf("places nearby: %w", err)
 	}
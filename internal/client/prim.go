@@ -1,84 +1,192 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
+	"strings"
+	"sync"
 	"time"
-)
 
-const (
-	primBase    = "https://prim.iledefrance-mobilites.fr/marketplace"
-	navitiaBase = primBase + "/v2/navitia"
+	"github.com/cyrilghali/metro-cli/internal/model"
 )
 
+// DefaultBaseURL is the PRIM marketplace root used when no override is set.
+const DefaultBaseURL = "https://prim.iledefrance-mobilites.fr/marketplace"
+
+// DefaultTimeout is the deadline of each request attempt, see SetTimeout.
+const DefaultTimeout = 15 * time.Second
+
 type Client struct {
-	apiKey string
-	http   *http.Client
+	apiKey      string
+	baseURL     string
+	http        *http.Client
+	timeout     time.Duration // per attempt
+	maxAttempts int
+	sleep       func(context.Context, time.Duration) error // replaced in tests
+
+	cache    *Cache
+	offline  bool
+	maxStale time.Duration
+	refresh  bool // skip fresh cache hits, see SetRefresh
+
+	mu      sync.Mutex
+	staleAt time.Time // oldest stale cache entry served, see TakeStale
+
+	observe            func(Request)
+	observeDisruptions func([]model.Line, []model.Disruption)
+}
+
+// Request describes one HTTP attempt to the API, for metrics.
+type Request struct {
+	Endpoint string // last path element, e.g. "departures" or "lines"
+	Status   int    // HTTP status, 0 when the request failed
+	Duration time.Duration
+}
+
+// SetObserver calls fn after every HTTP attempt, retries included.
+// Responses served from the cache are not requests.
+func (c *Client) SetObserver(fn func(Request)) {
+	c.observe = fn
+}
+
+// SetTimeout sets the deadline of each request attempt, derived from
+// the caller's context; retries get a fresh one. Zero restores
+// DefaultTimeout.
+func (c *Client) SetTimeout(d time.Duration) {
+	if d <= 0 {
+		d = DefaultTimeout
+	}
+	c.timeout = d
 }
 
-func New() (*Client, error) {
+// New creates a client for the PRIM API at baseURL (DefaultBaseURL if empty).
+// The Navitia v2 endpoint is expected under baseURL + "/v2/navitia".
+func New(baseURL string) (*Client, error) {
 	key := os.Getenv("PRIM_TOKEN")
 	if key == "" {
 		return nil, fmt.Errorf("PRIM_TOKEN not set\n\nGet a free token at https://prim.iledefrance-mobilites.fr\nThen set it:\n  export PRIM_TOKEN=<your-token>\n\nTo make it permanent, add the line above to your shell profile (~/.bashrc, ~/.zshrc, etc.)")
 	}
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
 
 	return &Client{
-		apiKey: key,
-		http: &http.Client{
-			Timeout: 15 * time.Second,
-		},
+		apiKey:      key,
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		http:        &http.Client{},
+		timeout:     DefaultTimeout,
+		maxAttempts: 3,
+		sleep:       sleepCtx,
 	}, nil
 }
 
 // navitia makes a GET request to the Navitia v2 endpoint (no /coverage/ prefix).
-func (c *Client) navitia(path string, params url.Values) ([]byte, error) {
-	u := navitiaBase + "/" + path
+// Responses are cached for ttl when a cache is set (0 disables caching).
+func (c *Client) navitia(ctx context.Context, path string, params url.Values, ttl time.Duration) ([]byte, error) {
+	u := c.baseURL + "/v2/navitia/" + path
 	if len(params) > 0 {
 		u += "?" + params.Encode()
 	}
-	return c.doGet(u)
+	return c.cachedGet(ctx, u, ttl)
 }
 
 // prim makes a GET request to the PRIM marketplace root endpoint.
-func (c *Client) prim(path string, params url.Values) ([]byte, error) {
-	u := primBase + "/" + path
+func (c *Client) prim(ctx context.Context, path string, params url.Values, ttl time.Duration) ([]byte, error) {
+	u := c.baseURL + "/" + path
 	if len(params) > 0 {
 		u += "?" + params.Encode()
 	}
-	return c.doGet(u)
+	return c.cachedGet(ctx, u, ttl)
 }
 
-func (c *Client) doGet(u string) ([]byte, error) {
-	req, err := http.NewRequest("GET", u, nil)
+// doGet performs a GET with bounded retries. 5xx responses and timeouts
+// are retried with jittered exponential backoff; 429 rate limiting waits
+// out a short Retry-After (or backs off without one), and a longer one
+// returns a *QuotaError.
+// Cancelling ctx aborts the request in flight and any backoff wait.
+func (c *Client) doGet(ctx context.Context, u string) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		resp, body, err := c.get(ctx, u)
+		if c.observe != nil {
+			r := Request{Endpoint: endpoint(u), Duration: time.Since(start)}
+			if err == nil {
+				r.Status = resp.StatusCode
+			}
+			c.observe(r)
+		}
+		last := attempt >= c.maxAttempts
+
+		var wait time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil || !isTimeout(err) || last {
+				return nil, err
+			}
+			wait = backoff(attempt)
+		case resp.StatusCode == http.StatusOK:
+			return body, nil
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, fmt.Errorf("not found (the API returned no results)")
+		case resp.StatusCode == http.StatusTooManyRequests:
+			qerr := newQuotaError(resp, time.Now())
+			if qerr.RetryAfter > maxRetryAfter || last {
+				return nil, qerr
+			}
+			wait = qerr.RetryAfter
+			if wait == 0 {
+				wait = backoff(attempt)
+			}
+		case resp.StatusCode >= 500 && !last:
+			wait = backoff(attempt)
+		default:
+			return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+		}
+		if err := c.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// endpoint returns the last path element of u, which names the API
+// endpoint without object IDs.
+func endpoint(u string) string {
+	parsed, err := url.Parse(u)
 	if err != nil {
-		return nil, err
+		return "unknown"
+	}
+	return path.Base(parsed.Path)
+}
+
+// get performs a single GET within the client timeout and reads the
+// whole body.
+func (c *Client) get(ctx context.Context, u string) (*http.Response, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, nil, err
 	}
 	req.Header.Set("apikey", c.apiKey)
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("not found (the API returned no results)")
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+		return nil, nil, fmt.Errorf("reading response: %w", err)
 	}
-
-	return body, nil
+	return resp, body, nil
 }
 
 func decode[T any](data []byte) (*T, error) {
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// retryBaseWait is the backoff before the first retry; it doubles each attempt.
+	retryBaseWait = 500 * time.Millisecond
+	// maxRetryAfter is the longest Retry-After we wait out before giving up
+	// with a QuotaError instead. Longer waits mean the quota, not the
+	// per-second rate limit, is exhausted.
+	maxRetryAfter = 10 * time.Second
+)
+
+// QuotaError is returned when PRIM rejects a request with 429 Too Many
+// Requests and retrying is pointless: the Retry-After wait is too long
+// (the daily quota is exhausted), or the retries ran out.
+type QuotaError struct {
+	RetryAfter time.Duration // from Retry-After, zero if not sent
+	ResetAt    time.Time     // now + RetryAfter, zero if unknown
+	StatusCode int
+}
+
+func (e *QuotaError) Error() string {
+	if e.RetryAfter > maxRetryAfter {
+		return fmt.Sprintf("PRIM quota exhausted, resets at %s", e.ResetAt.Local().Format("Mon 15:04"))
+	}
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("PRIM rate limit exceeded, retry in %s", e.RetryAfter.Round(time.Second))
+	}
+	return "PRIM rate limit exceeded, retry in a moment"
+}
+
+// newQuotaError builds a QuotaError from a 429 response's Retry-After.
+func newQuotaError(resp *http.Response, now time.Time) *QuotaError {
+	e := &QuotaError{
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), now),
+		StatusCode: resp.StatusCode,
+	}
+	if e.RetryAfter > 0 {
+		e.ResetAt = now.Add(e.RetryAfter)
+	}
+	return e
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date. It returns zero if the header is missing or invalid.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// backoff returns a jittered exponential delay for the given attempt
+// (1-based): between half and all of retryBaseWait * 2^(attempt-1).
+func backoff(attempt int) time.Duration {
+	d := retryBaseWait << (attempt - 1)
+	return d/2 + rand.N(d/2)
+}
+
+// sleepCtx waits for d, or returns early with ctx's error if ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// isTimeout reports whether err is a network timeout worth retrying.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+// LineRoutes fetches the routes of a line: one per direction and branch,
+// short runs included.
+func (c *Client) LineRoutes(ctx context.Context, lineID string) (*model.RoutesResponse, error) {
+	path := fmt.Sprintf("lines/%s/routes", url.PathEscape(lineID))
+	params := url.Values{}
+	params.Set("count", "50")
+	params.Set("depth", "1")
+
+	data, err := c.navitia(ctx, path, params, ttlSchedules)
+	if err != nil {
+		return nil, fmt.Errorf("fetching routes: %w", err)
+	}
+	return decode[model.RoutesResponse](data)
+}
+
+// RouteSchedules fetches the timetable of a route, keeping a single trip:
+// only the rows, the stops in running order, are of interest.
+func (c *Client) RouteSchedules(ctx context.Context, routeID string) (*model.RouteSchedulesResponse, error) {
+	path := fmt.Sprintf("routes/%s/route_schedules", url.PathEscape(routeID))
+	params := url.Values{}
+	params.Set("items_per_schedule", "1")
+	params.Set("data_freshness", "base_schedule")
+	params.Set("depth", "1")
+
+	data, err := c.navitia(ctx, path, params, ttlSchedules)
+	if err != nil {
+		return nil, fmt.Errorf("fetching route stops: %w", err)
+	}
+	return decode[model.RouteSchedulesResponse](data)
+}
+
+// LineStopAreas fetches the stop areas of a line with the lines serving
+// each of them.
+func (c *Client) LineStopAreas(ctx context.Context, lineID string) (*model.StopAreasResponse, error) {
+	path := fmt.Sprintf("lines/%s/stop_areas", url.PathEscape(lineID))
+	params := url.Values{}
+	params.Set("count", "200")
+	params.Set("depth", "2")
+
+	data, err := c.navitia(ctx, path, params, ttlSchedules)
+	if err != nil {
+		return nil, fmt.Errorf("fetching stops: %w", err)
+	}
+	return decode[model.StopAreasResponse](data)
+}
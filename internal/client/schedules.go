@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+// stopSchedulesPerPage is how many routes a stop_schedules page holds
+// (Navitia pages 10 by default). A var so tests can page smaller.
+var stopSchedulesPerPage = 100
+
+// maxStopSchedulesPages bounds the pages fetched for one stop area.
+const maxStopSchedulesPages = 10
+
+// StopSchedules fetches the scheduled timetable of every route at a stop
+// area, from fromDatetime (a Navitia local time) for duration. filter is
+// a Navitia filter such as model.LineFilter; empty means all lines.
+// Results are paged by route, so pages are fetched until all routes are in.
+func (c *Client) StopSchedules(ctx context.Context, stopAreaID, fromDatetime string, duration time.Duration, filter string) (*model.StopSchedulesResponse, error) {
+	path := fmt.Sprintf("stop_areas/%s/stop_schedules", url.PathEscape(stopAreaID))
+	params := url.Values{}
+	params.Set("from_datetime", fromDatetime)
+	params.Set("duration", fmt.Sprintf("%d", int(duration.Seconds())))
+	params.Set("data_freshness", "base_schedule")
+	params.Set("depth", "2")
+	params.Set("count", fmt.Sprintf("%d", stopSchedulesPerPage))
+	if filter != "" {
+		params.Set("filter", filter)
+	}
+
+	all := &model.StopSchedulesResponse{}
+	for page := 0; page < maxStopSchedulesPages; page++ {
+		if page > 0 {
+			params.Set("start_page", fmt.Sprintf("%d", page))
+		}
+		data, err := c.navitia(ctx, path, params, ttlSchedules)
+		if err != nil {
+			return nil, fmt.Errorf("fetching timetable: %w", err)
+		}
+		resp, err := decode[model.StopSchedulesResponse](data)
+		if err != nil {
+			return nil, err
+		}
+		all.StopSchedules = append(all.StopSchedules, resp.StopSchedules...)
+		// Each page repeats the disruptions it refers to
+		for _, d := range resp.Disruptions {
+			if !slices.ContainsFunc(all.Disruptions, func(o model.Disruption) bool { return o.ID == d.ID }) {
+				all.Disruptions = append(all.Disruptions, d)
+			}
+		}
+		all.Pagination = resp.Pagination
+		p := resp.Pagination
+		if p.ItemsOnPage == 0 || p.ItemsPerPage == 0 || (p.StartPage+1)*p.ItemsPerPage >= p.TotalResult {
+			break
+		}
+	}
+	return all, nil
+}
+
+// Arrivals fetches up to count scheduled arrivals at a stop area, from
+// fromDatetime for duration. Unlike departures, this includes trains
+// ending their run at the stop.
+func (c *Client) Arrivals(ctx context.Context, stopAreaID, fromDatetime string, duration time.Duration, count int, filter string) (*model.ArrivalsResponse, error) {
+	path := fmt.Sprintf("stop_areas/%s/arrivals", url.PathEscape(stopAreaID))
+	params := url.Values{}
+	params.Set("from_datetime", fromDatetime)
+	params.Set("duration", fmt.Sprintf("%d", int(duration.Seconds())))
+	params.Set("count", fmt.Sprintf("%d", count))
+	params.Set("data_freshness", "base_schedule")
+	params.Set("depth", "2")
+	if filter != "" {
+		params.Set("filter", filter)
+	}
+
+	data, err := c.navitia(ctx, path, params, ttlSchedules)
+	if err != nil {
+		return nil, fmt.Errorf("fetching arrivals: %w", err)
+	}
+	return decode[model.ArrivalsResponse](data)
+}
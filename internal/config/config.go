@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 
 	"github.com/BurntSushi/toml"
 )
@@ -14,11 +15,31 @@ type SavedPlace struct {
 	City string  `toml:"city"`
 	Lat  float64 `toml:"lat,omitempty"`
 	Lon  float64 `toml:"lon,omitempty"`
+
+	// Departure settings applied whenever the place is used
+	Modes       []string `toml:"modes,omitempty"`        // e.g. ["metro", "rer"]; none means all
+	Lines       []string `toml:"lines,omitempty"`        // e.g. ["RER A", "M1"]
+	Direction   string   `toml:"direction,omitempty"`    // fuzzy, e.g. "marne"
+	Radius      int      `toml:"radius,omitempty"`       // nearby stops search, meters; 0 for the default
+	WalkMinutes int      `toml:"walk_minutes,omitempty"` // to the platform; sooner departures are hidden
+}
+
+// CommuteRule picks the place "metro d" and "metro commute" use during a
+// time window, and optionally where the commute goes.
+type CommuteRule struct {
+	Days        string `toml:"days"`                  // "weekdays", "weekends", "daily" or e.g. "mon,wed,fri"
+	From        string `toml:"from"`                  // "06:00", Paris time
+	To          string `toml:"to"`                    // "11:00"; before From spans midnight
+	Place       string `toml:"place"`                 // saved place alias
+	Destination string `toml:"destination,omitempty"` // saved place alias, station or address
 }
 
 type Config struct {
 	DefaultPlace string                `toml:"default_place"`
+	BaseURL      string                `toml:"base_url,omitempty"` // PRIM API root; PRIM_BASE_URL overrides
+	History      bool                  `toml:"history,omitempty"`  // record observed disruptions, see DataDir
 	Places       map[string]SavedPlace `toml:"places"`
+	Commute      []CommuteRule         `toml:"commute,omitempty"` // first matching rule wins over DefaultPlace
 }
 
 func Path() string {
@@ -29,6 +50,27 @@ func Path() string {
 	return filepath.Join(home, ".metro.toml")
 }
 
+// DataDir returns where metro keeps recorded data: $XDG_DATA_HOME/metro,
+// ~/.local/share/metro on Linux, or the user config directory elsewhere.
+func DataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "metro"), nil
+	}
+	switch runtime.GOOS {
+	case "darwin", "windows", "ios", "plan9":
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(base, "metro"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "metro"), nil
+}
+
 func Load() (*Config, error) {
 	cfg := &Config{}
 	path := Path()
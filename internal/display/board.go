@@ -0,0 +1,153 @@
+package display
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+// The kiosk departure board (metro board) is a single server-rendered
+// page: inline CSS, no JS, refreshed by a meta tag.
+
+//go:embed board.html
+var boardHTML string
+
+var boardTemplate = template.Must(template.New("board").Parse(boardHTML))
+
+// BoardPage is the data of one kiosk board.
+type BoardPage struct {
+	Places  []BoardPlace
+	Refresh time.Duration // 0 for a static page
+	Stale   string        // plain text warning when showing cached data
+}
+
+// BoardPlace is one place's column: its departures and the disruptions
+// returned with them.
+type BoardPlace struct {
+	Title       string
+	Departures  []model.Departure
+	Disruptions []model.Disruption
+	Errors      []string
+}
+
+type boardView struct {
+	Clock   string
+	Refresh int
+	Stale   string
+	Places  []boardPlaceView
+}
+
+type boardPlaceView struct {
+	Title       string
+	Rows        []boardRow
+	Disruptions []boardDisruption
+	Errors      []string
+}
+
+type boardRow struct {
+	Label     string
+	Round     bool // metro lines get a round badge, like platform displays
+	Color     string
+	TextColor string
+	Direction string
+	Disrupted bool
+	Times     []boardTime
+}
+
+type boardTime struct {
+	Minutes   string // "2", or "" when departing now
+	Delay     string // "+2", "-1" or ""
+	Scheduled bool
+}
+
+type boardDisruption struct {
+	Label    string
+	Severity string
+	Level    string // CSS class: "severe", "warn" or "info"
+	Message  string
+}
+
+var hexColor = regexp.MustCompile(`^[0-9A-Fa-f]{6}$`)
+
+// BoardHTML writes a board page. Countdowns are relative to now.
+func BoardHTML(w io.Writer, page BoardPage, now time.Time) error {
+	v := boardView{
+		Clock:   now.In(paris).Format("15:04"),
+		Refresh: int(page.Refresh.Seconds()),
+		Stale:   page.Stale,
+	}
+	for _, p := range page.Places {
+		v.Places = append(v.Places, boardPlace(p, now))
+	}
+	return boardTemplate.Execute(w, v)
+}
+
+func boardPlace(p BoardPlace, now time.Time) boardPlaceView {
+	pv := boardPlaceView{Title: p.Title, Errors: p.Errors}
+	worst := worstEffectByLine(p.Disruptions)
+
+	for _, g := range GroupDepartures(p.Departures, maxTimesPerGroup) {
+		row := boardRow{
+			Label:     model.LineLabel(g.Code, g.CommercialMode),
+			Round:     modeName(g.CommercialMode) == "metro",
+			Color:     "555555",
+			TextColor: "FFFFFF",
+			Direction: g.Direction,
+		}
+		if len(g.Departures) > 0 {
+			first := g.Departures[0]
+			color, text := first.DisplayInformations.Color, first.DisplayInformations.TextColor
+			if color == "" && first.Route.Line != nil {
+				color, text = first.Route.Line.Color, first.Route.Line.TextColor
+			}
+			if hexColor.MatchString(color) {
+				row.Color = color
+				if hexColor.MatchString(text) {
+					row.TextColor = text
+				}
+			}
+			if first.Route.Line != nil {
+				if effect, ok := worst[first.Route.Line.ID]; ok {
+					row.Disrupted = effectRank(effect) >= effectRank("MODIFIED_SERVICE")
+				}
+			}
+		}
+		for _, d := range g.Departures {
+			t, _ := ParseNavitiaTime(d.StopDateTime.DepartureDateTime)
+			delay, realtime := DepartureDelay(d.StopDateTime)
+			bt := boardTime{Scheduled: !realtime}
+			if mins := minutesFrom(t, now); mins > 0 {
+				bt.Minutes = strconv.Itoa(mins)
+			}
+			if delay > 0 {
+				bt.Delay = "+" + strconv.Itoa(delay)
+			} else if delay < 0 {
+				bt.Delay = strconv.Itoa(delay)
+			}
+			row.Times = append(row.Times, bt)
+		}
+		pv.Rows = append(pv.Rows, row)
+	}
+
+	for _, m := range DepartureDisruptions(p.Departures, p.Disruptions) {
+		level := "info"
+		switch rank := effectRank(m.Disruption.Severity.Effect); {
+		case rank >= effectRank("REDUCED_SERVICE"):
+			level = "severe"
+		case rank >= effectRank("MODIFIED_SERVICE"):
+			level = "warn"
+		}
+		pv.Disruptions = append(pv.Disruptions, boardDisruption{
+			Label:    m.Label,
+			Severity: SeverityLabel(m.Disruption.Severity),
+			Level:    level,
+			Message:  DisruptionMessage(*m.Disruption),
+		})
+	}
+	return pv
+}
@@ -0,0 +1,69 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+func TestBoardHTML(t *testing.T) {
+	deps, disruptions := testDepartures()
+	deps[0].DisplayInformations.Color = "FFCD00"
+	deps[0].DisplayInformations.TextColor = "000000"
+	disruptions[0].Messages = []model.Message{{Text: "Trafic <b>perturbé</b>"}}
+
+	// A color that isn't hex must not reach the style attribute
+	evil := deps[0]
+	evil.DisplayInformations.Code = "14"
+	evil.DisplayInformations.Direction = "<script>x</script>"
+	evil.DisplayInformations.Color = "red;background:url(x)"
+
+	var buf bytes.Buffer
+	page := BoardPage{
+		Places:  []BoardPlace{{Title: "home", Departures: append(deps, evil), Disruptions: disruptions}},
+		Refresh: 30 * time.Second,
+		Stale:   "offline: showing data from 4 min ago",
+	}
+	if err := BoardHTML(&buf, page, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+
+	for _, want := range []string{
+		`<meta http-equiv="refresh" content="30">`,
+		`class="badge round" style="background:#FFCD00;color:#000000">M1<`,
+		`style="background:#555555;color:#FFFFFF">M14<`,
+		`<span class="delay">&#43;3</span>`, // "+3", escaped
+		`class="disruption severe"`,
+		`class="disruption warn"`,
+		"Trafic perturbé",
+		"offline: showing data from 4 min ago",
+		"&lt;script&gt;",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("board missing %q:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<script>") || strings.Contains(html, "url(x)") {
+		t.Errorf("board contains unescaped input:\n%s", html)
+	}
+}
+
+func TestBoardHTMLStatic(t *testing.T) {
+	var buf bytes.Buffer
+	if err := BoardHTML(&buf, BoardPage{Places: []BoardPlace{{Title: "work", Errors: []string{"fetching departures: timeout"}}}}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	if strings.Contains(html, "http-equiv") {
+		t.Error("static board should not refresh itself")
+	}
+	for _, want := range []string{"No upcoming departures", "fetching departures: timeout"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("board missing %q", want)
+		}
+	}
+}
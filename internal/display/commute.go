@@ -0,0 +1,59 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+// JourneyLines returns the labels of the lines a journey rides, in order.
+func JourneyLines(j model.Journey) []string {
+	var labels []string
+	for _, s := range j.Sections {
+		if sectionKind(s) != "transit" || s.DisplayInformations == nil {
+			continue
+		}
+		label := model.LineLabel(s.DisplayInformations.Code, s.DisplayInformations.CommercialMode)
+		if !slices.Contains(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// JourneySummary prints a journey on one line: times, duration, the
+// lines ridden and the number of transfers.
+func JourneySummary(w io.Writer, j model.Journey) {
+	fmt.Fprintf(w, "%s%s → %s%s  %s%s%s  %s  %s%s%s\n",
+		bold, clock(j.DepartureDateTime), clock(j.ArrivalDateTime), reset,
+		cyan, formatDuration(j.Duration), reset,
+		strings.Join(JourneyLines(j), " → "),
+		dim, transfersText(j.NbTransfers), reset)
+}
+
+// LineStatuses prints line status records as a table like
+// DisruptionsSummary: "OK", or one row per active disruption.
+func LineStatuses(w io.Writer, recs []LineStatusRecord) {
+	if len(recs) == 0 {
+		fmt.Fprintf(w, "  %s(no lines)%s\n", dim, reset)
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, r := range recs {
+		label := fmt.Sprintf("%s%-6s%s", bold, r.Line, reset)
+		if i > 0 && recs[i-1].Line == r.Line {
+			label = "      "
+		}
+		if r.Status == "ok" {
+			fmt.Fprintf(tw, "  %s\t%sOK%s\t\n", label, green, reset)
+			continue
+		}
+		status := formatSeverity(model.Severity{Effect: r.Severity, Name: r.SeverityName})
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", label, status, truncate(r.Message, 70))
+	}
+	tw.Flush()
+}
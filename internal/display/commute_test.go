@@ -0,0 +1,54 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+func TestJourneyLines(t *testing.T) {
+	transit := func(code, mode string) model.Section {
+		return model.Section{Type: "public_transport", DisplayInformations: &model.DisplayInfo{Code: code, CommercialMode: mode}}
+	}
+	j := model.Journey{Sections: []model.Section{
+		{Type: "street_network"},
+		transit("1", "Métro"),
+		{Type: "transfer"},
+		transit("A", "RER"),
+		transit("1", "Métro"),
+	}}
+	if got := strings.Join(JourneyLines(j), ","); got != "M1,RER A" {
+		t.Errorf("JourneyLines() = %q, want M1,RER A", got)
+	}
+}
+
+func TestLineStatuses(t *testing.T) {
+	var buf bytes.Buffer
+	LineStatuses(&buf, []LineStatusRecord{
+		{Line: "M1", Status: "ok"},
+		{Line: "M14", Status: "disrupted", Severity: "SIGNIFICANT_DELAYS", Message: "Trafic perturbé"},
+		{Line: "M14", Status: "disrupted", Severity: "REDUCED_SERVICE", Message: "Station fermée"},
+	})
+	out := buf.String()
+	rows := strings.Split(strings.TrimSpace(out), "\n")
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got:\n%s", out)
+	}
+	if !strings.Contains(rows[0], "M1") || !strings.Contains(rows[0], "OK") {
+		t.Errorf("unexpected M1 row: %q", rows[0])
+	}
+	if !strings.Contains(rows[1], "M14") || !strings.Contains(rows[1], "Trafic perturbé") {
+		t.Errorf("unexpected M14 row: %q", rows[1])
+	}
+	if strings.Contains(rows[2], "M14") || !strings.Contains(rows[2], "Station fermée") {
+		t.Errorf("second M14 disruption should not repeat the line: %q", rows[2])
+	}
+
+	buf.Reset()
+	LineStatuses(&buf, nil)
+	if !strings.Contains(buf.String(), "no lines") {
+		t.Errorf("empty output = %q", buf.String())
+	}
+}
@@ -0,0 +1,252 @@
+package display
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+// Panes for the full-screen dashboard (metro tui). Each returns lines
+// already fitted to a width, so the caller only has to lay them out.
+
+// PlacePane renders one place's departures as a dashboard column: a title,
+// then one line per line+direction with a "!" on disrupted lines.
+func PlacePane(title string, deps []model.Departure, disruptions []model.Disruption, now time.Time, width int) []string {
+	lines := []string{FitWidth(bold+title+reset, width), FitWidth(dim+strings.Repeat("─", width)+reset, width)}
+	if len(deps) == 0 {
+		return append(lines, FitWidth(dim+"(no upcoming departures)"+reset, width))
+	}
+
+	worst := worstEffectByLine(disruptions)
+	groups := GroupDepartures(deps, maxTimesPerGroup)
+	labelWidth := 0
+	for _, g := range groups {
+		labelWidth = max(labelWidth, utf8.RuneCountInString(model.LineLabel(g.Code, g.CommercialMode)))
+	}
+	dirWidth := min(24, max(8, width/3))
+
+	for _, g := range groups {
+		label := model.LineLabel(g.Code, g.CommercialMode)
+		mark := " "
+		if len(g.Departures) > 0 && g.Departures[0].Route.Line != nil {
+			if effect, ok := worst[g.Departures[0].Route.Line.ID]; ok && effectRank(effect) >= effectRank("MODIFIED_SERVICE") {
+				mark = yellow + "!" + reset
+			}
+		}
+		line := fmt.Sprintf("%s%s%-*s%s %s %s", mark, bold, labelWidth, label, reset,
+			FitWidth(g.Direction, dirWidth), g.FormatTimes(now))
+		lines = append(lines, FitWidth(line, width))
+	}
+	return lines
+}
+
+// LineDisruption is an active disruption on one line, for the dashboard.
+type LineDisruption struct {
+	Label      string // e.g. "M14", "RER B"
+	Mode       string
+	Disruption *model.Disruption
+}
+
+// LineDisruptions lists the active disruptions of a mode's lines, most
+// severe first, then by line order.
+func LineDisruptions(resp *model.LinesResponse, mode model.TransportMode) []LineDisruption {
+	if resp == nil {
+		return nil
+	}
+	byLine := activeDisruptionsByLine(resp.Disruptions)
+	var items []LineDisruption
+	for _, line := range resp.Lines {
+		for _, d := range byLine[line.ID] {
+			items = append(items, LineDisruption{Label: mode.Prefix + line.Code, Mode: mode.Name, Disruption: d})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return effectRank(items[i].Disruption.Severity.Effect) > effectRank(items[j].Disruption.Severity.Effect)
+	})
+	return items
+}
+
+// DisruptionPane renders a scrollable list of disruptions in height lines,
+// highlighting the selected one.
+func DisruptionPane(items []LineDisruption, selected, width, height int) []string {
+	if height <= 0 {
+		return nil
+	}
+	if len(items) == 0 {
+		return []string{FitWidth(green+"No disruptions"+reset, width)}
+	}
+
+	// Keep the selection visible
+	start := 0
+	if selected >= height {
+		start = selected - height + 1
+	}
+	var lines []string
+	for i := start; i < len(items) && len(lines) < height; i++ {
+		it := items[i]
+		cursor := "  "
+		if i == selected {
+			cursor = cyan + "▸ " + reset
+		}
+		line := fmt.Sprintf("%s%s%-6s%s %s  %s", cursor, bold, it.Label, reset,
+			FitWidth(formatSeverity(it.Disruption.Severity), 11), DisruptionMessage(*it.Disruption))
+		lines = append(lines, FitWidth(line, width))
+	}
+	return lines
+}
+
+// DisruptionDetail renders a disruption's full text, wrapped to width.
+func DisruptionDetail(item LineDisruption, width int) []string {
+	d := item.Disruption
+	lines := []string{FitWidth(fmt.Sprintf("%s%s%s  %s", bold, item.Label, reset, formatSeverity(d.Severity)), width)}
+	for _, p := range d.ApplicationPeriods {
+		begin, err1 := ParseNavitiaTime(p.Begin)
+		end, err2 := ParseNavitiaTime(p.End)
+		if err1 == nil && err2 == nil {
+			lines = append(lines, FitWidth(fmt.Sprintf("%s%s → %s%s", dim, begin.Format("Mon 2 Jan 15:04"), end.Format("Mon 2 Jan 15:04"), reset), width))
+		}
+	}
+	lines = append(lines, "")
+	for _, para := range strings.Split(FullMessage(*d), "\n") {
+		if para == "" {
+			lines = append(lines, "")
+			continue
+		}
+		lines = append(lines, wrapText(para, width)...)
+	}
+	return lines
+}
+
+// FullMessage returns the longest message of a disruption as plain text.
+// Web messages carry the details; SMS ones are one-line summaries.
+func FullMessage(d model.Disruption) string {
+	best := ""
+	for _, m := range d.Messages {
+		text := m.Text
+		if m.Channel.ContentType == "text/html" || strings.Contains(text, "<") {
+			text = HTMLToText(text)
+		}
+		if len(text) > len(best) {
+			best = text
+		}
+	}
+	if best == "" {
+		return d.Cause
+	}
+	return best
+}
+
+var (
+	htmlBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</li>|</div>|</h[1-6]>`)
+	htmlItems  = regexp.MustCompile(`(?i)<li[^>]*>`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLToText converts a disruption's HTML message to plain text: block
+// ends become line breaks, list items become "- ", tags are dropped and
+// entities decoded.
+func HTMLToText(s string) string {
+	s = htmlBreaks.ReplaceAllString(s, "\n")
+	s = htmlItems.ReplaceAllString(s, "- ")
+	s = html.UnescapeString(stripHTMLTags(s))
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.Join(strings.Fields(l), " ")
+	}
+	s = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// wrapText splits plain text into lines of at most width runes, breaking
+// at spaces.
+func wrapText(s string, width int) []string {
+	var lines []string
+	var cur []string
+	n := 0
+	for _, word := range strings.Fields(s) {
+		wl := utf8.RuneCountInString(word)
+		if n > 0 && n+1+wl > width {
+			lines = append(lines, strings.Join(cur, " "))
+			cur, n = nil, 0
+		}
+		if n > 0 {
+			n++
+		}
+		cur = append(cur, word)
+		n += wl
+	}
+	if len(cur) > 0 {
+		lines = append(lines, strings.Join(cur, " "))
+	}
+	return lines
+}
+
+// VisibleWidth returns the number of runes of s shown on a terminal,
+// ignoring ANSI escape sequences.
+func VisibleWidth(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\033' {
+			i += escapeLen(s[i:])
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		n++
+	}
+	return n
+}
+
+// FitWidth pads or cuts s to exactly width visible runes, keeping ANSI
+// escapes intact. Cut text ends with "…".
+func FitWidth(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	vw := VisibleWidth(s)
+	if vw <= width {
+		return s + strings.Repeat(" ", width-vw)
+	}
+
+	var b strings.Builder
+	n := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\033' {
+			l := escapeLen(s[i:])
+			b.WriteString(s[i : i+l])
+			i += l
+			continue
+		}
+		if n == width-1 {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		b.WriteString(s[i : i+size])
+		i += size
+		n++
+	}
+	b.WriteString("…" + reset)
+	return b.String()
+}
+
+// escapeLen returns the length of the ANSI CSI sequence at the start of s
+// ("\033[...m" and the like), or 1 for a lone escape.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[1] != '[' {
+		return 1
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,60 @@
+package display
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+func TestFitWidth(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"abc", 5, "abc  "},
+		{"abcdef", 4, "abc…" + reset},
+		{bold + "M1" + reset, 3, bold + "M1" + reset + " "},
+		{bold + "Défense" + reset, 4, bold + "Déf…" + reset},
+		{"abc", 0, ""},
+	}
+	for _, tt := range tests {
+		got := FitWidth(tt.in, tt.width)
+		if got != tt.want {
+			t.Errorf("FitWidth(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+		if tt.width > 0 && VisibleWidth(got) != tt.width {
+			t.Errorf("FitWidth(%q, %d) has visible width %d", tt.in, tt.width, VisibleWidth(got))
+		}
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	in := `<p>Trafic perturbé&nbsp;:</p><ul><li>entre <b>A</b> et B</li><li>reprise 18h</li></ul><br/><br/><br/>Merci`
+	want := "Trafic perturbé :\n- entre A et B\n- reprise 18h\n\nMerci"
+	if got := HTMLToText(in); got != want {
+		t.Errorf("HTMLToText() = %q, want %q", got, want)
+	}
+}
+
+func TestLineDisruptions(t *testing.T) {
+	resp := &model.LinesResponse{
+		Lines: []model.Line{{ID: "line:1", Code: "1"}, {ID: "line:14", Code: "14"}},
+		Disruptions: []model.Disruption{
+			{ID: "d1", Status: "active", Severity: model.Severity{Effect: "SIGNIFICANT_DELAYS"},
+				ImpactedObjects: []model.ImpactedObject{{PTObject: model.PTObject{ID: "line:1"}}}},
+			{ID: "d2", Status: "active", Severity: model.Severity{Effect: "NO_SERVICE"},
+				ImpactedObjects: []model.ImpactedObject{{PTObject: model.PTObject{ID: "line:14"}}}},
+		},
+	}
+	items := LineDisruptions(resp, model.TransportMode{Name: "metro", Prefix: "M"})
+	if len(items) != 2 || items[0].Label != "M14" || items[1].Label != "M1" {
+		t.Fatalf("LineDisruptions() = %+v, want M14 then M1", items)
+	}
+
+	pane := DisruptionPane(items, 1, 40, 5)
+	if len(pane) != 2 || !strings.Contains(pane[1], "▸") || strings.Contains(pane[0], "▸") {
+		t.Errorf("DisruptionPane() does not mark the selection:\n%s", strings.Join(pane, "\n"))
+	}
+}
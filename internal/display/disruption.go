@@ -0,0 +1,181 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+// Disruption prints everything known about a disruption: status, periods,
+// cause, category, tags, every message as plain text wrapped to width,
+// and the impacted stops with their cause.
+func Disruption(w io.Writer, labels []string, d model.Disruption, width int) {
+	title := strings.Join(labels, ", ")
+	if title == "" {
+		title = "Disruption"
+	}
+	fmt.Fprintf(w, "%s%s%s  %s  %s%s%s\n", bold, title, reset, formatSeverity(d.Severity), dim, d.Status, reset)
+
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "  %s%-9s%s %s\n", dim, name, reset, value)
+		}
+	}
+	id := d.DisruptionID
+	if id == "" {
+		id = d.ID
+	}
+	field("ID", id)
+	for i, p := range d.ApplicationPeriods {
+		name := ""
+		if i == 0 {
+			name = "Periods"
+		}
+		fmt.Fprintf(w, "  %s%-9s%s %s\n", dim, name, reset, FormatPeriod(p))
+	}
+	field("Cause", d.Cause)
+	field("Category", d.Category)
+	field("Tags", strings.Join(d.Tags, ", "))
+
+	// The same text often comes on several channels
+	seen := make(map[string]bool)
+	for _, m := range d.Messages {
+		text := m.Text
+		if m.Channel.ContentType == "text/html" || strings.Contains(text, "<") {
+			text = HTMLToText(text)
+		}
+		if text == "" || seen[text] {
+			continue
+		}
+		seen[text] = true
+		channel := m.Channel.Name
+		if channel == "" {
+			channel = "Message"
+		}
+		fmt.Fprintf(w, "\n  %s%s%s\n", bold, channel, reset)
+		for _, para := range strings.Split(text, "\n") {
+			if para == "" {
+				fmt.Fprintln(w)
+				continue
+			}
+			for _, l := range wrapText(para, max(width-4, 20)) {
+				fmt.Fprintf(w, "    %s\n", l)
+			}
+		}
+	}
+
+	type stop struct{ name, cause string }
+	var stops []stop
+	seenStops := make(map[stop]bool)
+	for _, obj := range d.ImpactedObjects {
+		for _, is := range obj.ImpactedStops {
+			s := stop{is.StopPoint.Name, is.Cause}
+			if !seenStops[s] {
+				seenStops[s] = true
+				stops = append(stops, s)
+			}
+		}
+	}
+	if len(stops) > 0 {
+		fmt.Fprintf(w, "\n  %sImpacted stops%s\n", bold, reset)
+		nameWidth := 0
+		for _, s := range stops {
+			nameWidth = max(nameWidth, VisibleWidth(s.name))
+		}
+		for _, s := range stops {
+			cause := s.cause
+			if cause == "" {
+				cause = dim + "-" + reset
+			}
+			fmt.Fprintf(w, "    %s  %s\n", FitWidth(s.name, nameWidth), cause)
+		}
+	}
+}
+
+// FormatPeriod formats an application period in Paris time, e.g.
+// "Sat 25 Apr 22:00 → Sun 26 Apr 05:00" or "Wed 25 Feb 05:00 → 23:59".
+func FormatPeriod(p model.Period) string {
+	begin, err := ParseNavitiaTime(p.Begin)
+	if err != nil {
+		return p.Begin + " → " + p.End
+	}
+	end, err := ParseNavitiaTime(p.End)
+	if err != nil {
+		return begin.Format("Mon 2 Jan 15:04") + " →"
+	}
+	if sameDay(begin, end) {
+		return begin.Format("Mon 2 Jan 15:04") + " → " + end.Format("15:04")
+	}
+	return begin.Format("Mon 2 Jan 15:04") + " → " + end.Format("Mon 2 Jan 15:04")
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// DisruptionRecord is a disruption's details in structured output.
+// Periods are "begin/end" in RFC 3339, Paris time.
+type DisruptionRecord struct {
+	DisruptionID string   `json:"disruption_id" yaml:"disruption_id"`
+	Lines        []string `json:"lines" yaml:"lines"`
+	Status       string   `json:"status" yaml:"status"`
+	Severity     string   `json:"severity" yaml:"severity"`
+	SeverityName string   `json:"severity_name" yaml:"severity_name"`
+	Cause        string   `json:"cause" yaml:"cause"`
+	Category     string   `json:"category,omitempty" yaml:"category,omitempty"`
+	Tags         []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Periods      []string `json:"periods" yaml:"periods"`
+	Message      string   `json:"message" yaml:"message"`
+	Stops        []string `json:"impacted_stops,omitempty" yaml:"impacted_stops,omitempty"`
+}
+
+func (DisruptionRecord) csvHeader() []string {
+	return []string{"disruption_id", "lines", "status", "severity", "severity_name", "cause", "category", "tags", "periods", "message", "impacted_stops"}
+}
+
+func (r DisruptionRecord) csvRow() []string {
+	return []string{r.DisruptionID, strings.Join(r.Lines, ";"), r.Status, r.Severity, r.SeverityName, r.Cause, r.Category,
+		strings.Join(r.Tags, ";"), strings.Join(r.Periods, ";"), r.Message, strings.Join(r.Stops, ";")}
+}
+
+// NewDisruptionRecord flattens a disruption. The message is the longest
+// one, as plain text.
+func NewDisruptionRecord(labels []string, d model.Disruption) DisruptionRecord {
+	r := DisruptionRecord{
+		DisruptionID: d.DisruptionID,
+		Lines:        labels,
+		Status:       d.Status,
+		Severity:     d.Severity.Effect,
+		SeverityName: d.Severity.Name,
+		Cause:        d.Cause,
+		Category:     d.Category,
+		Tags:         d.Tags,
+		Periods:      []string{},
+		Message:      FullMessage(d),
+	}
+	if r.DisruptionID == "" {
+		r.DisruptionID = d.ID
+	}
+	for _, p := range d.ApplicationPeriods {
+		begin, err1 := ParseNavitiaTime(p.Begin)
+		end, err2 := ParseNavitiaTime(p.End)
+		if err1 == nil && err2 == nil {
+			r.Periods = append(r.Periods, begin.Format(time.RFC3339)+"/"+end.Format(time.RFC3339))
+		}
+	}
+	seen := make(map[string]bool)
+	for _, obj := range d.ImpactedObjects {
+		for _, is := range obj.ImpactedStops {
+			if !seen[is.StopPoint.Name] {
+				seen[is.StopPoint.Name] = true
+				r.Stops = append(r.Stops, is.StopPoint.Name)
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,63 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+func TestDisruption(t *testing.T) {
+	long := "Travaux de modernisation : le trafic est interrompu entre Gare du Nord et Châtelet - Les Halles, un bus de remplacement dessert les gares."
+	d := model.Disruption{
+		DisruptionID: "d-1",
+		Status:       "future",
+		Severity:     model.Severity{Effect: "NO_SERVICE"},
+		ApplicationPeriods: []model.Period{
+			{Begin: "20260425T000000", End: "20260426T050000"},
+			{Begin: "20260502T220000", End: "20260502T235900"},
+		},
+		Cause:    "travaux",
+		Category: "Travaux",
+		Tags:     []string{"Ascenseur", "Week-end"},
+		Messages: []model.Message{
+			{Text: "<p>" + long + "</p>", Channel: model.Channel{Name: "web", ContentType: "text/html"}},
+			{Text: long, Channel: model.Channel{Name: "moteur", ContentType: "text/plain"}},
+			{Text: "RER B : trafic interrompu", Channel: model.Channel{Name: "titre"}},
+		},
+		ImpactedObjects: []model.ImpactedObject{{ImpactedStops: []model.ImpactedStop{
+			{StopPoint: model.StopPoint{Name: "Gare du Nord"}, Cause: "Travaux"},
+			{StopPoint: model.StopPoint{Name: "Châtelet - Les Halles"}},
+		}}},
+	}
+
+	var buf bytes.Buffer
+	Disruption(&buf, []string{"RER B"}, d, 60)
+	out := buf.String()
+	for _, want := range []string{
+		"RER B", "future", "d-1",
+		"Sat 25 Apr 00:00 → Sun 26 Apr 05:00", "Sat 2 May 22:00 → 23:59",
+		"travaux", "Ascenseur, Week-end",
+		"web", "titre", "RER B : trafic interrompu",
+		"remplacement dessert les gares.", "Impacted stops", "Gare du Nord           Travaux",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<p>") || strings.Contains(out, "moteur") {
+		t.Errorf("HTML kept or duplicate message shown:\n%s", out)
+	}
+	for _, l := range strings.Split(out, "\n") {
+		if VisibleWidth(l) > 60 {
+			t.Errorf("line wider than 60: %q", l)
+		}
+	}
+
+	r := NewDisruptionRecord([]string{"RER B"}, d)
+	if r.Message != long || len(r.Periods) != 2 || r.Periods[0] != "2026-04-25T00:00:00+02:00/2026-04-26T05:00:00+02:00" ||
+		len(r.Stops) != 2 {
+		t.Errorf("NewDisruptionRecord() = %+v", r)
+	}
+}
@@ -0,0 +1,333 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/history"
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+// maxWorstDays is how many days the history report ranks.
+const maxWorstDays = 5
+
+// HistorySummary is the recorded disruption history of a period.
+type HistorySummary struct {
+	Since, Until time.Time
+	Recorded     time.Time // first observation in the log, zero if empty
+	Lines        []LineHistory
+	WorstDays    []DayHistory
+	Disruptions  []history.Entry // within the period, by first seen
+}
+
+// LineHistory is one line's disruptions over the period. Hours counts
+// the time covered by service-affecting disruptions (modified service or
+// worse), overlaps counted once.
+type LineHistory struct {
+	Line        string
+	Disruptions int
+	Hours       float64
+	Worst       string // effect, e.g. "NO_SERVICE"
+}
+
+// DayHistory is a day's disrupted hours, summed over lines.
+type DayHistory struct {
+	Day   time.Time // midnight, Paris time
+	Hours float64
+	Lines []string
+	Worst string
+}
+
+type interval struct{ start, end time.Time }
+
+// SummarizeHistory summarizes the entries active between since and until,
+// on lines matching line (all lines if empty). Disruption times come from
+// their application periods, or from when they were seen if they have none.
+func SummarizeHistory(entries []history.Entry, line string, since, until time.Time) HistorySummary {
+	s := HistorySummary{Since: since, Until: until}
+	byLine := make(map[string]*LineHistory)
+	spans := make(map[string][]interval) // service-affecting, by line
+	days := make(map[time.Time]*DayHistory)
+
+	for _, e := range entries {
+		if s.Recorded.IsZero() || e.FirstSeen.Before(s.Recorded) {
+			s.Recorded = e.FirstSeen
+		}
+		var lines []string
+		for _, l := range e.Lines {
+			if line == "" || model.MatchLine(l, line) {
+				lines = append(lines, l)
+			}
+		}
+		ivs := entryIntervals(e, since, until)
+		if len(lines) == 0 || len(ivs) == 0 {
+			continue
+		}
+		s.Disruptions = append(s.Disruptions, e)
+
+		worst := worstEffect(e)
+		affecting := effectRank(worst) >= effectRank("MODIFIED_SERVICE")
+		for _, l := range lines {
+			lh := byLine[l]
+			if lh == nil {
+				lh = &LineHistory{Line: l}
+				byLine[l] = lh
+			}
+			lh.Disruptions++
+			if effectRank(worst) > effectRank(lh.Worst) || lh.Worst == "" {
+				lh.Worst = worst
+			}
+			if affecting {
+				spans[l] = append(spans[l], ivs...)
+			}
+		}
+	}
+
+	for l, ivs := range spans {
+		for _, iv := range mergeIntervals(ivs) {
+			byLine[l].Hours += iv.end.Sub(iv.start).Hours()
+			for _, part := range splitDays(iv) {
+				day := startOfDay(part.start)
+				d := days[day]
+				if d == nil {
+					d = &DayHistory{Day: day}
+					days[day] = d
+				}
+				d.Hours += part.end.Sub(part.start).Hours()
+				if !containsString(d.Lines, l) {
+					d.Lines = append(d.Lines, l)
+				}
+			}
+		}
+	}
+	// The worst effect of a day is that of the disruptions covering it
+	for _, e := range s.Disruptions {
+		worst := worstEffect(e)
+		for _, iv := range entryIntervals(e, since, until) {
+			for _, part := range splitDays(iv) {
+				if d := days[startOfDay(part.start)]; d != nil && effectRank(worst) > effectRank(d.Worst) {
+					d.Worst = worst
+				}
+			}
+		}
+	}
+
+	for _, lh := range byLine {
+		s.Lines = append(s.Lines, *lh)
+	}
+	sort.Slice(s.Lines, func(i, j int) bool {
+		if s.Lines[i].Hours != s.Lines[j].Hours {
+			return s.Lines[i].Hours > s.Lines[j].Hours
+		}
+		if s.Lines[i].Disruptions != s.Lines[j].Disruptions {
+			return s.Lines[i].Disruptions > s.Lines[j].Disruptions
+		}
+		return s.Lines[i].Line < s.Lines[j].Line
+	})
+
+	for _, d := range days {
+		sort.Strings(d.Lines)
+		s.WorstDays = append(s.WorstDays, *d)
+	}
+	sort.Slice(s.WorstDays, func(i, j int) bool {
+		if s.WorstDays[i].Hours != s.WorstDays[j].Hours {
+			return s.WorstDays[i].Hours > s.WorstDays[j].Hours
+		}
+		return s.WorstDays[i].Day.Before(s.WorstDays[j].Day)
+	})
+	if len(s.WorstDays) > maxWorstDays {
+		s.WorstDays = s.WorstDays[:maxWorstDays]
+	}
+	return s
+}
+
+// entryIntervals returns when a disruption applied, clipped to
+// [since, until].
+func entryIntervals(e history.Entry, since, until time.Time) []interval {
+	var ivs []interval
+	for _, p := range e.ApplicationPeriods {
+		begin, err := ParseNavitiaTime(p.Begin)
+		if err != nil {
+			continue
+		}
+		end, err := ParseNavitiaTime(p.End)
+		if err != nil {
+			end = e.LastSeen
+		}
+		ivs = append(ivs, interval{begin, end})
+	}
+	if len(ivs) == 0 {
+		ivs = append(ivs, interval{e.FirstSeen, e.LastSeen})
+	}
+
+	var clipped []interval
+	for _, iv := range ivs {
+		if iv.start.Before(since) {
+			iv.start = since
+		}
+		if iv.end.After(until) {
+			iv.end = until
+		}
+		if iv.end.After(iv.start) {
+			clipped = append(clipped, iv)
+		}
+	}
+	return clipped
+}
+
+// mergeIntervals returns the union of ivs as disjoint intervals.
+func mergeIntervals(ivs []interval) []interval {
+	sort.Slice(ivs, func(i, j int) bool { return ivs[i].start.Before(ivs[j].start) })
+	var out []interval
+	for _, iv := range ivs {
+		if n := len(out); n > 0 && !iv.start.After(out[n-1].end) {
+			if iv.end.After(out[n-1].end) {
+				out[n-1].end = iv.end
+			}
+			continue
+		}
+		out = append(out, iv)
+	}
+	return out
+}
+
+// splitDays cuts an interval at Paris midnights.
+func splitDays(iv interval) []interval {
+	var parts []interval
+	for iv.start.Before(iv.end) {
+		next := startOfDay(iv.start).AddDate(0, 0, 1)
+		if next.After(iv.end) {
+			next = iv.end
+		}
+		parts = append(parts, interval{iv.start, next})
+		iv.start = next
+	}
+	return parts
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.In(paris)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, paris)
+}
+
+func worstEffect(e history.Entry) string {
+	worst := e.Severity.Effect
+	for _, eff := range e.Effects {
+		if effectRank(eff) > effectRank(worst) {
+			worst = eff
+		}
+	}
+	return worst
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// History prints a history summary: per-line totals, the worst days and,
+// when listDisruptions is set, every disruption of the period.
+func History(w io.Writer, s HistorySummary, listDisruptions bool) {
+	fmt.Fprintf(w, "%sDisruptions since %s%s %s(%s)%s\n", bold, s.Since.In(paris).Format("Mon 2 Jan 2006"), reset,
+		dim, formatDays(s.Until.Sub(s.Since)), reset)
+	if !s.Recorded.IsZero() && s.Recorded.After(s.Since) {
+		fmt.Fprintf(w, "%sRecorded since %s: earlier disruptions are missing.%s\n", dim, s.Recorded.In(paris).Format("Mon 2 Jan 15:04"), reset)
+	}
+	fmt.Fprintln(w)
+
+	if len(s.Lines) == 0 {
+		fmt.Fprintf(w, "%sNo disruptions recorded.%s\n", green, reset)
+		return
+	}
+
+	width := len("Line")
+	for _, l := range s.Lines {
+		width = max(width, VisibleWidth(l.Line))
+	}
+	fmt.Fprintf(w, "%s%-*s  %11s  %9s  %s%s\n", bold, width, "Line", "Disruptions", "Disrupted", "Worst", reset)
+	for _, l := range s.Lines {
+		fmt.Fprintf(w, "%s%-*s%s  %11d  %9s  %s\n", bold, width, l.Line, reset, l.Disruptions, formatHours(l.Hours),
+			formatSeverity(model.Severity{Effect: l.Worst}))
+	}
+
+	if len(s.WorstDays) > 0 {
+		fmt.Fprintf(w, "\n%sWorst days%s\n", bold, reset)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, d := range s.WorstDays {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", d.Day.Format("Mon 2 Jan"), formatHours(d.Hours),
+				strings.Join(d.Lines, ", "), formatSeverity(model.Severity{Effect: d.Worst}))
+		}
+		tw.Flush()
+	}
+
+	if listDisruptions {
+		fmt.Fprintf(w, "\n%sDisruptions%s\n", bold, reset)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, e := range s.Disruptions {
+			first, last := e.FirstSeen.In(paris), e.LastSeen.In(paris)
+			until := last.Format("15:04")
+			if !startOfDay(first).Equal(startOfDay(last)) {
+				until = last.Format("Mon 2 Jan 15:04")
+			}
+			fmt.Fprintf(tw, "  %s – %s\t%s\t%s %s\n", first.Format("Mon 2 Jan 15:04"), until, strings.Join(e.Lines, ", "),
+				FitWidth(formatSeverity(model.Severity{Effect: worstEffect(e)}), 11), truncate(e.Message, 70))
+		}
+		tw.Flush()
+	}
+}
+
+// formatHours returns "45 min" under an hour, else "3.5h".
+func formatHours(h float64) string {
+	if h < 1 {
+		return fmt.Sprintf("%d min", int(h*60+0.5))
+	}
+	return strconv.FormatFloat(h, 'f', 1, 64) + "h"
+}
+
+// formatDays returns "30 days" or "1 day", rounding to the nearest day.
+func formatDays(d time.Duration) string {
+	n := int(d.Hours()/24 + 0.5)
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+// HistoryRecord is one line's history in structured output.
+type HistoryRecord struct {
+	Line           string  `json:"line" yaml:"line"`
+	Disruptions    int     `json:"disruptions" yaml:"disruptions"`
+	DisruptedHours float64 `json:"disrupted_hours" yaml:"disrupted_hours"`
+	WorstSeverity  string  `json:"worst_severity" yaml:"worst_severity"`
+}
+
+func (HistoryRecord) csvHeader() []string {
+	return []string{"line", "disruptions", "disrupted_hours", "worst_severity"}
+}
+
+func (r HistoryRecord) csvRow() []string {
+	return []string{r.Line, strconv.Itoa(r.Disruptions), strconv.FormatFloat(r.DisruptedHours, 'f', 2, 64), r.WorstSeverity}
+}
+
+// HistoryRecords mirrors the per-line table of History.
+func HistoryRecords(s HistorySummary) []HistoryRecord {
+	recs := make([]HistoryRecord, 0, len(s.Lines))
+	for _, l := range s.Lines {
+		recs = append(recs, HistoryRecord{
+			Line:           l.Line,
+			Disruptions:    l.Disruptions,
+			DisruptedHours: float64(int(l.Hours*100+0.5)) / 100,
+			WorstSeverity:  l.Worst,
+		})
+	}
+	return recs
+}
@@ -0,0 +1,84 @@
+package display
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/history"
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+func historyEntry(id, effect string, lines []string, periods ...model.Period) history.Entry {
+	seen := time.Date(2026, 3, 2, 7, 0, 0, 0, paris)
+	return history.Entry{
+		DisruptionID:       id,
+		FirstSeen:          seen,
+		LastSeen:           seen.Add(time.Hour),
+		Severity:           model.Severity{Effect: effect},
+		Effects:            []string{effect},
+		Lines:              lines,
+		ApplicationPeriods: periods,
+	}
+}
+
+func TestSummarizeHistory(t *testing.T) {
+	entries := []history.Entry{
+		// Overlapping RER B disruptions: 06:00-10:00 and 08:00-12:00 is 6h
+		historyEntry("a", "SIGNIFICANT_DELAYS", []string{"RER B"}, model.Period{Begin: "20260302T060000", End: "20260302T100000"}),
+		historyEntry("b", "NO_SERVICE", []string{"RER B", "RER D"}, model.Period{Begin: "20260302T080000", End: "20260302T120000"}),
+		// Across midnight: 3h on Tue, 2h on Wed
+		historyEntry("c", "REDUCED_SERVICE", []string{"RER B"}, model.Period{Begin: "20260303T210000", End: "20260304T020000"}),
+		// Information only: counted, but not disrupted hours
+		historyEntry("d", "UNKNOWN_EFFECT", []string{"M14"}, model.Period{Begin: "20260302T000000", End: "20260305T000000"}),
+		// Before the period
+		historyEntry("e", "NO_SERVICE", []string{"M14"}, model.Period{Begin: "20260201T060000", End: "20260201T100000"}),
+	}
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, paris)
+	until := time.Date(2026, 3, 10, 0, 0, 0, 0, paris)
+
+	s := SummarizeHistory(entries, "", since, until)
+	want := []LineHistory{
+		{Line: "RER B", Disruptions: 3, Hours: 11, Worst: "NO_SERVICE"},
+		{Line: "RER D", Disruptions: 1, Hours: 4, Worst: "NO_SERVICE"},
+		{Line: "M14", Disruptions: 1, Hours: 0, Worst: "UNKNOWN_EFFECT"},
+	}
+	if len(s.Lines) != len(want) {
+		t.Fatalf("lines = %+v", s.Lines)
+	}
+	for i, w := range want {
+		if s.Lines[i] != w {
+			t.Errorf("line %d = %+v, want %+v", i, s.Lines[i], w)
+		}
+	}
+
+	if len(s.WorstDays) != 3 {
+		t.Fatalf("worst days = %+v", s.WorstDays)
+	}
+	first := s.WorstDays[0]
+	if first.Day.Format("2006-01-02") != "2026-03-02" || first.Hours != 10 || strings.Join(first.Lines, ",") != "RER B,RER D" || first.Worst != "NO_SERVICE" {
+		t.Errorf("worst day = %+v", first)
+	}
+	if d := s.WorstDays[1]; d.Day.Format("2006-01-02") != "2026-03-03" || d.Hours != 3 {
+		t.Errorf("second day = %+v", d)
+	}
+	if len(s.Disruptions) != 4 {
+		t.Errorf("got %d disruptions in the period, want 4", len(s.Disruptions))
+	}
+
+	s = SummarizeHistory(entries, "rerd", since, until)
+	if len(s.Lines) != 1 || s.Lines[0].Line != "RER D" || len(s.Disruptions) != 1 {
+		t.Errorf("RER D only: %+v", s.Lines)
+	}
+}
+
+func TestSummarizeHistoryWithoutPeriods(t *testing.T) {
+	e := historyEntry("a", "NO_SERVICE", []string{"M1"})
+	since := e.FirstSeen.Add(-24 * time.Hour)
+
+	// Falls back to when the disruption was seen, clipped to the period
+	s := SummarizeHistory([]history.Entry{e}, "", since, e.FirstSeen.Add(30*time.Minute))
+	if len(s.Lines) != 1 || s.Lines[0].Hours != 0.5 {
+		t.Errorf("lines = %+v", s.Lines)
+	}
+}
@@ -0,0 +1,200 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+// Journeys prints itineraries, each followed by its sections
+// (walk, line, transfer) with times and durations.
+func Journeys(w io.Writer, journeys []model.Journey) {
+	if len(journeys) == 0 {
+		fmt.Fprintf(w, "  %s(no itinerary found)%s\n", dim, reset)
+		return
+	}
+
+	for i, j := range journeys {
+		fmt.Fprintf(w, "  %s%d. %s → %s%s  %s%s%s  %s%s%s\n",
+			bold, i+1, clock(j.DepartureDateTime), clock(j.ArrivalDateTime), reset,
+			cyan, formatDuration(j.Duration), reset,
+			dim, transfersText(j.NbTransfers), reset)
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, s := range j.Sections {
+			kind := sectionKind(s)
+			if kind == "" || (kind != "transit" && s.Duration == 0) {
+				continue
+			}
+			label, desc := sectionText(s, kind)
+			fmt.Fprintf(tw, "     %s\t%s\t%s\t%s\n", label, clock(s.DepartureDateTime), formatDuration(s.Duration), desc)
+		}
+		tw.Flush()
+		fmt.Fprintln(w)
+	}
+}
+
+// transfersText describes a number of transfers: "direct", "1 transfer"...
+func transfersText(n int) string {
+	switch {
+	case n == 1:
+		return "1 transfer"
+	case n > 1:
+		return fmt.Sprintf("%d transfers", n)
+	default:
+		return "direct"
+	}
+}
+
+// sectionKind classifies a Navitia section as "walk", "transit",
+// "transfer" or "wait". Other section types (boarding, park...) return "".
+func sectionKind(s model.Section) string {
+	switch s.Type {
+	case "public_transport", "on_demand_transport":
+		return "transit"
+	case "street_network", "crow_fly":
+		return "walk"
+	case "transfer":
+		return "transfer"
+	case "waiting":
+		return "wait"
+	default:
+		return ""
+	}
+}
+
+// sectionText returns the label and description columns for a section.
+func sectionText(s model.Section, kind string) (string, string) {
+	switch kind {
+	case "transit":
+		di := s.DisplayInformations
+		if di == nil {
+			di = &model.DisplayInfo{}
+		}
+		desc := fmt.Sprintf("%s → %s", placeName(s.From), placeName(s.To))
+		if di.Direction != "" {
+			desc += fmt.Sprintf("  %s(dir. %s)%s", dim, truncate(di.Direction, 30), reset)
+		}
+		return lineLabel(di.Code, di.CommercialMode), desc
+	case "walk":
+		verb := "Walk"
+		switch s.Mode {
+		case "bike", "bss":
+			verb = "Bike"
+		case "car":
+			verb = "Drive"
+		}
+		return dim + verb + reset, "to " + placeName(s.To)
+	case "transfer":
+		return dim + "Transfer" + reset, ""
+	default:
+		return dim + "Wait" + reset, ""
+	}
+}
+
+// JourneySectionRecord is one section of an itinerary in structured output.
+// Journey-level fields repeat on every section of the same journey.
+type JourneySectionRecord struct {
+	Journey          int    `json:"journey" yaml:"journey"`
+	JourneyDeparture string `json:"journey_departure" yaml:"journey_departure"`
+	JourneyArrival   string `json:"journey_arrival" yaml:"journey_arrival"`
+	JourneyMinutes   int    `json:"journey_minutes" yaml:"journey_minutes"`
+	Transfers        int    `json:"transfers" yaml:"transfers"`
+	Type             string `json:"type" yaml:"type"` // "walk", "transit", "transfer" or "wait"
+	Line             string `json:"line,omitempty" yaml:"line,omitempty"`
+	Mode             string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Direction        string `json:"direction,omitempty" yaml:"direction,omitempty"`
+	From             string `json:"from,omitempty" yaml:"from,omitempty"`
+	To               string `json:"to,omitempty" yaml:"to,omitempty"`
+	Departure        string `json:"departure" yaml:"departure"`
+	Arrival          string `json:"arrival" yaml:"arrival"`
+	Minutes          int    `json:"minutes" yaml:"minutes"`
+}
+
+func (JourneySectionRecord) csvHeader() []string {
+	return []string{"journey", "journey_departure", "journey_arrival", "journey_minutes", "transfers",
+		"type", "line", "mode", "direction", "from", "to", "departure", "arrival", "minutes"}
+}
+
+func (r JourneySectionRecord) csvRow() []string {
+	return []string{strconv.Itoa(r.Journey), r.JourneyDeparture, r.JourneyArrival,
+		strconv.Itoa(r.JourneyMinutes), strconv.Itoa(r.Transfers),
+		r.Type, r.Line, r.Mode, r.Direction, r.From, r.To, r.Departure, r.Arrival, strconv.Itoa(r.Minutes)}
+}
+
+// JourneyRecords flattens itineraries into one record per section.
+func JourneyRecords(journeys []model.Journey) []JourneySectionRecord {
+	var recs []JourneySectionRecord
+	for i, j := range journeys {
+		for _, s := range j.Sections {
+			kind := sectionKind(s)
+			if kind == "" || (kind != "transit" && s.Duration == 0) {
+				continue
+			}
+			r := JourneySectionRecord{
+				Journey:          i + 1,
+				JourneyDeparture: isoTime(j.DepartureDateTime),
+				JourneyArrival:   isoTime(j.ArrivalDateTime),
+				JourneyMinutes:   ceilMinutes(j.Duration),
+				Transfers:        j.NbTransfers,
+				Type:             kind,
+				From:             placeName(s.From),
+				To:               placeName(s.To),
+				Departure:        isoTime(s.DepartureDateTime),
+				Arrival:          isoTime(s.ArrivalDateTime),
+				Minutes:          ceilMinutes(s.Duration),
+			}
+			if di := s.DisplayInformations; kind == "transit" && di != nil {
+				r.Line = model.LineLabel(di.Code, di.CommercialMode)
+				r.Mode = modeName(di.CommercialMode)
+				r.Direction = di.Direction
+			}
+			recs = append(recs, r)
+		}
+	}
+	return recs
+}
+
+// placeName returns a section endpoint name, or "?" when missing.
+func placeName(p *model.NavitiaPlace) string {
+	if p == nil || p.Name == "" {
+		return "?"
+	}
+	return p.Name
+}
+
+// clock formats a Navitia time as "15:04", or "" if unparsable.
+func clock(s string) string {
+	t, err := ParseNavitiaTime(s)
+	if err != nil {
+		return ""
+	}
+	return t.Format("15:04")
+}
+
+// isoTime converts a Navitia time to RFC 3339, or "" if unparsable.
+func isoTime(s string) string {
+	t, err := ParseNavitiaTime(s)
+	if err != nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// ceilMinutes converts seconds to whole minutes, rounding up.
+func ceilMinutes(seconds int) int {
+	return (seconds + 59) / 60
+}
+
+// formatDuration returns "35 min" or "1h05" for a duration in seconds.
+func formatDuration(seconds int) string {
+	mins := ceilMinutes(seconds)
+	if mins >= 60 {
+		return fmt.Sprintf("%dh%02d", mins/60, mins%60)
+	}
+	return fmt.Sprintf("%d min", mins)
+}
@@ -0,0 +1,63 @@
+package display
+
+import (
+	"testing"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    string
+	}{
+		{0, "0 min"},
+		{59, "1 min"},
+		{600, "10 min"},
+		{3600, "1h00"},
+		{3900, "1h05"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.seconds); got != tt.want {
+			t.Errorf("formatDuration(%d) = %q, want %q", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestJourneyRecords(t *testing.T) {
+	journeys := []model.Journey{{
+		Duration:          1500,
+		NbTransfers:       0,
+		DepartureDateTime: "20260225T080000",
+		ArrivalDateTime:   "20260225T082500",
+		Sections: []model.Section{
+			{Type: "crow_fly", Duration: 0},
+			{Type: "street_network", Mode: "walking", Duration: 240,
+				To: &model.NavitiaPlace{Name: "Châtelet"}},
+			{Type: "public_transport", Duration: 900,
+				DepartureDateTime: "20260225T080400", ArrivalDateTime: "20260225T081900",
+				From:                &model.NavitiaPlace{Name: "Châtelet"},
+				To:                  &model.NavitiaPlace{Name: "La Défense"},
+				DisplayInformations: &model.DisplayInfo{Code: "1", CommercialMode: "Métro", Direction: "La Défense"}},
+			{Type: "waiting", Duration: 120},
+		},
+	}}
+
+	recs := JourneyRecords(journeys)
+	if len(recs) != 3 {
+		t.Fatalf("expected 3 records (empty crow_fly skipped), got %d", len(recs))
+	}
+	if recs[0].Type != "walk" || recs[0].To != "Châtelet" || recs[0].Minutes != 4 {
+		t.Errorf("unexpected walk record: %+v", recs[0])
+	}
+	tr := recs[1]
+	if tr.Type != "transit" || tr.Line != "M1" || tr.Mode != "metro" || tr.From != "Châtelet" || tr.To != "La Défense" {
+		t.Errorf("unexpected transit record: %+v", tr)
+	}
+	if tr.JourneyMinutes != 25 || tr.Journey != 1 {
+		t.Errorf("unexpected journey fields: %+v", tr)
+	}
+	if recs[2].Type != "wait" {
+		t.Errorf("expected wait record, got %+v", recs[2])
+	}
+}
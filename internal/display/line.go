@@ -0,0 +1,286 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+// RouteStops is one route's stop areas in running order.
+type RouteStops struct {
+	Route model.Route
+	Stops []model.StopArea
+}
+
+// LineDiagram is a line's branches in one direction and the stops they
+// serve, merged into a single order.
+type LineDiagram struct {
+	Label       string
+	Line        model.Line
+	Branches    []Branch
+	Stops       []DiagramStop
+	Disruptions []*model.Disruption // active on the line
+}
+
+// Branch is a route not contained in a longer one.
+type Branch struct {
+	From, To string
+	Stops    int
+}
+
+// DiagramStop is a stop, the branches serving it, the other lines
+// serving it and, if it is impacted by a disruption, its cause.
+type DiagramStop struct {
+	ID          string
+	Name        string
+	Served      []bool // per branch
+	Connections []string
+	Disrupted   bool
+	Cause       string
+}
+
+// NewLineDiagram builds the diagram of routes, all in one direction.
+// Routes whose stops all belong to a longer route (short runs) are
+// dropped; the others become branches. stopAreas supplies connections,
+// and the active disruptions on the line mark their impacted stops.
+func NewLineDiagram(label string, line model.Line, routes []RouteStops, stopAreas []model.StopArea, disruptions []model.Disruption) LineDiagram {
+	diagram := LineDiagram{Label: label, Line: line}
+
+	var branches []RouteStops
+	for i, r := range routes {
+		if len(r.Stops) == 0 || containedInOther(routes, i) {
+			continue
+		}
+		branches = append(branches, r)
+	}
+	sort.SliceStable(branches, func(i, j int) bool { return len(branches[i].Stops) > len(branches[j].Stops) })
+
+	order := mergeStops(branches)
+	index := make(map[string]int, len(order))
+	for i, s := range order {
+		index[s.ID] = i
+		diagram.Stops = append(diagram.Stops, DiagramStop{ID: s.ID, Name: s.Name, Served: make([]bool, len(branches))})
+	}
+	for b, r := range branches {
+		diagram.Branches = append(diagram.Branches, Branch{From: r.Stops[0].Name, To: r.Stops[len(r.Stops)-1].Name, Stops: len(r.Stops)})
+		for _, s := range r.Stops {
+			diagram.Stops[index[s.ID]].Served[b] = true
+		}
+	}
+
+	for _, sa := range stopAreas {
+		i, ok := index[sa.ID]
+		if !ok {
+			continue
+		}
+		for _, l := range sa.Lines {
+			if l.ID == line.ID {
+				continue
+			}
+			mode := ""
+			if l.CommercialMode != nil {
+				mode = l.CommercialMode.Name
+			}
+			if c := model.LineLabel(l.Code, mode); c != "" && !slices.Contains(diagram.Stops[i].Connections, c) {
+				diagram.Stops[i].Connections = append(diagram.Stops[i].Connections, c)
+			}
+		}
+		sort.SliceStable(diagram.Stops[i].Connections, func(a, b int) bool {
+			return connectionRank(diagram.Stops[i].Connections[a]) < connectionRank(diagram.Stops[i].Connections[b])
+		})
+	}
+
+	diagram.Disruptions = activeDisruptionsByLine(disruptions)[line.ID]
+	for _, d := range diagram.Disruptions {
+		for _, obj := range d.ImpactedObjects {
+			for _, is := range obj.ImpactedStops {
+				for i := range diagram.Stops {
+					s := &diagram.Stops[i]
+					if (is.StopPoint.StopArea != nil && is.StopPoint.StopArea.ID == s.ID) || strings.EqualFold(is.StopPoint.Name, s.Name) {
+						s.Disrupted = true
+						if s.Cause == "" {
+							s.Cause = is.Cause
+						}
+					}
+				}
+			}
+		}
+	}
+	return diagram
+}
+
+// containedInOther reports whether routes[i]'s stops all belong to
+// another route with more stops, or to an identical earlier one.
+func containedInOther(routes []RouteStops, i int) bool {
+	for j, other := range routes {
+		if j == i || len(other.Stops) < len(routes[i].Stops) {
+			continue
+		}
+		if len(other.Stops) == len(routes[i].Stops) && j > i {
+			continue
+		}
+		ids := make(map[string]bool, len(other.Stops))
+		for _, s := range other.Stops {
+			ids[s.ID] = true
+		}
+		all := true
+		for _, s := range routes[i].Stops {
+			if !ids[s.ID] {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeStops merges the branches' stops into one order. A branch's stops
+// missing so far go before its next stop already placed, or at the end,
+// so each branch's own stops stay together.
+func mergeStops(branches []RouteStops) []model.StopArea {
+	var order []model.StopArea
+	for _, b := range branches {
+		var pending []model.StopArea
+		for _, s := range b.Stops {
+			p := slices.IndexFunc(order, func(o model.StopArea) bool { return o.ID == s.ID })
+			if p < 0 {
+				pending = append(pending, s)
+				continue
+			}
+			order = slices.Insert(order, p, pending...)
+			pending = nil
+		}
+		order = append(order, pending...)
+	}
+	return order
+}
+
+// connectionRank orders connections metro first, then RER, tram and others.
+func connectionRank(label string) int {
+	mode, _ := model.ParseLineLabel(label)
+	switch mode {
+	case "metro":
+		return 0
+	case "rer":
+		return 1
+	case "tram":
+		return 2
+	default:
+		return 3
+	}
+}
+
+// Line prints a line's branches and a diagram of its stops: one column
+// per branch, connections after each stop, and disrupted stops marked.
+func Line(w io.Writer, d LineDiagram) {
+	fmt.Fprintf(w, "%s%s%s", bold, d.Label, reset)
+	if d.Line.Name != d.Label {
+		fmt.Fprintf(w, "  %s", d.Line.Name)
+	}
+	fmt.Fprintln(w)
+	for _, dis := range d.Disruptions {
+		fmt.Fprintf(w, "%s  %s\n", formatSeverity(dis.Severity), truncate(DisruptionMessage(*dis), 70))
+	}
+	if len(d.Stops) == 0 {
+		fmt.Fprintf(w, "\n%sNo stops found.%s\n", dim, reset)
+		return
+	}
+
+	if len(d.Branches) > 1 {
+		fmt.Fprintf(w, "\n%sBranches%s\n", bold, reset)
+		for i, b := range d.Branches {
+			fmt.Fprintf(w, "  %d  %s → %s  %s(%d stops)%s\n", i+1, b.From, b.To, dim, b.Stops, reset)
+		}
+	}
+
+	nameWidth := 0
+	for _, s := range d.Stops {
+		nameWidth = max(nameWidth, VisibleWidth(s.Name))
+	}
+	fmt.Fprintln(w)
+	if len(d.Branches) > 1 {
+		var cols []string
+		for i := range d.Branches {
+			cols = append(cols, strconv.Itoa(i+1))
+		}
+		fmt.Fprintf(w, "  %s%s%s\n", dim, strings.Join(cols, " "), reset)
+	}
+	for _, s := range d.Stops {
+		var cells []string
+		for _, served := range s.Served {
+			if served {
+				cells = append(cells, "●")
+			} else {
+				cells = append(cells, " ")
+			}
+		}
+		name := FitWidth(s.Name, nameWidth)
+		suffix := ""
+		if s.Disrupted {
+			name = red + name + reset
+			cause := s.Cause
+			if cause == "" {
+				cause = "disrupted"
+			}
+			suffix = "  " + yellow + "⚠ " + cause + reset
+		}
+		conns := ""
+		if len(s.Connections) > 0 {
+			conns = "  " + dim + strings.Join(s.Connections, " ") + reset
+		}
+		row := fmt.Sprintf("  %s  %s%s%s", strings.Join(cells, " "), name, conns, suffix)
+		fmt.Fprintln(w, strings.TrimRight(row, " "))
+	}
+}
+
+// LineStopRecord is one stop of a line in structured output, in diagram
+// order. Branches are numbered from 1.
+type LineStopRecord struct {
+	Line        string   `json:"line" yaml:"line"`
+	StopID      string   `json:"stop_id" yaml:"stop_id"`
+	Stop        string   `json:"stop" yaml:"stop"`
+	Branches    []int    `json:"branches" yaml:"branches"`
+	Connections []string `json:"connections" yaml:"connections"`
+	Disrupted   bool     `json:"disrupted" yaml:"disrupted"`
+	Cause       string   `json:"cause,omitempty" yaml:"cause,omitempty"`
+}
+
+func (LineStopRecord) csvHeader() []string {
+	return []string{"line", "stop_id", "stop", "branches", "connections", "disrupted", "cause"}
+}
+
+func (r LineStopRecord) csvRow() []string {
+	branches := make([]string, len(r.Branches))
+	for i, b := range r.Branches {
+		branches[i] = strconv.Itoa(b)
+	}
+	return []string{r.Line, r.StopID, r.Stop, strings.Join(branches, ";"), strings.Join(r.Connections, ";"),
+		strconv.FormatBool(r.Disrupted), r.Cause}
+}
+
+// LineStopRecords mirrors Line as structured records.
+func LineStopRecords(d LineDiagram) []LineStopRecord {
+	recs := make([]LineStopRecord, 0, len(d.Stops))
+	for _, s := range d.Stops {
+		r := LineStopRecord{Line: d.Label, StopID: s.ID, Stop: s.Name, Branches: []int{}, Connections: s.Connections,
+			Disrupted: s.Disrupted, Cause: s.Cause}
+		if r.Connections == nil {
+			r.Connections = []string{}
+		}
+		for b, served := range s.Served {
+			if served {
+				r.Branches = append(r.Branches, b+1)
+			}
+		}
+		recs = append(recs, r)
+	}
+	return recs
+}
@@ -0,0 +1,86 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+func TestNewLineDiagram(t *testing.T) {
+	route := func(names ...string) RouteStops {
+		var r RouteStops
+		for _, n := range names {
+			r.Stops = append(r.Stops, model.StopArea{ID: "sa:" + n, Name: n})
+		}
+		return r
+	}
+	// M13-like: a trunk forking at La Fourche, and a short run
+	routes := []RouteStops{
+		route("Châtillon", "Montparnasse", "La Fourche", "Guy Môquet", "Saint-Denis"),
+		route("Châtillon", "Montparnasse", "La Fourche", "Brochant", "Asnières"),
+		route("Montparnasse", "La Fourche"),
+	}
+	line := model.Line{ID: "line:13", Code: "13"}
+	metro := &model.Mode{Name: "Métro"}
+	stopAreas := []model.StopArea{{ID: "sa:Montparnasse", Lines: []model.Line{
+		line,
+		{ID: "line:T", Code: "N", CommercialMode: &model.Mode{Name: "Transilien"}},
+		{ID: "line:6", Code: "6", CommercialMode: metro},
+		{ID: "line:4", Code: "4", CommercialMode: metro},
+	}}}
+	disruptions := []model.Disruption{{ID: "d1", Status: "active", ImpactedObjects: []model.ImpactedObject{{
+		PTObject:      model.PTObject{ID: "line:13"},
+		ImpactedStops: []model.ImpactedStop{{StopPoint: model.StopPoint{Name: "Brochant"}, Cause: "Travaux"}},
+	}}}}
+
+	d := NewLineDiagram("M13", line, routes, stopAreas, disruptions)
+	if len(d.Branches) != 2 {
+		t.Fatalf("branches = %+v, want 2 (short run dropped)", d.Branches)
+	}
+	var names []string
+	for _, s := range d.Stops {
+		names = append(names, s.Name)
+	}
+	if got := strings.Join(names, ","); got != "Châtillon,Montparnasse,La Fourche,Guy Môquet,Saint-Denis,Brochant,Asnières" {
+		t.Errorf("stops = %s", got)
+	}
+	if got := strings.Join(d.Stops[1].Connections, " "); got != "M6 M4 N" {
+		t.Errorf("connections = %q", got)
+	}
+	if s := d.Stops[5]; !s.Disrupted || s.Cause != "Travaux" || s.Served[0] || !s.Served[1] {
+		t.Errorf("Brochant = %+v", s)
+	}
+
+	var buf bytes.Buffer
+	Line(&buf, d)
+	for _, want := range []string{"1  Châtillon → Saint-Denis", "2  Châtillon → Asnières", "● ●  Châtillon", "  ●  " + red + "Brochant", "⚠ Travaux"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	recs := LineStopRecords(d)
+	if len(recs) != 7 || len(recs[0].Branches) != 2 || !recs[5].Disrupted {
+		t.Errorf("LineStopRecords() = %+v", recs)
+	}
+}
+
+func TestMergeStopsBranchesAtBothEnds(t *testing.T) {
+	route := func(ids ...string) RouteStops {
+		var r RouteStops
+		for _, id := range ids {
+			r.Stops = append(r.Stops, model.StopArea{ID: id})
+		}
+		return r
+	}
+	order := mergeStops([]RouteStops{route("a1", "a2", "x", "y", "b1"), route("c1", "x", "y", "d1", "d2")})
+	var ids []string
+	for _, s := range order {
+		ids = append(ids, s.ID)
+	}
+	if got := strings.Join(ids, ","); got != "a1,a2,c1,x,y,b1,d1,d2" {
+		t.Errorf("mergeStops() = %s", got)
+	}
+}
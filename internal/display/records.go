@@ -0,0 +1,320 @@
+package display
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+	"go.yaml.in/yaml/v3"
+)
+
+// Format is an output format selected with --output.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+	FormatCSV   Format = "csv"
+)
+
+// FormatNames lists the accepted --output values for help text.
+var FormatNames = []string{"table", "json", "yaml", "csv"}
+
+// ParseFormat validates an --output value.
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, name := range FormatNames {
+		if s == name {
+			return Format(s), nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q (valid: %s)", s, strings.Join(FormatNames, ", "))
+}
+
+// IsStructured returns true for machine-readable formats (everything but table).
+func (f Format) IsStructured() bool {
+	return f != FormatTable && f != ""
+}
+
+// Record is a flat row of structured output. The CSV methods keep column
+// order explicit so the schema doesn't depend on struct field order.
+type Record interface {
+	csvHeader() []string
+	csvRow() []string
+}
+
+// WriteRecords encodes records as a JSON array, a YAML sequence or CSV
+// with a header row. An empty result is written as [] (or a bare header).
+func WriteRecords[T Record](w io.Writer, f Format, recs []T) error {
+	if recs == nil {
+		recs = []T{}
+	}
+	switch f {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(recs)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(recs); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		var zero T
+		if err := cw.Write(zero.csvHeader()); err != nil {
+			return err
+		}
+		for _, r := range recs {
+			if err := cw.Write(r.csvRow()); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("format %q is not a structured output format", f)
+	}
+}
+
+// DepartureRecord is one departure in structured output.
+type DepartureRecord struct {
+	StopID        string `json:"stop_id" yaml:"stop_id"`
+	StopName      string `json:"stop_name" yaml:"stop_name"`
+	Line          string `json:"line" yaml:"line"`
+	Mode          string `json:"mode" yaml:"mode"`
+	Direction     string `json:"direction" yaml:"direction"`
+	Minutes       int    `json:"minutes" yaml:"minutes"`
+	Time          string `json:"time" yaml:"time"`
+	ScheduledTime string `json:"scheduled_time" yaml:"scheduled_time"`
+	Delay         int    `json:"delay_minutes" yaml:"delay_minutes"`
+	Realtime      bool   `json:"realtime" yaml:"realtime"`
+	Severity      string `json:"disruption_severity" yaml:"disruption_severity"`
+}
+
+func (DepartureRecord) csvHeader() []string {
+	return []string{"stop_id", "stop_name", "line", "mode", "direction", "minutes", "time",
+		"scheduled_time", "delay_minutes", "realtime", "disruption_severity"}
+}
+
+func (r DepartureRecord) csvRow() []string {
+	return []string{r.StopID, r.StopName, r.Line, r.Mode, r.Direction,
+		strconv.Itoa(r.Minutes), r.Time, r.ScheduledTime, strconv.Itoa(r.Delay),
+		strconv.FormatBool(r.Realtime), r.Severity}
+}
+
+// DepartureRecords flattens departures at a stop into structured records.
+// Unlike the table, every departure is kept (no per-direction cap).
+// Minutes count from now.
+func DepartureRecords(stopID, stopName string, deps []model.Departure, disruptions []model.Disruption, now time.Time) []DepartureRecord {
+	worst := worstEffectByLine(disruptions)
+	recs := make([]DepartureRecord, 0, len(deps))
+	for _, d := range deps {
+		t, err := ParseNavitiaTime(d.StopDateTime.DepartureDateTime)
+		if err != nil {
+			continue
+		}
+		var severity string
+		if d.Route.Line != nil {
+			severity = worst[d.Route.Line.ID]
+		}
+		scheduled := t
+		if base, err := ParseNavitiaTime(d.StopDateTime.BaseDateTime); err == nil {
+			scheduled = base
+		}
+		delay, realtime := DepartureDelay(d.StopDateTime)
+		recs = append(recs, DepartureRecord{
+			StopID:        stopID,
+			StopName:      stopName,
+			Line:          model.LineLabel(d.DisplayInformations.Code, d.DisplayInformations.CommercialMode),
+			Mode:          modeName(d.DisplayInformations.CommercialMode),
+			Direction:     d.DisplayInformations.Direction,
+			Minutes:       minutesFrom(t, now),
+			Time:          t.Format(time.RFC3339),
+			ScheduledTime: scheduled.Format(time.RFC3339),
+			Delay:         delay,
+			Realtime:      realtime,
+			Severity:      severity,
+		})
+	}
+	return recs
+}
+
+// LineStatusRecord is one line's status in structured output. A line with
+// several active disruptions produces one record per disruption.
+type LineStatusRecord struct {
+	Line         string `json:"line" yaml:"line"`
+	Mode         string `json:"mode" yaml:"mode"`
+	Status       string `json:"status" yaml:"status"` // "ok" or "disrupted"
+	Severity     string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	SeverityName string `json:"severity_name,omitempty" yaml:"severity_name,omitempty"`
+	DisruptionID string `json:"disruption_id,omitempty" yaml:"disruption_id,omitempty"`
+	Message      string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+func (LineStatusRecord) csvHeader() []string {
+	return []string{"line", "mode", "status", "severity", "severity_name", "disruption_id", "message"}
+}
+
+func (r LineStatusRecord) csvRow() []string {
+	return []string{r.Line, r.Mode, r.Status, r.Severity, r.SeverityName, r.DisruptionID, r.Message}
+}
+
+// LineStatusRecords mirrors DisruptionsSummary as structured records.
+func LineStatusRecords(resp *model.LinesResponse, filterLine string, mode model.TransportMode) []LineStatusRecord {
+	if resp == nil {
+		return nil
+	}
+	lineDisruptions := activeDisruptionsByLine(resp.Disruptions)
+
+	var recs []LineStatusRecord
+	for _, line := range resp.Lines {
+		label := mode.Prefix + line.Code
+		if filterLine != "" && !matchesLineFilter(line.Code, label, filterLine) {
+			continue
+		}
+		disruptions := lineDisruptions[line.ID]
+		if len(disruptions) == 0 {
+			recs = append(recs, LineStatusRecord{Line: label, Mode: mode.Name, Status: "ok"})
+			continue
+		}
+		for _, d := range disruptions {
+			recs = append(recs, LineStatusRecord{
+				Line:         label,
+				Mode:         mode.Name,
+				Status:       "disrupted",
+				Severity:     d.Severity.Effect,
+				SeverityName: d.Severity.Name,
+				DisruptionID: d.DisruptionID,
+				Message:      DisruptionMessage(*d),
+			})
+		}
+	}
+	return recs
+}
+
+// PlaceRecord is one saved place in structured output.
+type PlaceRecord struct {
+	Alias   string  `json:"alias" yaml:"alias"`
+	Name    string  `json:"name" yaml:"name"`
+	Type    string  `json:"type" yaml:"type"`
+	ID      string  `json:"id,omitempty" yaml:"id,omitempty"`
+	City    string  `json:"city,omitempty" yaml:"city,omitempty"`
+	Lat     float64 `json:"lat,omitempty" yaml:"lat,omitempty"`
+	Lon     float64 `json:"lon,omitempty" yaml:"lon,omitempty"`
+	Default bool    `json:"default" yaml:"default"`
+
+	Modes       []string `json:"modes,omitempty" yaml:"modes,omitempty"`
+	Lines       []string `json:"lines,omitempty" yaml:"lines,omitempty"`
+	Direction   string   `json:"direction,omitempty" yaml:"direction,omitempty"`
+	Radius      int      `json:"radius,omitempty" yaml:"radius,omitempty"`
+	WalkMinutes int      `json:"walk_minutes,omitempty" yaml:"walk_minutes,omitempty"`
+}
+
+func (PlaceRecord) csvHeader() []string {
+	return []string{"alias", "name", "type", "id", "city", "lat", "lon", "default", "modes", "lines", "direction", "radius", "walk_minutes"}
+}
+
+func (r PlaceRecord) csvRow() []string {
+	return []string{r.Alias, r.Name, r.Type, r.ID, r.City,
+		formatCoord(r.Lat), formatCoord(r.Lon), strconv.FormatBool(r.Default),
+		strings.Join(r.Modes, ";"), strings.Join(r.Lines, ";"), r.Direction, formatCount(r.Radius), formatCount(r.WalkMinutes)}
+}
+
+// formatCount formats an optional setting, empty when unset.
+func formatCount(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func formatCoord(f float64) string {
+	if f == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'f', 6, 64)
+}
+
+// minutesFrom returns whole minutes from now until t, rounded, never negative.
+func minutesFrom(t, now time.Time) int {
+	mins := int(math.Round(t.Sub(now).Minutes()))
+	if mins < 0 {
+		return 0
+	}
+	return mins
+}
+
+// modeName maps a Navitia commercial mode to the user-facing mode name
+// used by --mode ("metro", "rer", ...). Unknown modes are lowercased.
+func modeName(commercialMode string) string {
+	switch strings.ToLower(commercialMode) {
+	case "metro", "métro":
+		return "metro"
+	case "rer":
+		return "rer"
+	case "train", "localtrain", "transilien":
+		return "train"
+	case "tramway":
+		return "tram"
+	case "bus":
+		return "bus"
+	default:
+		return strings.ToLower(commercialMode)
+	}
+}
+
+// activeDisruptionsByLine indexes active disruptions by impacted object ID.
+func activeDisruptionsByLine(disruptions []model.Disruption) map[string][]*model.Disruption {
+	byLine := make(map[string][]*model.Disruption)
+	for i := range disruptions {
+		d := &disruptions[i]
+		if d.Status != "active" {
+			continue
+		}
+		for _, obj := range d.ImpactedObjects {
+			byLine[obj.PTObject.ID] = append(byLine[obj.PTObject.ID], d)
+		}
+	}
+	return byLine
+}
+
+// worstEffectByLine returns the most severe active effect for each impacted line.
+func worstEffectByLine(disruptions []model.Disruption) map[string]string {
+	worst := make(map[string]string)
+	for id, ds := range activeDisruptionsByLine(disruptions) {
+		for _, d := range ds {
+			if cur, ok := worst[id]; !ok || effectRank(d.Severity.Effect) > effectRank(cur) {
+				worst[id] = d.Severity.Effect
+			}
+		}
+	}
+	return worst
+}
+
+// effectRank orders disruption effects from harmless (0) to no service.
+func effectRank(effect string) int {
+	switch effect {
+	case "NO_SERVICE":
+		return 5
+	case "REDUCED_SERVICE":
+		return 4
+	case "SIGNIFICANT_DELAYS":
+		return 3
+	case "MODIFIED_SERVICE":
+		return 2
+	case "ADDITIONAL_SERVICE", "UNKNOWN_EFFECT":
+		return 0
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,170 @@
+package display
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{"table", FormatTable, false},
+		{"json", FormatJSON, false},
+		{"YAML", FormatYAML, false},
+		{" csv ", FormatCSV, false},
+		{"xml", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseFormat(%q) expected error, got %q", tt.input, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func testDepartures() ([]model.Departure, []model.Disruption) {
+	when := time.Now().Add(5*time.Minute + 10*time.Second).In(paris)
+	deps := []model.Departure{{
+		DisplayInformations: model.DisplayInfo{Code: "1", CommercialMode: "Métro", Direction: "La Défense"},
+		StopDateTime: model.StopDateTime{
+			DepartureDateTime: when.Format("20060102T150405"),
+			BaseDateTime:      when.Add(-3 * time.Minute).Format("20060102T150405"),
+			DataFreshness:     "realtime",
+		},
+		Route: model.Route{Line: &model.Line{ID: "line:M1"}},
+	}}
+	disruptions := []model.Disruption{
+		{ID: "d1", Status: "active", Severity: model.Severity{Effect: "SIGNIFICANT_DELAYS"},
+			ImpactedObjects: []model.ImpactedObject{{PTObject: model.PTObject{ID: "line:M1"}}}},
+		{ID: "d2", Status: "active", Severity: model.Severity{Effect: "NO_SERVICE"},
+			ImpactedObjects: []model.ImpactedObject{{PTObject: model.PTObject{ID: "line:M1"}}}},
+		{ID: "d3", Status: "future", Severity: model.Severity{Effect: "NO_SERVICE"},
+			ImpactedObjects: []model.ImpactedObject{{PTObject: model.PTObject{ID: "line:M4"}}}},
+	}
+	return deps, disruptions
+}
+
+func TestDepartureRecords(t *testing.T) {
+	deps, disruptions := testDepartures()
+	recs := DepartureRecords("stop_area:1", "Châtelet", deps, disruptions, time.Now())
+	if len(recs) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(recs))
+	}
+	r := recs[0]
+	if r.Line != "M1" || r.Mode != "metro" || r.Direction != "La Défense" {
+		t.Errorf("unexpected line/mode/direction: %+v", r)
+	}
+	if r.Minutes != 5 {
+		t.Errorf("expected 5 minutes, got %d", r.Minutes)
+	}
+	if !r.Realtime {
+		t.Error("expected realtime = true")
+	}
+	if r.Delay != 3 {
+		t.Errorf("expected 3 min delay, got %d", r.Delay)
+	}
+	if r.Severity != "NO_SERVICE" {
+		t.Errorf("expected worst severity NO_SERVICE, got %q", r.Severity)
+	}
+	tm, err := time.Parse(time.RFC3339, r.Time)
+	if err != nil {
+		t.Errorf("time %q is not RFC3339: %v", r.Time, err)
+	}
+	if sched, err := time.Parse(time.RFC3339, r.ScheduledTime); err != nil || tm.Sub(sched) != 3*time.Minute {
+		t.Errorf("scheduled time %q should be 3 min before %q", r.ScheduledTime, r.Time)
+	}
+}
+
+func TestWriteRecordsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteRecords[DepartureRecord](&buf, FormatJSON, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("expected empty JSON array, got %q", buf.String())
+	}
+
+	buf.Reset()
+	deps, disruptions := testDepartures()
+	if err := WriteRecords(&buf, FormatJSON, DepartureRecords("s", "Stop", deps, disruptions, time.Now())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded[0]["disruption_severity"] != "NO_SERVICE" {
+		t.Errorf("unexpected record: %v", decoded[0])
+	}
+}
+
+func TestWriteRecordsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	recs := []PlaceRecord{
+		{Alias: "home", Name: "Châtelet, Paris", Type: "StopArea", Default: true},
+		{Alias: "work", Name: "La Défense", Type: "StopArea", Lines: []string{"RER A", "M1"}, WalkMinutes: 6},
+	}
+	if err := WriteRecords(&buf, FormatCSV, recs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "alias,name,type,id,city,lat,lon,default,modes,lines,direction,radius,walk_minutes\n" +
+		"home,\"Châtelet, Paris\",StopArea,,,,,true,,,,,\n" +
+		"work,La Défense,StopArea,,,,,false,,RER A;M1,,,6\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteRecordsYAML(t *testing.T) {
+	var buf bytes.Buffer
+	recs := []LineStatusRecord{{Line: "M14", Mode: "metro", Status: "ok"}}
+	if err := WriteRecords(&buf, FormatYAML, recs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "- line: M14\n  mode: metro\n  status: ok\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestLineStatusRecords(t *testing.T) {
+	resp := &model.LinesResponse{
+		Lines: []model.Line{{ID: "line:M1", Code: "1"}, {ID: "line:M4", Code: "4"}},
+		Disruptions: []model.Disruption{{
+			ID: "d1", DisruptionID: "dis-1", Status: "active",
+			Severity:        model.Severity{Effect: "NO_SERVICE", Name: "blocking"},
+			Messages:        []model.Message{{Text: "Trafic interrompu", Channel: model.Channel{ContentType: "text/plain"}}},
+			ImpactedObjects: []model.ImpactedObject{{PTObject: model.PTObject{ID: "line:M1"}}},
+		}},
+	}
+	recs := LineStatusRecords(resp, "", model.Modes["metro"])
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(recs))
+	}
+	if recs[0].Line != "M1" || recs[0].Status != "disrupted" || recs[0].DisruptionID != "dis-1" || recs[0].Message != "Trafic interrompu" {
+		t.Errorf("unexpected disrupted record: %+v", recs[0])
+	}
+	if recs[1].Line != "M4" || recs[1].Status != "ok" {
+		t.Errorf("unexpected ok record: %+v", recs[1])
+	}
+
+	recs = LineStatusRecords(resp, "M4", model.Modes["metro"])
+	if len(recs) != 1 || recs[0].Line != "M4" {
+		t.Errorf("line filter not applied: %+v", recs)
+	}
+}
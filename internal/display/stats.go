@@ -0,0 +1,143 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/stats"
+)
+
+// StatsReport is the punctuality of sampled departures over a period.
+type StatsReport struct {
+	Since, Until time.Time
+	Slack        time.Duration // how late a departure may be and still count as on time
+	Departures   int
+	Lines        []stats.Group // by line and direction
+	WorstHours   []stats.Group // by hour of the week
+	Slots        []stats.Group // by scheduled time of day, if requested
+}
+
+// Stats prints a punctuality report.
+func Stats(w io.Writer, r StatsReport) {
+	fmt.Fprintf(w, "%sPunctuality since %s%s %s(%s, %d departures; on time: at most %s late)%s\n\n", bold,
+		r.Since.In(paris).Format("Mon 2 Jan 2006"), reset, dim, formatDays(r.Until.Sub(r.Since)), r.Departures,
+		formatSlack(r.Slack), reset)
+
+	if r.Departures == 0 {
+		fmt.Fprintf(w, "%sNo departures recorded.%s\n", dim, reset)
+		return
+	}
+
+	lineWidth, dirWidth := len("Line"), len("Direction")
+	for _, g := range r.Lines {
+		lineWidth = max(lineWidth, VisibleWidth(g.Line))
+		dirWidth = max(dirWidth, VisibleWidth(g.Direction))
+	}
+	dirWidth = min(dirWidth, 30)
+	fmt.Fprintf(w, "%s%-*s  %-*s  %10s  %6s  %4s  %7s%s\n", bold, lineWidth, "Line", dirWidth, "Direction",
+		"Departures", "Median", "p90", "On time", reset)
+	for _, g := range r.Lines {
+		fmt.Fprintf(w, "%s%-*s%s  %s  %10d  %6s  %4s  %s\n", bold, lineWidth, g.Line, reset,
+			FitWidth(g.Direction, dirWidth), g.Departures, formatDelayMinutes(g.Median), formatDelayMinutes(g.P90),
+			formatOnTime(g.OnTime, 7))
+	}
+
+	if len(r.WorstHours) > 0 {
+		fmt.Fprintf(w, "\n%sWorst hours of the week%s\n", bold, reset)
+		for _, g := range r.WorstHours {
+			fmt.Fprintf(w, "  %s %02d:00  %s on time  %sp90 %s, %d departures%s\n",
+				g.Weekday.String()[:3], g.Hour, formatOnTime(g.OnTime, 4), dim,
+				formatDelayMinutes(g.P90), g.Departures, reset)
+		}
+	}
+
+	if len(r.Slots) > 0 {
+		fmt.Fprintf(w, "\n%sBy departure time%s\n", bold, reset)
+		var line, dir string
+		for _, g := range r.Slots {
+			if g.Line != line || g.Direction != dir {
+				line, dir = g.Line, g.Direction
+				fmt.Fprintf(w, "  %s%s%s → %s\n", bold, line, reset, dir)
+			}
+			fmt.Fprintf(w, "    %s  %s on time  %smedian %s, p90 %s, %d departures%s\n", g.Slot,
+				formatOnTime(g.OnTime, 4), dim, formatDelayMinutes(g.Median), formatDelayMinutes(g.P90), g.Departures, reset)
+		}
+	}
+}
+
+// formatDelayMinutes returns a delay in whole minutes: "+2", "0" or "-1".
+func formatDelayMinutes(d time.Duration) string {
+	m := int(math.Round(d.Minutes()))
+	if m > 0 {
+		return "+" + strconv.Itoa(m)
+	}
+	return strconv.Itoa(m)
+}
+
+// formatOnTime returns a colored percentage right-aligned in width:
+// green from 90%, yellow from 75%, red below.
+func formatOnTime(share float64, width int) string {
+	pct := fmt.Sprintf("%*d%%", width-1, int(math.Round(share*100)))
+	switch {
+	case share >= 0.9:
+		return green + pct + reset
+	case share >= 0.75:
+		return yellow + pct + reset
+	default:
+		return red + pct + reset
+	}
+}
+
+func formatSlack(d time.Duration) string {
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%d min", int(d.Minutes()))
+	}
+	return d.String()
+}
+
+// StatsRecord is one line and direction's punctuality in structured
+// output. Delays are in minutes.
+type StatsRecord struct {
+	Line        string  `json:"line" yaml:"line"`
+	Direction   string  `json:"direction" yaml:"direction"`
+	Departures  int     `json:"departures" yaml:"departures"`
+	MedianDelay float64 `json:"median_delay_minutes" yaml:"median_delay_minutes"`
+	P90Delay    float64 `json:"p90_delay_minutes" yaml:"p90_delay_minutes"`
+	OnTimeShare float64 `json:"on_time_share" yaml:"on_time_share"`
+	ScheduledAt string  `json:"scheduled_at,omitempty" yaml:"scheduled_at,omitempty"` // time slot, e.g. "08:10"
+}
+
+func (StatsRecord) csvHeader() []string {
+	return []string{"line", "direction", "departures", "median_delay_minutes", "p90_delay_minutes", "on_time_share", "scheduled_at"}
+}
+
+func (r StatsRecord) csvRow() []string {
+	return []string{r.Line, r.Direction, strconv.Itoa(r.Departures),
+		strconv.FormatFloat(r.MedianDelay, 'f', -1, 64), strconv.FormatFloat(r.P90Delay, 'f', -1, 64),
+		strconv.FormatFloat(r.OnTimeShare, 'f', 3, 64), r.ScheduledAt}
+}
+
+// StatsRecords flattens line groups, or time slot groups if any, into
+// structured records.
+func StatsRecords(r StatsReport) []StatsRecord {
+	groups := r.Lines
+	if len(r.Slots) > 0 {
+		groups = r.Slots
+	}
+	recs := make([]StatsRecord, 0, len(groups))
+	for _, g := range groups {
+		recs = append(recs, StatsRecord{
+			Line:        g.Line,
+			Direction:   g.Direction,
+			Departures:  g.Departures,
+			MedianDelay: g.Median.Minutes(),
+			P90Delay:    g.P90.Minutes(),
+			OnTimeShare: math.Round(g.OnTime*1000) / 1000,
+			ScheduledAt: g.Slot,
+		})
+	}
+	return recs
+}
@@ -0,0 +1,47 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/stats"
+)
+
+func TestStats(t *testing.T) {
+	r := StatsReport{
+		Since:      time.Date(2026, 3, 1, 0, 0, 0, 0, paris),
+		Until:      time.Date(2026, 3, 31, 0, 0, 0, 0, paris),
+		Slack:      time.Minute,
+		Departures: 40,
+		Lines: []stats.Group{
+			{Line: "RER B", Direction: "Aéroport CDG 2", Punctuality: stats.Punctuality{Departures: 40, Median: 90 * time.Second, P90: 6 * time.Minute, OnTime: 0.675}},
+		},
+		WorstHours: []stats.Group{
+			{Weekday: time.Monday, Hour: 8, Punctuality: stats.Punctuality{Departures: 12, P90: 7 * time.Minute, OnTime: 0.5}},
+		},
+	}
+	var buf bytes.Buffer
+	Stats(&buf, r)
+	out := buf.String()
+	for _, want := range []string{"Punctuality since Sun 1 Mar 2026", "30 days, 40 departures; on time: at most 1 min late",
+		"Aéroport CDG 2", "+2    +6  " + red + "    68%", "Mon 08:00  " + red + " 50%" + reset + " on time", "p90 +7, 12 departures"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	recs := StatsRecords(r)
+	if len(recs) != 1 || recs[0].MedianDelay != 1.5 || recs[0].P90Delay != 6 || recs[0].OnTimeShare != 0.675 {
+		t.Errorf("records = %+v", recs)
+	}
+}
+
+func TestFormatDelayMinutes(t *testing.T) {
+	for d, want := range map[time.Duration]string{0: "0", 2 * time.Minute: "+2", -time.Minute: "-1", 29 * time.Second: "0"} {
+		if got := formatDelayMinutes(d); got != want {
+			t.Errorf("formatDelayMinutes(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -2,8 +2,9 @@ package display
 
 import (
 	"fmt"
+	"io"
 	"math"
-	"os"
+	"slices"
 	"sort"
 	"strings"
 	"text/tabwriter"
@@ -32,15 +33,31 @@ var paris = func() *time.Location {
 	return loc
 }()
 
+// Paris returns the Europe/Paris timezone used for all Navitia times.
+func Paris() *time.Location {
+	return paris
+}
+
 // ParseNavitiaTime parses "20260225T143000" into time.Time.
 // Navitia always returns times in Europe/Paris local time.
 func ParseNavitiaTime(s string) (time.Time, error) {
 	return time.ParseInLocation("20060102T150405", s, paris)
 }
 
+// FormatNavitiaTime formats t as a Navitia local time ("20260225T143000").
+func FormatNavitiaTime(t time.Time) string {
+	return t.In(paris).Format("20060102T150405")
+}
+
 // FormatMinutesUntil returns "2 min", "now", "~2h30", etc.
 func FormatMinutesUntil(t time.Time) string {
-	diff := time.Until(t)
+	return FormatMinutesFrom(t, time.Now())
+}
+
+// FormatMinutesFrom is like FormatMinutesUntil, counting from now rather
+// than the current time (e.g. for departures at a planned time).
+func FormatMinutesFrom(t, now time.Time) string {
+	diff := t.Sub(now)
 	mins := int(math.Round(diff.Minutes()))
 	if mins <= 0 {
 		return green + "now" + reset
@@ -56,6 +73,39 @@ func FormatMinutesUntil(t time.Time) string {
 	return fmt.Sprintf("%s%d min%s", cyan, mins, reset)
 }
 
+// DepartureDelay returns how many minutes a departure runs late (negative
+// if early) versus its base schedule. realtime is false when the time
+// comes from the base schedule, in which case delay is 0.
+func DepartureDelay(sdt model.StopDateTime) (delay int, realtime bool) {
+	if sdt.DataFreshness != "realtime" {
+		return 0, false
+	}
+	t, err := ParseNavitiaTime(sdt.DepartureDateTime)
+	if err != nil {
+		return 0, true
+	}
+	base, err := ParseNavitiaTime(sdt.BaseDateTime)
+	if err != nil {
+		return 0, true
+	}
+	return int(math.Round(t.Sub(base).Minutes())), true
+}
+
+// formatDelay returns " (+3)" for a late departure, " (-1)" for an early
+// one, or "" when on time.
+func formatDelay(delay int) string {
+	switch {
+	case delay >= 5:
+		return fmt.Sprintf(" %s(+%d)%s", red, delay, reset)
+	case delay > 0:
+		return fmt.Sprintf(" %s(+%d)%s", yellow, delay, reset)
+	case delay < 0:
+		return fmt.Sprintf(" %s(%d)%s", dim, delay, reset)
+	default:
+		return ""
+	}
+}
+
 // truncate safely truncates a string to maxRunes, appending "..." if needed.
 // Uses rune-level slicing so multi-byte UTF-8 characters are not split.
 func truncate(s string, maxRunes int) string {
@@ -72,24 +122,29 @@ func lineLabel(code, commercialMode string) string {
 	return fmt.Sprintf("%s%s%s", bold, label, reset)
 }
 
-// Departures prints next departures grouped by line+direction, followed
-// by any active disruptions affecting the displayed lines.
-func Departures(deps []model.Departure, disruptions []model.Disruption, showMode bool) {
-	if len(deps) == 0 {
-		fmt.Printf("  %s(no upcoming departures)%s\n", dim, reset)
-		return
-	}
+// maxTimesPerGroup is how many departures a board shows per line and
+// direction.
+const maxTimesPerGroup = 3
+
+// DepartureGroup is the departures of one line and direction at a stop.
+type DepartureGroup struct {
+	Code           string
+	CommercialMode string
+	Direction      string
+	Departures     []model.Departure
+}
 
+// GroupDepartures groups departures by line+direction, keeping at most
+// max per group (0 for all). Groups are sorted by transport type (metro
+// first, then RER, train, tram, bus), then by line code within each type.
+func GroupDepartures(deps []model.Departure, max int) []DepartureGroup {
 	type key struct {
 		lineCode       string
 		commercialMode string
 		direction      string
 	}
-	type entry struct {
-		times []string
-	}
-	groups := make(map[key]*entry)
-	var order []key
+	index := make(map[key]int)
+	var groups []DepartureGroup
 
 	for _, d := range deps {
 		k := key{
@@ -97,55 +152,103 @@ func Departures(deps []model.Departure, disruptions []model.Disruption, showMode
 			commercialMode: d.DisplayInformations.CommercialMode,
 			direction:      d.DisplayInformations.Direction,
 		}
-		if _, ok := groups[k]; !ok {
-			groups[k] = &entry{}
-			order = append(order, k)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, DepartureGroup{Code: k.lineCode, CommercialMode: k.commercialMode, Direction: k.direction})
 		}
-		if len(groups[k].times) >= 3 {
+		if max > 0 && len(groups[i].Departures) >= max {
 			continue
 		}
-		t, err := ParseNavitiaTime(d.StopDateTime.DepartureDateTime)
-		if err != nil {
+		if _, err := ParseNavitiaTime(d.StopDateTime.DepartureDateTime); err != nil {
 			continue
 		}
-		groups[k].times = append(groups[k].times, FormatMinutesUntil(t))
+		groups[i].Departures = append(groups[i].Departures, d)
 	}
 
-	// Sort by transport type (metro first, then RER, train, tram, bus),
-	// then by line code within each type.
-	sort.SliceStable(order, func(i, j int) bool {
-		pi, pj := modePriority(order[i].commercialMode), modePriority(order[j].commercialMode)
+	sort.SliceStable(groups, func(i, j int) bool {
+		pi, pj := modePriority(groups[i].CommercialMode), modePriority(groups[j].CommercialMode)
 		if pi != pj {
 			return pi < pj
 		}
-		return order[i].lineCode < order[j].lineCode
+		return groups[i].Code < groups[j].Code
 	})
+	return groups
+}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintf(w, "  %sLine\tDirection\tNext departures%s\n", bold, reset)
-	fmt.Fprintf(w, "  %s----\t---------\t---------------%s\n", dim, reset)
+// FormatTimes returns the group's countdowns from now with delays, e.g.
+// "2 min (+2), 6 min". Times without realtime data are marked "scheduled":
+// once at the end when none is realtime, otherwise after each such time.
+func (g DepartureGroup) FormatTimes(now time.Time) string {
+	times := make([]string, len(g.Departures))
+	scheduled := make([]bool, len(g.Departures))
+	for i, d := range g.Departures {
+		t, _ := ParseNavitiaTime(d.StopDateTime.DepartureDateTime)
+		delay, realtime := DepartureDelay(d.StopDateTime)
+		times[i] = FormatMinutesFrom(t, now) + formatDelay(delay)
+		scheduled[i] = !realtime
+	}
 
-	for _, k := range order {
-		e := groups[k]
-		label := lineLabel(k.lineCode, k.commercialMode)
+	allScheduled := !slices.Contains(scheduled, false)
+	for i := range times {
+		if scheduled[i] && !allScheduled {
+			times[i] += fmt.Sprintf(" %sscheduled%s", dim, reset)
+		}
+	}
+	s := strings.Join(times, ", ")
+	if allScheduled {
+		s += fmt.Sprintf("  %sscheduled%s", dim, reset)
+	}
+	return s
+}
+
+// Departures prints next departures grouped by line+direction, followed
+// by any active disruptions affecting the displayed lines. Countdowns are
+// relative to now.
+func Departures(w io.Writer, deps []model.Departure, disruptions []model.Disruption, showMode bool, now time.Time) {
+	if len(deps) == 0 {
+		fmt.Fprintf(w, "  %s(no upcoming departures)%s\n", dim, reset)
+		return
+	}
 
-		dir := truncate(k.direction, 30)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "  %sLine\tDirection\tNext departures%s\n", bold, reset)
+	fmt.Fprintf(tw, "  %s----\t---------\t---------------%s\n", dim, reset)
 
-		timesStr := strings.Join(e.times, ", ")
-		fmt.Fprintf(w, "  %s\t%s\t%s\n", label, dir, timesStr)
+	for _, g := range GroupDepartures(deps, maxTimesPerGroup) {
+		label := lineLabel(g.Code, g.CommercialMode)
+		dir := truncate(g.Direction, 30)
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", label, dir, g.FormatTimes(now))
 	}
-	w.Flush()
+	tw.Flush()
 
 	// Show active disruptions affecting the displayed lines
-	showDepartureDisruptions(deps, disruptions)
+	showDepartureDisruptions(w, deps, disruptions)
 }
 
 // showDepartureDisruptions prints active disruptions for lines present in the departures.
-func showDepartureDisruptions(deps []model.Departure, disruptions []model.Disruption) {
-	if len(disruptions) == 0 {
+func showDepartureDisruptions(w io.Writer, deps []model.Departure, disruptions []model.Disruption) {
+	matches := DepartureDisruptions(deps, disruptions)
+	if len(matches) == 0 {
 		return
 	}
 
+	fmt.Fprintln(w)
+	for _, m := range matches {
+		severity := formatSeverity(m.Disruption.Severity)
+		msg := truncate(DisruptionMessage(*m.Disruption), 80)
+		fmt.Fprintf(w, "  %s!%s %s%s%s  %s  %s\n", yellow, reset, bold, m.Label, reset, severity, msg)
+	}
+}
+
+// DepartureDisruptions returns the active disruptions impacting lines
+// present in the departures, once each, labelled with the line.
+func DepartureDisruptions(deps []model.Departure, disruptions []model.Disruption) []LineDisruption {
+	if len(disruptions) == 0 {
+		return nil
+	}
+
 	// Collect line IDs seen in departures
 	lineIDs := make(map[string]bool)
 	for _, d := range deps {
@@ -155,18 +258,14 @@ func showDepartureDisruptions(deps []model.Departure, disruptions []model.Disrup
 	}
 
 	// Find active disruptions impacting those lines (deduplicate by disruption ID)
-	type match struct {
-		label      string
-		disruption *model.Disruption
-	}
 	seen := make(map[string]bool)
-	var matches []match
+	var matches []LineDisruption
 	for i, d := range disruptions {
 		if d.Status != "active" {
 			continue
 		}
-		for _, io := range d.ImpactedObjects {
-			if !lineIDs[io.PTObject.ID] {
+		for _, obj := range d.ImpactedObjects {
+			if !lineIDs[obj.PTObject.ID] {
 				continue
 			}
 			if seen[d.ID] {
@@ -174,58 +273,35 @@ func showDepartureDisruptions(deps []model.Departure, disruptions []model.Disrup
 			}
 			seen[d.ID] = true
 			// Build label from the impacted line name
-			label := io.PTObject.Name
+			label := obj.PTObject.Name
+			mode := ""
 			// Try to find a better label from departures
 			for _, dep := range deps {
-				if dep.Route.Line != nil && dep.Route.Line.ID == io.PTObject.ID {
+				if dep.Route.Line != nil && dep.Route.Line.ID == obj.PTObject.ID {
 					label = model.LineLabel(dep.DisplayInformations.Code, dep.DisplayInformations.CommercialMode)
+					mode = modeName(dep.DisplayInformations.CommercialMode)
 					break
 				}
 			}
-			matches = append(matches, match{label: label, disruption: &disruptions[i]})
+			matches = append(matches, LineDisruption{Label: label, Mode: mode, Disruption: &disruptions[i]})
 		}
 	}
-
-	if len(matches) == 0 {
-		return
-	}
-
-	fmt.Println()
-	for _, m := range matches {
-		severity := formatSeverity(m.disruption.Severity)
-		msg := truncate(extractMessage(*m.disruption), 80)
-		fmt.Printf("  %s!%s %s%s%s  %s  %s\n", yellow, reset, bold, m.label, reset, severity, msg)
-	}
+	return matches
 }
 
 // DisruptionsSummary prints disruption status for lines of a given mode.
-func DisruptionsSummary(resp *model.LinesResponse, filterLine string, mode model.TransportMode) {
+func DisruptionsSummary(w io.Writer, resp *model.LinesResponse, filterLine string, mode model.TransportMode) {
 	if resp == nil || len(resp.Lines) == 0 {
-		fmt.Printf("%sNo lines found.%s\n", dim, reset)
+		fmt.Fprintf(w, "%sNo lines found.%s\n", dim, reset)
 		return
 	}
 
-	// Index disruptions by ID
-	dmap := make(map[string]*model.Disruption)
-	for i := range resp.Disruptions {
-		d := &resp.Disruptions[i]
-		dmap[d.ID] = d
-	}
-
 	// Build map: line ID -> active disruptions
-	lineDisruptions := make(map[string][]*model.Disruption)
-	for _, d := range resp.Disruptions {
-		if d.Status != "active" {
-			continue
-		}
-		for _, io := range d.ImpactedObjects {
-			lineDisruptions[io.PTObject.ID] = append(lineDisruptions[io.PTObject.ID], dmap[d.ID])
-		}
-	}
+	lineDisruptions := activeDisruptionsByLine(resp.Disruptions)
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintf(w, "%sLine\tStatus\tInfo%s\n", bold, reset)
-	fmt.Fprintf(w, "%s----\t------\t----%s\n", dim, reset)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%sLine\tStatus\tInfo%s\n", bold, reset)
+	fmt.Fprintf(tw, "%s----\t------\t----%s\n", dim, reset)
 
 	for _, line := range resp.Lines {
 		code := line.Code
@@ -239,7 +315,7 @@ func DisruptionsSummary(resp *model.LinesResponse, filterLine string, mode model
 		disruptions := lineDisruptions[line.ID]
 
 		if len(disruptions) == 0 {
-			fmt.Fprintf(w, "%s\t%sOK%s\t\n", label, green, reset)
+			fmt.Fprintf(tw, "%s\t%sOK%s\t\n", label, green, reset)
 		} else {
 			for i, d := range disruptions {
 				prefix := label
@@ -247,12 +323,12 @@ func DisruptionsSummary(resp *model.LinesResponse, filterLine string, mode model
 					prefix = "      "
 				}
 				status := formatSeverity(d.Severity)
-				msg := truncate(extractMessage(*d), 70)
-				fmt.Fprintf(w, "%s\t%s\t%s\n", prefix, status, msg)
+				msg := truncate(DisruptionMessage(*d), 70)
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", prefix, status, msg)
 			}
 		}
 	}
-	w.Flush()
+	tw.Flush()
 }
 
 // modePriority returns a sort rank for a commercial mode name.
@@ -285,28 +361,51 @@ func matchesLineFilter(code, lineLabel, filter string) bool {
 }
 
 func formatSeverity(s model.Severity) string {
+	label := SeverityLabel(s)
+	switch s.Effect {
+	case "NO_SERVICE":
+		return red + label + reset
+	case "ADDITIONAL_SERVICE":
+		return green + label + reset
+	case "UNKNOWN_EFFECT":
+		return dim + label + reset
+	case "REDUCED_SERVICE", "SIGNIFICANT_DELAYS", "MODIFIED_SERVICE":
+		return yellow + label + reset
+	default:
+		if s.Name != "" {
+			return yellow + label + reset
+		}
+		return dim + label + reset
+	}
+}
+
+// SeverityLabel returns a short name for a disruption's effect, e.g.
+// "Interrupted" or "Delays".
+func SeverityLabel(s model.Severity) string {
 	switch s.Effect {
 	case "NO_SERVICE":
-		return red + "Interrupted" + reset
+		return "Interrupted"
 	case "REDUCED_SERVICE":
-		return yellow + "Reduced" + reset
+		return "Reduced"
 	case "SIGNIFICANT_DELAYS":
-		return yellow + "Delays" + reset
+		return "Delays"
 	case "MODIFIED_SERVICE":
-		return yellow + "Modified" + reset
+		return "Modified"
 	case "ADDITIONAL_SERVICE":
-		return green + "Extra" + reset
+		return "Extra"
 	case "UNKNOWN_EFFECT":
-		return dim + "Info" + reset
+		return "Info"
 	default:
 		if s.Name != "" {
-			return yellow + s.Name + reset
+			return s.Name
 		}
-		return dim + s.Effect + reset
+		return s.Effect
 	}
 }
 
-func extractMessage(d model.Disruption) string {
+// DisruptionMessage returns a one-line summary of a disruption: its
+// plain text message, or the first message with HTML stripped.
+func DisruptionMessage(d model.Disruption) string {
 	for _, m := range d.Messages {
 		if m.Channel.ContentType == "text/plain" {
 			return m.Text
@@ -58,6 +58,37 @@ func TestFormatMinutesUntil(t *testing.T) {
 	}
 }
 
+func TestFormatMinutesFrom(t *testing.T) {
+	// Tomorrow morning, counted from a planned time rather than now
+	at := time.Now().Add(24 * time.Hour)
+	if got := FormatMinutesFrom(at.Add(7*time.Minute), at); !strings.Contains(got, "7 min") {
+		t.Errorf("expected '7 min', got %q", got)
+	}
+	if got := FormatMinutesFrom(at.Add(-time.Minute), at); !strings.Contains(got, "now") {
+		t.Errorf("expected 'now', got %q", got)
+	}
+}
+
+func TestDepartureDelay(t *testing.T) {
+	tests := []struct {
+		sdt          model.StopDateTime
+		wantDelay    int
+		wantRealtime bool
+	}{
+		{model.StopDateTime{DepartureDateTime: "20260225T143200", BaseDateTime: "20260225T143000", DataFreshness: "realtime"}, 2, true},
+		{model.StopDateTime{DepartureDateTime: "20260225T142900", BaseDateTime: "20260225T143000", DataFreshness: "realtime"}, -1, true},
+		{model.StopDateTime{DepartureDateTime: "20260225T143000", BaseDateTime: "20260225T143000", DataFreshness: "realtime"}, 0, true},
+		{model.StopDateTime{DepartureDateTime: "20260225T143000", DataFreshness: "realtime"}, 0, true},
+		{model.StopDateTime{DepartureDateTime: "20260225T143200", BaseDateTime: "20260225T143000", DataFreshness: "base_schedule"}, 0, false},
+	}
+	for _, tt := range tests {
+		delay, realtime := DepartureDelay(tt.sdt)
+		if delay != tt.wantDelay || realtime != tt.wantRealtime {
+			t.Errorf("DepartureDelay(%+v) = %d, %v; want %d, %v", tt.sdt, delay, realtime, tt.wantDelay, tt.wantRealtime)
+		}
+	}
+}
+
 func TestStripHTMLTags(t *testing.T) {
 	tests := []struct {
 		input, want string
@@ -77,7 +108,7 @@ func TestStripHTMLTags(t *testing.T) {
 	}
 }
 
-func TestExtractMessage(t *testing.T) {
+func TestDisruptionMessage(t *testing.T) {
 	// Prefers text/plain
 	d := model.Disruption{
 		Messages: []model.Message{
@@ -85,7 +116,7 @@ func TestExtractMessage(t *testing.T) {
 			{Text: "plain version", Channel: model.Channel{ContentType: "text/plain"}},
 		},
 	}
-	got := extractMessage(d)
+	got := DisruptionMessage(d)
 	if got != "plain version" {
 		t.Errorf("expected 'plain version', got %q", got)
 	}
@@ -96,20 +127,20 @@ func TestExtractMessage(t *testing.T) {
 			{Text: "<p>only html</p>", Channel: model.Channel{ContentType: "text/html"}},
 		},
 	}
-	got = extractMessage(d2)
+	got = DisruptionMessage(d2)
 	if got != "only html" {
 		t.Errorf("expected 'only html', got %q", got)
 	}
 
 	// Falls back to cause
 	d3 := model.Disruption{Cause: "travaux"}
-	got = extractMessage(d3)
+	got = DisruptionMessage(d3)
 	if got != "travaux" {
 		t.Errorf("expected 'travaux', got %q", got)
 	}
 
 	// Empty
-	got = extractMessage(model.Disruption{})
+	got = DisruptionMessage(model.Disruption{})
 	if got != "" {
 		t.Errorf("expected empty, got %q", got)
 	}
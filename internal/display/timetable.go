@@ -0,0 +1,207 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+// minutesPerRow is how many times fit on one hour row before wrapping.
+const minutesPerRow = 15
+
+// Timetable is the service of one line and direction at a stop over a
+// day, in chronological order.
+type Timetable struct {
+	Code           string
+	CommercialMode string
+	Direction      string
+	Times          []time.Time
+	// Note explains an empty timetable: "terminus" or "no service".
+	Note string
+}
+
+// ScheduleTimetables builds timetables from Navitia stop schedules.
+// Routes sharing a line and direction (e.g. several platforms) are merged.
+func ScheduleTimetables(schedules []model.StopSchedule) []Timetable {
+	b := newTimetableBuilder()
+	for _, s := range schedules {
+		tt := b.get(s.DisplayInformations)
+		for _, dt := range s.DateTimes {
+			if t, err := ParseNavitiaTime(dt.DateTime); err == nil {
+				tt.Times = append(tt.Times, t)
+			}
+		}
+		switch s.AdditionalInformations {
+		case "terminus", "partial_terminus":
+			tt.Note = "terminus"
+		case "no_departure_this_day", "no_active_circulation_this_day":
+			tt.Note = "no service"
+		}
+	}
+	return b.timetables()
+}
+
+// ArrivalTimetables builds timetables from Navitia arrivals.
+func ArrivalTimetables(arrivals []model.Departure) []Timetable {
+	b := newTimetableBuilder()
+	for _, a := range arrivals {
+		tt := b.get(a.DisplayInformations)
+		if t, err := ParseNavitiaTime(a.StopDateTime.ArrivalDateTime); err == nil {
+			tt.Times = append(tt.Times, t)
+		}
+	}
+	return b.timetables()
+}
+
+type timetableBuilder struct {
+	byKey map[string]*Timetable
+	order []*Timetable
+}
+
+func newTimetableBuilder() *timetableBuilder {
+	return &timetableBuilder{byKey: make(map[string]*Timetable)}
+}
+
+func (b *timetableBuilder) get(di model.DisplayInfo) *Timetable {
+	key := di.CommercialMode + "|" + di.Code + "|" + di.Direction
+	if tt, ok := b.byKey[key]; ok {
+		return tt
+	}
+	tt := &Timetable{Code: di.Code, CommercialMode: di.CommercialMode, Direction: di.Direction}
+	b.byKey[key] = tt
+	b.order = append(b.order, tt)
+	return tt
+}
+
+// timetables returns the timetables sorted like departure boards (by mode,
+// line, then direction), with times in order.
+func (b *timetableBuilder) timetables() []Timetable {
+	out := make([]Timetable, 0, len(b.order))
+	for _, tt := range b.order {
+		sort.Slice(tt.Times, func(i, j int) bool { return tt.Times[i].Before(tt.Times[j]) })
+		if len(tt.Times) > 0 {
+			tt.Note = ""
+		}
+		out = append(out, *tt)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		pi, pj := modePriority(out[i].CommercialMode), modePriority(out[j].CommercialMode)
+		if pi != pj {
+			return pi < pj
+		}
+		if out[i].Code != out[j].Code {
+			return out[i].Code < out[j].Code
+		}
+		return out[i].Direction < out[j].Direction
+	})
+	return out
+}
+
+// Timetables prints each timetable with its first and last service and
+// one row per hour. Times before now are dimmed and the next one is
+// highlighted, so today's timetable doubles as a "what's left" view.
+func Timetables(w io.Writer, tts []Timetable, now time.Time) {
+	if len(tts) == 0 {
+		fmt.Fprintf(w, "  %s(no service found)%s\n", dim, reset)
+		return
+	}
+
+	for _, tt := range tts {
+		fmt.Fprintf(w, "  %s  → %s\n", lineLabel(tt.Code, tt.CommercialMode), tt.Direction)
+		if len(tt.Times) == 0 {
+			note := tt.Note
+			if note == "" {
+				note = "no service"
+			}
+			fmt.Fprintf(w, "    %s(%s)%s\n\n", dim, note, reset)
+			continue
+		}
+
+		first, last := tt.Times[0].In(paris), tt.Times[len(tt.Times)-1].In(paris)
+		fmt.Fprintf(w, "    %sFirst%s %s  %sLast%s %s\n",
+			dim, reset, first.Format("15:04"), dim, reset, last.Format("15:04"))
+
+		next := sort.Search(len(tt.Times), func(i int) bool { return !tt.Times[i].Before(now) })
+		for _, row := range hourRows(tt.Times) {
+			mins := make([]string, len(row.idx))
+			for k, i := range row.idx {
+				m := tt.Times[i].In(paris).Format("04")
+				switch {
+				case i < next:
+					m = dim + m + reset
+				case i == next:
+					m = green + bold + m + reset
+				}
+				mins[k] = m
+			}
+			for start := 0; start < len(mins); start += minutesPerRow {
+				hour := "  "
+				if start == 0 {
+					hour = fmt.Sprintf("%02d", row.hour)
+				}
+				end := min(start+minutesPerRow, len(mins))
+				fmt.Fprintf(w, "    %s%s%s │ %s\n", bold, hour, reset, strings.Join(mins[start:end], " "))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+type hourRow struct {
+	hour int
+	idx  []int // indexes into the timetable's times
+}
+
+// hourRows groups sorted times into consecutive clock hours. Hours past
+// midnight follow 23 rather than sorting first.
+func hourRows(times []time.Time) []hourRow {
+	var rows []hourRow
+	for i, t := range times {
+		h := t.In(paris).Hour()
+		if len(rows) == 0 || rows[len(rows)-1].hour != h {
+			rows = append(rows, hourRow{hour: h})
+		}
+		rows[len(rows)-1].idx = append(rows[len(rows)-1].idx, i)
+	}
+	return rows
+}
+
+// TimetableRecord is one scheduled time in structured output.
+type TimetableRecord struct {
+	StopID    string `json:"stop_id" yaml:"stop_id"`
+	StopName  string `json:"stop_name" yaml:"stop_name"`
+	Line      string `json:"line" yaml:"line"`
+	Mode      string `json:"mode" yaml:"mode"`
+	Direction string `json:"direction" yaml:"direction"`
+	Time      string `json:"time" yaml:"time"`
+}
+
+func (TimetableRecord) csvHeader() []string {
+	return []string{"stop_id", "stop_name", "line", "mode", "direction", "time"}
+}
+
+func (r TimetableRecord) csvRow() []string {
+	return []string{r.StopID, r.StopName, r.Line, r.Mode, r.Direction, r.Time}
+}
+
+// TimetableRecords flattens timetables into one record per time.
+func TimetableRecords(stopID, stopName string, tts []Timetable) []TimetableRecord {
+	var recs []TimetableRecord
+	for _, tt := range tts {
+		for _, t := range tt.Times {
+			recs = append(recs, TimetableRecord{
+				StopID:    stopID,
+				StopName:  stopName,
+				Line:      model.LineLabel(tt.Code, tt.CommercialMode),
+				Mode:      modeName(tt.CommercialMode),
+				Direction: tt.Direction,
+				Time:      t.Format(time.RFC3339),
+			})
+		}
+	}
+	return recs
+}
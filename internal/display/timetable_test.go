@@ -0,0 +1,54 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+func TestScheduleTimetables(t *testing.T) {
+	m1 := model.DisplayInfo{Code: "1", CommercialMode: "Métro", Direction: "La Défense"}
+	rerA := model.DisplayInfo{Code: "A", CommercialMode: "RER", Direction: "Boissy"}
+	schedules := []model.StopSchedule{
+		{DisplayInformations: rerA, AdditionalInformations: "terminus"},
+		{DisplayInformations: m1, DateTimes: []model.ScheduleDateTime{{DateTime: "20260226T001000"}, {DateTime: "20260225T233000"}}},
+		{DisplayInformations: m1, DateTimes: []model.ScheduleDateTime{{DateTime: "20260225T053100"}}},
+	}
+
+	tts := ScheduleTimetables(schedules)
+	if len(tts) != 2 {
+		t.Fatalf("expected 2 timetables, got %d", len(tts))
+	}
+	if tts[0].Code != "1" || len(tts[0].Times) != 3 {
+		t.Fatalf("expected merged M1 timetable first, got %+v", tts[0])
+	}
+	rows := hourRows(tts[0].Times)
+	var hours []int
+	for _, r := range rows {
+		hours = append(hours, r.hour)
+	}
+	if len(hours) != 3 || hours[0] != 5 || hours[1] != 23 || hours[2] != 0 {
+		t.Errorf("expected hours [5 23 0], got %v", hours)
+	}
+	if tts[1].Note != "terminus" {
+		t.Errorf("expected RER A terminus note, got %+v", tts[1])
+	}
+}
+
+func TestTimetablesNext(t *testing.T) {
+	t0 := time.Date(2026, 2, 25, 23, 30, 0, 0, paris)
+	tts := []Timetable{{Code: "1", CommercialMode: "Métro", Direction: "La Défense",
+		Times: []time.Time{t0, t0.Add(20 * time.Minute), t0.Add(40 * time.Minute)}}}
+
+	var buf bytes.Buffer
+	Timetables(&buf, tts, t0.Add(time.Minute))
+	out := buf.String()
+	for _, want := range []string{"First\033[0m 23:30", "Last\033[0m 00:10", dim + "30" + reset, green + bold + "50" + reset, "00" + reset + " │ 10"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
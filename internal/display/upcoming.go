@@ -0,0 +1,236 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+// UpcomingDisruptions returns the disruptions on resp's lines with an
+// application period beginning between now and until, sorted by the first
+// such begin. filterLine keeps a single line, as in DisruptionsSummary.
+func UpcomingDisruptions(resp *model.LinesResponse, filterLine string, mode model.TransportMode, now, until time.Time) []LineDisruption {
+	if resp == nil {
+		return nil
+	}
+	byLine := make(map[string][]*model.Disruption)
+	for i := range resp.Disruptions {
+		d := &resp.Disruptions[i]
+		if len(upcomingPeriods(*d, now, until)) == 0 {
+			continue
+		}
+		for _, obj := range d.ImpactedObjects {
+			byLine[obj.PTObject.ID] = append(byLine[obj.PTObject.ID], d)
+		}
+	}
+
+	var items []LineDisruption
+	for _, line := range resp.Lines {
+		label := mode.Prefix + line.Code
+		if filterLine != "" && !matchesLineFilter(line.Code, label, filterLine) {
+			continue
+		}
+		for _, d := range byLine[line.ID] {
+			items = append(items, LineDisruption{Label: label, Mode: mode.Name, Disruption: d})
+		}
+	}
+	SortUpcoming(items, now, until)
+	return items
+}
+
+// SortUpcoming sorts disruptions by their first period beginning between
+// now and until.
+func SortUpcoming(items []LineDisruption, now, until time.Time) {
+	first := func(it LineDisruption) time.Time {
+		if ps := upcomingPeriods(*it.Disruption, now, until); len(ps) > 0 {
+			return ps[0].begin
+		}
+		return until
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return first(items[i]).Before(first(items[j]))
+	})
+}
+
+// Upcoming prints upcoming disruptions grouped by line, lines in order of
+// their first disruption, each with its day ranges, e.g.
+// "Sat 25 – Sun 26, all day".
+func Upcoming(w io.Writer, items []LineDisruption, now, until time.Time) {
+	fmt.Fprintf(w, "%sPlanned disruptions until %s%s\n\n", bold, until.In(paris).Format("Mon 2 Jan"), reset)
+	if len(items) == 0 {
+		fmt.Fprintf(w, "%sNo planned disruptions.%s\n", green, reset)
+		return
+	}
+
+	var order []string
+	byLabel := make(map[string][]LineDisruption)
+	rangeWidth := 0
+	for _, it := range items {
+		if _, ok := byLabel[it.Label]; !ok {
+			order = append(order, it.Label)
+		}
+		byLabel[it.Label] = append(byLabel[it.Label], it)
+		for _, r := range dayRanges(upcomingPeriods(*it.Disruption, now, until), now) {
+			rangeWidth = max(rangeWidth, VisibleWidth(r))
+		}
+	}
+
+	for i, label := range order {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s%s%s\n", bold, label, reset)
+		for _, it := range byLabel[label] {
+			d := *it.Disruption
+			ranges := dayRanges(upcomingPeriods(d, now, until), now)
+			fmt.Fprintf(w, "  %s  %s  %s\n", FitWidth(ranges[0], rangeWidth),
+				FitWidth(formatSeverity(d.Severity), 11), truncate(DisruptionMessage(d), 70))
+			for _, r := range ranges[1:] {
+				fmt.Fprintf(w, "  %s\n", r)
+			}
+		}
+	}
+}
+
+// period is an application period in Paris time.
+type period struct {
+	begin, end time.Time
+}
+
+// upcomingPeriods returns d's application periods beginning between now
+// and until, sorted.
+func upcomingPeriods(d model.Disruption, now, until time.Time) []period {
+	var ps []period
+	for _, p := range d.ApplicationPeriods {
+		begin, err := ParseNavitiaTime(p.Begin)
+		if err != nil || begin.Before(now) || !begin.Before(until) {
+			continue
+		}
+		end, err := ParseNavitiaTime(p.End)
+		if err != nil || end.Before(begin) {
+			end = begin
+		}
+		ps = append(ps, period{begin, end})
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].begin.Before(ps[j].begin) })
+	return ps
+}
+
+// dayRanges formats sorted periods as day ranges: "Sat 25 – Sun 26, all
+// day", "Wed 25, 05:00–23:59", "Mon 23 – Thu 26, 22:00–05:00 nightly"
+// for the same hours on consecutive days, or "Sat 25 22:00 – Mon 27 05:00".
+// The month is added to days outside now's month.
+func dayRanges(ps []period, now time.Time) []string {
+	// Join back-to-back periods, often split at midnight
+	var spans []period
+	for _, p := range ps {
+		if n := len(spans); n > 0 && !p.begin.After(spans[n-1].end.Add(time.Minute)) {
+			if p.end.After(spans[n-1].end) {
+				spans[n-1].end = p.end
+			}
+			continue
+		}
+		spans = append(spans, p)
+	}
+
+	now = now.In(paris)
+	day := func(t time.Time) string {
+		if t.Month() != now.Month() || t.Year() != now.Year() {
+			return t.Format("Mon 2 Jan")
+		}
+		return t.Format("Mon 2")
+	}
+	var out []string
+	for i := 0; i < len(spans); i++ {
+		s := spans[i]
+		if lastDay, ok := allDay(s); ok {
+			if sameDay(s.begin, lastDay) {
+				out = append(out, day(s.begin)+", all day")
+			} else {
+				out = append(out, day(s.begin)+" – "+day(lastDay)+", all day")
+			}
+			continue
+		}
+
+		// The same hours on the following days
+		j := i
+		for j+1 < len(spans) && spans[j+1].begin.Sub(spans[j].begin) == 24*time.Hour &&
+			spans[j+1].end.Sub(spans[j].end) == 24*time.Hour {
+			j++
+		}
+		hours := s.begin.Format("15:04") + "–" + s.end.Format("15:04")
+		switch {
+		case j > i && sameDay(s.begin, s.end):
+			out = append(out, day(s.begin)+" – "+day(spans[j].begin)+", "+hours+" daily")
+		case j > i:
+			out = append(out, day(s.begin)+" – "+day(spans[j].begin)+", "+hours+" nightly")
+		case sameDay(s.begin, s.end):
+			out = append(out, day(s.begin)+", "+hours)
+		default:
+			out = append(out, day(s.begin)+" "+s.begin.Format("15:04")+" – "+day(s.end)+" "+s.end.Format("15:04"))
+		}
+		i = j
+	}
+	return out
+}
+
+// allDay reports whether p covers whole days, from midnight to 23:59 or
+// the next midnight, and returns its last day.
+func allDay(p period) (time.Time, bool) {
+	if p.begin.Hour() != 0 || p.begin.Minute() != 0 {
+		return time.Time{}, false
+	}
+	switch {
+	case p.end.Hour() == 23 && p.end.Minute() == 59:
+		return p.end, true
+	case p.end.Hour() == 0 && p.end.Minute() == 0 && p.end.After(p.begin):
+		return p.end.Add(-time.Minute), true
+	}
+	return time.Time{}, false
+}
+
+// UpcomingRecord is one application period of an upcoming disruption in
+// structured output. Begin and end are RFC 3339, Paris time.
+type UpcomingRecord struct {
+	Line         string `json:"line" yaml:"line"`
+	Mode         string `json:"mode" yaml:"mode"`
+	Begin        string `json:"begin" yaml:"begin"`
+	End          string `json:"end" yaml:"end"`
+	Severity     string `json:"severity" yaml:"severity"`
+	SeverityName string `json:"severity_name" yaml:"severity_name"`
+	DisruptionID string `json:"disruption_id" yaml:"disruption_id"`
+	Message      string `json:"message" yaml:"message"`
+}
+
+func (UpcomingRecord) csvHeader() []string {
+	return []string{"line", "mode", "begin", "end", "severity", "severity_name", "disruption_id", "message"}
+}
+
+func (r UpcomingRecord) csvRow() []string {
+	return []string{r.Line, r.Mode, r.Begin, r.End, r.Severity, r.SeverityName, r.DisruptionID, r.Message}
+}
+
+// UpcomingRecords mirrors Upcoming as structured records, one per period.
+func UpcomingRecords(items []LineDisruption, now, until time.Time) []UpcomingRecord {
+	var recs []UpcomingRecord
+	for _, it := range items {
+		d := *it.Disruption
+		for _, p := range upcomingPeriods(d, now, until) {
+			recs = append(recs, UpcomingRecord{
+				Line:         it.Label,
+				Mode:         it.Mode,
+				Begin:        p.begin.Format(time.RFC3339),
+				End:          p.end.Format(time.RFC3339),
+				Severity:     d.Severity.Effect,
+				SeverityName: d.Severity.Name,
+				DisruptionID: d.DisruptionID,
+				Message:      strings.TrimSpace(DisruptionMessage(d)),
+			})
+		}
+	}
+	return recs
+}
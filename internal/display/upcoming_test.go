@@ -0,0 +1,72 @@
+package display
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+func TestDayRanges(t *testing.T) {
+	now := time.Date(2026, 4, 20, 12, 0, 0, 0, paris) // Monday
+	p := func(begin, end string) period {
+		b, _ := ParseNavitiaTime(begin)
+		e, _ := ParseNavitiaTime(end)
+		return period{b, e}
+	}
+	tests := []struct {
+		ps   []period
+		want string
+	}{
+		{[]period{p("20260425T000000", "20260426T235900")}, "Sat 25 – Sun 26, all day"},
+		{[]period{p("20260425T000000", "20260426T000000")}, "Sat 25, all day"},
+		// Split at midnight
+		{[]period{p("20260425T000000", "20260425T235959"), p("20260426T000000", "20260426T235900")}, "Sat 25 – Sun 26, all day"},
+		{[]period{p("20260422T093000", "20260422T160000")}, "Wed 22, 09:30–16:00"},
+		{[]period{p("20260425T220000", "20260427T050000")}, "Sat 25 22:00 – Mon 27 05:00"},
+		{[]period{p("20260427T223000", "20260428T050000"), p("20260428T223000", "20260429T050000"),
+			p("20260429T223000", "20260430T050000")}, "Mon 27 – Wed 29, 22:30–05:00 nightly"},
+		{[]period{p("20260421T100000", "20260421T150000"), p("20260422T100000", "20260422T150000")}, "Tue 21 – Wed 22, 10:00–15:00 daily"},
+		{[]period{p("20260502T000000", "20260503T235900")}, "Sat 2 May – Sun 3 May, all day"},
+	}
+	for _, tt := range tests {
+		got := strings.Join(dayRanges(tt.ps, now), "; ")
+		if got != tt.want {
+			t.Errorf("dayRanges(%v) = %q, want %q", tt.ps, got, tt.want)
+		}
+	}
+}
+
+func TestUpcomingDisruptions(t *testing.T) {
+	now := time.Date(2026, 4, 20, 12, 0, 0, 0, paris)
+	until := now.AddDate(0, 0, 7)
+	works := func(id, begin, end string) model.Disruption {
+		return model.Disruption{ID: id, DisruptionID: id, Status: "future",
+			Severity:           model.Severity{Effect: "NO_SERVICE"},
+			ApplicationPeriods: []model.Period{{Begin: begin, End: end}},
+			ImpactedObjects:    []model.ImpactedObject{{PTObject: model.PTObject{ID: "line:A"}}}}
+	}
+	resp := &model.LinesResponse{
+		Lines: []model.Line{{ID: "line:A", Code: "A"}},
+		Disruptions: []model.Disruption{
+			works("later", "20260425T000000", "20260426T235900"),
+			works("sooner", "20260422T220000", "20260423T050000"),
+			works("current", "20260420T050000", "20260420T235900"),
+			works("next-month", "20260502T000000", "20260503T235900"),
+		},
+	}
+	rer := model.TransportMode{Name: "rer", Prefix: "RER "}
+	items := UpcomingDisruptions(resp, "", rer, now, until)
+	if len(items) != 2 || items[0].Disruption.ID != "sooner" || items[1].Disruption.ID != "later" {
+		t.Fatalf("UpcomingDisruptions() = %+v, want sooner then later", items)
+	}
+	if items := UpcomingDisruptions(resp, "B", rer, now, until); len(items) != 0 {
+		t.Errorf("line filter ignored: %+v", items)
+	}
+
+	recs := UpcomingRecords(items, now, until)
+	if len(recs) != 2 || recs[0].Line != "RER A" || recs[0].Begin != "2026-04-22T22:00:00+02:00" {
+		t.Errorf("UpcomingRecords() = %+v", recs)
+	}
+}
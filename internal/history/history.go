@@ -0,0 +1,264 @@
+// Package history records observed disruptions in an append-only JSONL
+// log, one entry per line. Entries for the same DisruptionID are merged
+// when the log is read, so concurrent writers never corrupt each other's
+// data; at worst an observation is written twice. Once the log holds
+// many more lines than disruptions, it is rewritten with one line each.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+// FileName is the log's file name in the data directory.
+const FileName = "disruptions.jsonl"
+
+// refreshAfter is how stale LastSeen may get in the log before an
+// unchanged disruption is written again.
+const refreshAfter = 10 * time.Minute
+
+// compactSlack is how many lines beyond two per disruption the log may
+// hold before it is compacted.
+const compactSlack = 100
+
+// Entry is what was observed of one disruption.
+type Entry struct {
+	DisruptionID       string         `json:"disruption_id"`
+	FirstSeen          time.Time      `json:"first_seen"`
+	LastSeen           time.Time      `json:"last_seen"`
+	Severity           model.Severity `json:"severity"`          // the latest
+	Effects            []string       `json:"effects,omitempty"` // every effect seen, e.g. "NO_SERVICE"
+	Lines              []string       `json:"lines"`             // labels, e.g. "RER B"
+	ApplicationPeriods []model.Period `json:"application_periods,omitempty"`
+	Cause              string         `json:"cause,omitempty"`
+	Message            string         `json:"message,omitempty"`
+}
+
+// merge combines two observations of a disruption. Seen times widen, lines
+// and effects accumulate, and the rest comes from the latest observation.
+func merge(a, b Entry) Entry {
+	if b.LastSeen.Before(a.LastSeen) {
+		a, b = b, a
+	}
+	m := b
+	if a.FirstSeen.Before(m.FirstSeen) {
+		m.FirstSeen = a.FirstSeen
+	}
+	m.Lines = union(a.Lines, b.Lines)
+	m.Effects = union(a.Effects, b.Effects)
+	return m
+}
+
+func union(a, b []string) []string {
+	out := slices.Clone(a)
+	for _, s := range b {
+		if !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// sameState reports whether a and b differ only in their seen times.
+func sameState(a, b Entry) bool {
+	return a.Severity == b.Severity &&
+		a.Cause == b.Cause &&
+		a.Message == b.Message &&
+		slices.Equal(a.Lines, b.Lines) &&
+		slices.Equal(a.Effects, b.Effects) &&
+		slices.Equal(a.ApplicationPeriods, b.ApplicationPeriods)
+}
+
+// Log appends observations to a file. It is safe for concurrent use.
+type Log struct {
+	path string
+
+	mu      sync.Mutex
+	written map[string]Entry // latest state in the file, loaded on first Add
+	lines   int              // lines in the file, as far as we know
+}
+
+// Open returns a log writing to path. The file and its directory are
+// created on first write.
+func Open(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns the log file path.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Add records observations. A disruption is only written when it is new,
+// has changed, or was last written more than a few minutes ago, so
+// polling the same disruptions doesn't grow the log much, and the log is
+// compacted when it has grown anyway.
+func (l *Log) Add(entries []Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.written == nil {
+		byID, lines, err := read(l.path)
+		if err != nil {
+			return err
+		}
+		l.written, l.lines = byID, lines
+		if l.overgrown() {
+			if err := l.compact(); err != nil {
+				return err
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	n := 0
+	for _, e := range entries {
+		e.Lines = union(nil, e.Lines)
+		e.Effects = union(nil, e.Effects)
+		prev, ok := l.written[e.DisruptionID]
+		if ok {
+			e = merge(prev, e)
+			if sameState(prev, e) && e.LastSeen.Sub(prev.LastSeen) < refreshAfter {
+				continue
+			}
+		}
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+		l.written[e.DisruptionID] = e
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	l.lines += n
+	if l.overgrown() {
+		return l.compact()
+	}
+	return nil
+}
+
+func (l *Log) overgrown() bool {
+	return l.lines > 2*len(l.written)+compactSlack
+}
+
+// compact rewrites the file with one line per disruption. The file is
+// read again first, keeping what other writers appended; a line appended
+// while it is rewritten is lost, until that writer refreshes it.
+func (l *Log) compact() error {
+	byID, _, err := read(l.path)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range sorted(byID) {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), l.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	l.written, l.lines = byID, len(byID)
+	return nil
+}
+
+// Load reads a log and merges its entries by DisruptionID, ordered by
+// first seen. A missing file is an empty log; unreadable lines (e.g. a
+// write cut short) are skipped.
+func Load(path string) ([]Entry, error) {
+	byID, _, err := read(path)
+	if err != nil || len(byID) == 0 {
+		return nil, err
+	}
+	return sorted(byID), nil
+}
+
+// read merges a log's entries by DisruptionID and counts its lines.
+func read(path string) (map[string]Entry, int, error) {
+	byID := make(map[string]Entry)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return byID, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	lines := 0
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		lines++
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil || e.DisruptionID == "" {
+			continue
+		}
+		if prev, ok := byID[e.DisruptionID]; ok {
+			e = merge(prev, e)
+		}
+		byID[e.DisruptionID] = e
+	}
+	if err := sc.Err(); err != nil {
+		return nil, 0, err
+	}
+	return byID, lines, nil
+}
+
+// sorted returns entries ordered by first seen.
+func sorted(byID map[string]Entry) []Entry {
+	entries := make([]Entry, 0, len(byID))
+	for _, e := range byID {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].FirstSeen.Equal(entries[j].FirstSeen) {
+			return entries[i].FirstSeen.Before(entries[j].FirstSeen)
+		}
+		return entries[i].DisruptionID < entries[j].DisruptionID
+	})
+	return entries
+}
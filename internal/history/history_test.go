@@ -0,0 +1,145 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyrilghali/metro-cli/internal/model"
+)
+
+var t0 = time.Date(2026, 2, 25, 8, 0, 0, 0, time.UTC)
+
+func observed(id string, at time.Time, effect string, lines ...string) Entry {
+	return Entry{
+		DisruptionID: id,
+		FirstSeen:    at,
+		LastSeen:     at,
+		Severity:     model.Severity{Effect: effect},
+		Effects:      []string{effect},
+		Lines:        lines,
+	}
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(data), "\n")
+}
+
+func TestLogAdd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", FileName)
+	log := Open(path)
+
+	steps := []struct {
+		entry   Entry
+		written int // lines in the file afterwards
+	}{
+		{observed("a", t0, "SIGNIFICANT_DELAYS", "RER B"), 1},
+		{observed("a", t0.Add(2*time.Minute), "SIGNIFICANT_DELAYS", "RER B"), 1}, // unchanged
+		{observed("a", t0.Add(4*time.Minute), "NO_SERVICE", "RER B"), 2},         // worse
+		{observed("a", t0.Add(30*time.Minute), "NO_SERVICE", "RER B"), 3},        // refresh last seen
+		{observed("b", t0.Add(30*time.Minute), "MODIFIED_SERVICE", "M14"), 4},
+	}
+	for i, s := range steps {
+		if err := log.Add([]Entry{s.entry}); err != nil {
+			t.Fatal(err)
+		}
+		if got := countLines(t, path); got != s.written {
+			t.Errorf("step %d: %d lines written, want %d", i, got, s.written)
+		}
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	a := entries[0]
+	if a.DisruptionID != "a" || !a.FirstSeen.Equal(t0) || !a.LastSeen.Equal(t0.Add(30*time.Minute)) {
+		t.Errorf("a = %+v", a)
+	}
+	if a.Severity.Effect != "NO_SERVICE" || strings.Join(a.Effects, ",") != "NO_SERVICE,SIGNIFICANT_DELAYS" {
+		t.Errorf("a severity %q, effects %v", a.Severity.Effect, a.Effects)
+	}
+}
+
+func TestLoadMergesWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	// Two processes recording the same disruption from different places
+	if err := Open(path).Add([]Entry{observed("a", t0, "NO_SERVICE", "RER A")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Open(path).Add([]Entry{observed("a", t0.Add(-time.Minute), "NO_SERVICE", "RER B")}); err != nil {
+		t.Fatal(err)
+	}
+	// A write cut short
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	f.WriteString(`{"disruption_id":"b","first_se`)
+	f.Close()
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1: %+v", len(entries), entries)
+	}
+	e := entries[0]
+	if strings.Join(e.Lines, ",") != "RER A,RER B" || !e.FirstSeen.Equal(t0.Add(-time.Minute)) || !e.LastSeen.Equal(t0) {
+		t.Errorf("merged = %+v", e)
+	}
+}
+
+func TestLogCompacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	// A long-running writer refreshing one disruption
+	log := Open(path)
+	last := t0
+	for i := 0; i < 3*compactSlack; i++ {
+		last = t0.Add(time.Duration(i) * refreshAfter)
+		if err := log.Add([]Entry{observed("a", last, "NO_SERVICE", "RER B")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := countLines(t, path); n > 2+compactSlack {
+		t.Errorf("log not compacted: %d lines", n)
+	}
+
+	// Another process appended without compacting: the next one does
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	for i := 0; i < 2*compactSlack; i++ {
+		f.WriteString(`{"disruption_id":"b","first_seen":"2026-02-25T09:00:00Z","last_seen":"2026-02-25T09:00:00Z","lines":["M14"]}` + "\n")
+	}
+	f.Close()
+	if err := Open(path).Add(nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := countLines(t, path); n != 2 {
+		t.Errorf("got %d lines after compacting on load, want 2", n)
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || !entries[0].FirstSeen.Equal(t0) || !entries[0].LastSeen.Equal(last) || entries[1].DisruptionID != "b" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	entries, err := Load(filepath.Join(t.TempDir(), FileName))
+	if err != nil || entries != nil {
+		t.Errorf("Load of a missing file = %v, %v", entries, err)
+	}
+}
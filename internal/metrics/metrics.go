@@ -0,0 +1,194 @@
+// Package metrics keeps gauges, counters and histograms in memory and
+// writes them in the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Labels are a metric's label names and values.
+type Labels map[string]string
+
+// Sample is one value of a gauge.
+type Sample struct {
+	Labels Labels
+	Value  float64
+}
+
+// DefaultBuckets are histogram upper bounds in seconds, suited to API
+// latencies.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type family struct {
+	help    string
+	kind    string // "gauge", "counter" or "histogram"
+	buckets []float64
+	series  map[string]*series
+}
+
+type series struct {
+	labels string // rendered, e.g. `mode="rer",line="B"`
+	value  float64
+	counts []uint64 // histogram bucket counts, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// Registry holds metric families. It is safe for concurrent use.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+func (r *Registry) family(name, help, kind string) *family {
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{help: help, kind: kind, series: make(map[string]*series)}
+		r.families[name] = f
+	}
+	return f
+}
+
+func (f *family) get(labels Labels) *series {
+	key := formatLabels(labels)
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: key}
+		if f.kind == "histogram" {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// SetGauges replaces every sample of a gauge, so series that are no longer
+// reported (e.g. a line that is no longer disrupted) disappear.
+func (r *Registry) SetGauges(name, help string, samples []Sample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := r.family(name, help, "gauge")
+	f.series = make(map[string]*series)
+	for _, s := range samples {
+		f.get(s.Labels).value = s.Value
+	}
+}
+
+// Add increments a counter.
+func (r *Registry) Add(name, help string, labels Labels, delta float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.family(name, help, "counter").get(labels).value += delta
+}
+
+// Observe records a value in a histogram with DefaultBuckets.
+func (r *Registry) Observe(name, help string, labels Labels, v float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := r.family(name, help, "histogram")
+	if f.buckets == nil {
+		f.buckets = DefaultBuckets
+	}
+	s := f.get(labels)
+	for i, le := range f.buckets {
+		if v <= le {
+			s.counts[i]++
+			break
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+// WriteTo writes all metrics in the Prometheus text format, sorted by
+// name and labels.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := r.families[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, f.help, name, f.kind)
+		keys := make([]string, 0, len(f.series))
+		for k := range f.series {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			s := f.series[k]
+			if f.kind != "histogram" {
+				fmt.Fprintf(&b, "%s%s %s\n", name, braces(s.labels), formatValue(s.value))
+				continue
+			}
+			var cum uint64
+			for i, le := range f.buckets {
+				cum += s.counts[i]
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", name, braces(join(s.labels, `le="`+formatValue(le)+`"`)), cum)
+			}
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", name, braces(join(s.labels, `le="+Inf"`)), s.count)
+			fmt.Fprintf(&b, "%s_sum%s %s\n", name, braces(s.labels), formatValue(s.sum))
+			fmt.Fprintf(&b, "%s_count%s %d\n", name, braces(s.labels), s.count)
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// formatLabels renders labels sorted by name, with escaped values.
+func formatLabels(labels Labels) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + `="` + labelEscaper.Replace(labels[name]) + `"`
+	}
+	return strings.Join(parts, ",")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func braces(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+func join(labels, extra string) string {
+	if labels == "" {
+		return extra
+	}
+	return labels + "," + extra
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
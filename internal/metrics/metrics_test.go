@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.SetGauges("metro_line_disrupted", "Disrupted lines.", []Sample{
+		{Labels{"mode": "rer", "line": "RER B"}, 1},
+		{Labels{"mode": "metro", "line": `M"1`}, 1},
+	})
+	r.Add("metro_api_requests_total", "API requests.", Labels{"endpoint": "lines", "code": "200"}, 1)
+	r.Add("metro_api_requests_total", "API requests.", Labels{"endpoint": "lines", "code": "200"}, 1)
+	r.Observe("metro_api_request_duration_seconds", "API latency.", Labels{"endpoint": "lines"}, 0.2)
+	r.Observe("metro_api_request_duration_seconds", "API latency.", Labels{"endpoint": "lines"}, 20)
+
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP metro_api_request_duration_seconds API latency.
+# TYPE metro_api_request_duration_seconds histogram
+metro_api_request_duration_seconds_bucket{endpoint="lines",le="0.05"} 0
+metro_api_request_duration_seconds_bucket{endpoint="lines",le="0.1"} 0
+metro_api_request_duration_seconds_bucket{endpoint="lines",le="0.25"} 1
+metro_api_request_duration_seconds_bucket{endpoint="lines",le="0.5"} 1
+metro_api_request_duration_seconds_bucket{endpoint="lines",le="1"} 1
+metro_api_request_duration_seconds_bucket{endpoint="lines",le="2.5"} 1
+metro_api_request_duration_seconds_bucket{endpoint="lines",le="5"} 1
+metro_api_request_duration_seconds_bucket{endpoint="lines",le="10"} 1
+metro_api_request_duration_seconds_bucket{endpoint="lines",le="+Inf"} 2
+metro_api_request_duration_seconds_sum{endpoint="lines"} 20.2
+metro_api_request_duration_seconds_count{endpoint="lines"} 2
+# HELP metro_api_requests_total API requests.
+# TYPE metro_api_requests_total counter
+metro_api_requests_total{code="200",endpoint="lines"} 2
+# HELP metro_line_disrupted Disrupted lines.
+# TYPE metro_line_disrupted gauge
+metro_line_disrupted{line="M\"1",mode="metro"} 1
+metro_line_disrupted{line="RER B",mode="rer"} 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteTo() =\n%s\nwant\n%s", got, want)
+	}
+
+	// Replacing gauges drops series no longer reported
+	r.SetGauges("metro_line_disrupted", "Disrupted lines.", nil)
+	b.Reset()
+	r.WriteTo(&b)
+	if strings.Contains(b.String(), "metro_line_disrupted{") {
+		t.Errorf("stale gauge series kept:\n%s", b.String())
+	}
+}
@@ -31,10 +31,11 @@ type StopDateTime struct {
 }
 
 type Route struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Direction Direction `json:"direction"`
-	Line      *Line     `json:"line,omitempty"`
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Direction     Direction `json:"direction"`
+	DirectionType string    `json:"direction_type,omitempty"` // "forward" or "backward"
+	Line          *Line     `json:"line,omitempty"`
 }
 
 type Direction struct {
@@ -7,6 +7,18 @@ type LinesResponse struct {
 	Pagination  Pagination   `json:"pagination"`
 }
 
+// LineReportsResponse is returned by /line_reports: the disrupted lines and
+// the disruptions applying within the requested period.
+type LineReportsResponse struct {
+	LineReports []LineReport `json:"line_reports"`
+	Disruptions []Disruption `json:"disruptions"`
+	Pagination  Pagination   `json:"pagination"`
+}
+
+type LineReport struct {
+	Line Line `json:"line"`
+}
+
 type Disruption struct {
 	ID                 string           `json:"id"`
 	DisruptionID       string           `json:"disruption_id"`
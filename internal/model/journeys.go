@@ -0,0 +1,31 @@
+package model
+
+// JourneysResponse is returned by the Navitia /journeys endpoint.
+type JourneysResponse struct {
+	Journeys    []Journey    `json:"journeys"`
+	Disruptions []Disruption `json:"disruptions,omitempty"`
+}
+
+type Journey struct {
+	Type              string    `json:"type"` // "best", "rapid", "comfort", ...
+	Status            string    `json:"status"`
+	Duration          int       `json:"duration"` // seconds
+	NbTransfers       int       `json:"nb_transfers"`
+	DepartureDateTime string    `json:"departure_date_time"`
+	ArrivalDateTime   string    `json:"arrival_date_time"`
+	Sections          []Section `json:"sections"`
+}
+
+// Section is one leg of a journey.
+type Section struct {
+	ID                  string        `json:"id"`
+	Type                string        `json:"type"` // "public_transport", "street_network", "transfer", "waiting", "crow_fly"
+	Mode                string        `json:"mode,omitempty"`
+	TransferType        string        `json:"transfer_type,omitempty"`
+	Duration            int           `json:"duration"` // seconds
+	DepartureDateTime   string        `json:"departure_date_time"`
+	ArrivalDateTime     string        `json:"arrival_date_time"`
+	From                *NavitiaPlace `json:"from,omitempty"`
+	To                  *NavitiaPlace `json:"to,omitempty"`
+	DisplayInformations *DisplayInfo  `json:"display_informations,omitempty"`
+}
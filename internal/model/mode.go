@@ -2,7 +2,9 @@ package model
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"unicode"
 )
 
 // TransportMode represents a supported public transport mode.
@@ -84,6 +86,117 @@ func (m TransportMode) IsAll() bool {
 	return m.Name == "all"
 }
 
+// ModeSet is a selection of transport modes. The empty set, AllModes,
+// means every mode and applies no filter.
+type ModeSet []TransportMode
+
+// AllModes selects every transport mode.
+var AllModes ModeSet
+
+// ParseModes parses a comma-separated list of modes ("metro,rer" or
+// "all") and removes the excluded ones. Excluding from "all" keeps the
+// other known modes, so rarer ones (funicular, cable car) are left out.
+func ParseModes(include string, exclude []string) (ModeSet, error) {
+	exclude = slices.DeleteFunc(slices.Clone(exclude), func(s string) bool { return strings.TrimSpace(s) == "" })
+	var set ModeSet
+	for _, name := range strings.Split(include, ",") {
+		m, err := ParseMode(name)
+		if err != nil {
+			return nil, err
+		}
+		if m.IsAll() {
+			set = nil
+			for _, n := range ModeNames {
+				set = append(set, Modes[n])
+			}
+			if len(exclude) == 0 {
+				return AllModes, nil
+			}
+			break
+		}
+		if !slices.Contains(set, m) {
+			set = append(set, m)
+		}
+	}
+
+	for _, name := range exclude {
+		m, err := ParseMode(name)
+		if err != nil {
+			return nil, err
+		}
+		if m.IsAll() {
+			return nil, fmt.Errorf("cannot exclude every mode")
+		}
+		for i, kept := range set {
+			if kept.Name == m.Name {
+				set = append(set[:i], set[i+1:]...)
+				break
+			}
+		}
+	}
+	if len(set) == 0 {
+		return nil, fmt.Errorf("no transport mode left after excluding %s", strings.Join(exclude, ", "))
+	}
+	return set, nil
+}
+
+// IsAll reports whether s selects every mode.
+func (s ModeSet) IsAll() bool {
+	return len(s) == 0
+}
+
+// Has reports whether s selects the named mode.
+func (s ModeSet) Has(name string) bool {
+	if s.IsAll() {
+		return true
+	}
+	for _, m := range s {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Filters returns one Navitia filter per request to make: a single empty
+// filter for every mode, else one per mode, as Navitia has no OR filter.
+func (s ModeSet) Filters() []string {
+	if s.IsAll() {
+		return []string{AllFilter}
+	}
+	filters := make([]string, len(s))
+	for i, m := range s {
+		filters[i] = m.Filter
+	}
+	return filters
+}
+
+// Each returns the modes to go through one by one, every known mode for
+// AllModes.
+func (s ModeSet) Each() []TransportMode {
+	if !s.IsAll() {
+		return s
+	}
+	modes := make([]TransportMode, len(ModeNames))
+	for i, n := range ModeNames {
+		modes[i] = Modes[n]
+	}
+	return modes
+}
+
+// String returns the selection as accepted by ParseModes: "all" or
+// "metro,rer".
+func (s ModeSet) String() string {
+	if s.IsAll() {
+		return "all"
+	}
+	names := make([]string, len(s))
+	for i, m := range s {
+		names[i] = m.Name
+	}
+	return strings.Join(names, ",")
+}
+
 // ModeByPhysicalID returns the mode name for a Navitia physical_mode ID.
 func ModeByPhysicalID(id string) string {
 	for _, m := range Modes {
@@ -127,3 +240,95 @@ func LineLabel(code string, commercialMode string) string {
 		return code
 	}
 }
+
+// LineFilter returns a Navitia filter selecting a line from a label like
+// "M1", "RER A", "T3a" or a bare code ("14", "N01"). Prefixed labels also
+// filter on the physical mode, so "M1" doesn't match bus 1.
+func LineFilter(label string) string {
+	mode, code := ParseLineLabel(label)
+	filter := fmt.Sprintf("line.code=%q", code)
+	if mode != "" {
+		filter = Modes[mode].Filter + " and " + filter
+	}
+	return filter
+}
+
+// ParseLineLabel splits a label like "M1", "RER a" or "T3a" into its mode
+// name and line code. A bare code ("14", "N01") has no mode.
+func ParseLineLabel(label string) (mode, code string) {
+	label = strings.TrimSpace(label)
+	upper := strings.ToUpper(label)
+	switch {
+	case strings.HasPrefix(upper, "RER"):
+		return "rer", strings.TrimSpace(upper[3:])
+	case len(label) > 1 && upper[0] == 'M' && label[1] >= '0' && label[1] <= '9':
+		return "metro", label[1:]
+	case len(label) > 1 && upper[0] == 'T' && label[1] >= '0' && label[1] <= '9':
+		return "tram", label[1:]
+	default:
+		return "", label
+	}
+}
+
+// MatchLine reports whether a line label matches a user's line query,
+// ignoring case: "RER A" matches "rera" and "A", "M14" matches "14".
+func MatchLine(label, query string) bool {
+	lmode, lcode := ParseLineLabel(label)
+	qmode, qcode := ParseLineLabel(query)
+	return strings.EqualFold(lcode, qcode) && (qmode == "" || qmode == lmode)
+}
+
+// MatchLines reports whether a line label matches any of the queries, as
+// MatchLine. No query matches every line.
+func MatchLines(label string, queries []string) bool {
+	if len(queries) == 0 {
+		return true
+	}
+	for _, q := range queries {
+		if MatchLine(label, q) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchDirection reports whether a direction matches a user's query,
+// ignoring case, accents and punctuation: each word of the query must
+// start a word of the direction, in any order. "defense" and "la def"
+// match "La Défense (Grande Arche)"; "st lazare" matches "Saint-Lazare".
+func MatchDirection(direction, query string) bool {
+	words := foldWords(direction)
+	for _, q := range foldWords(query) {
+		long := abbreviations[q]
+		found := false
+		for _, w := range words {
+			if strings.HasPrefix(w, q) || (long != "" && w == long) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+var accents = strings.NewReplacer(
+	"à", "a", "â", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"î", "i", "ï", "i",
+	"ô", "o", "ö", "o",
+	"ù", "u", "û", "u", "ü", "u",
+	"ÿ", "y", "ç", "c", "œ", "oe", "æ", "ae",
+)
+
+// abbreviations are the usual short forms of words in station names.
+var abbreviations = map[string]string{"st": "saint", "ste": "sainte"}
+
+// foldWords lowercases s, strips accents and splits it into words.
+func foldWords(s string) []string {
+	return strings.FieldsFunc(accents.Replace(strings.ToLower(s)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -48,6 +48,57 @@ func TestIsAll(t *testing.T) {
 	}
 }
 
+func TestParseModes(t *testing.T) {
+	tests := []struct {
+		include string
+		exclude []string
+		want    string
+		wantErr bool
+	}{
+		{"all", nil, "all", false},
+		{"metro", nil, "metro", false},
+		{"metro,rer", nil, "metro,rer", false},
+		{"Metro, RER,metro", nil, "metro,rer", false},
+		{"all", []string{"bus"}, "metro,rer,train,tram", false},
+		{"metro,rer", []string{"rer"}, "metro", false},
+		{"metro", []string{""}, "metro", false},
+		{"metro,", nil, "", true},
+		{"metro,boat", nil, "", true},
+		{"metro", []string{"metro"}, "", true},
+		{"metro", []string{"all"}, "", true},
+	}
+	for _, tt := range tests {
+		s, err := ParseModes(tt.include, tt.exclude)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseModes(%q, %q) expected error, got %q", tt.include, tt.exclude, s)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseModes(%q, %q) unexpected error: %v", tt.include, tt.exclude, err)
+			continue
+		}
+		if s.String() != tt.want {
+			t.Errorf("ParseModes(%q, %q) = %q, want %q", tt.include, tt.exclude, s, tt.want)
+		}
+	}
+
+	s, _ := ParseModes("metro,rer", nil)
+	if got := s.Filters(); len(got) != 2 || got[1] != Modes["rer"].Filter {
+		t.Errorf("Filters() = %q", got)
+	}
+	if !s.Has("rer") || s.Has("bus") {
+		t.Error("Has() wrong for metro,rer")
+	}
+	if got := AllModes.Filters(); len(got) != 1 || got[0] != AllFilter {
+		t.Errorf("AllModes.Filters() = %q", got)
+	}
+	if len(AllModes.Each()) != len(ModeNames) {
+		t.Errorf("AllModes.Each() has %d modes", len(AllModes.Each()))
+	}
+}
+
 func TestLineLabel(t *testing.T) {
 	tests := []struct {
 		code, mode, want string
@@ -88,3 +139,72 @@ func TestParseModeFilters(t *testing.T) {
 		t.Errorf("expected empty filter for 'all', got %q", all.Filter)
 	}
 }
+
+func TestLineFilter(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"M1", `physical_mode.id=physical_mode:Metro and line.code="1"`},
+		{"m14", `physical_mode.id=physical_mode:Metro and line.code="14"`},
+		{"RER A", `physical_mode.id=physical_mode:RapidTransit and line.code="A"`},
+		{"rerb", `physical_mode.id=physical_mode:RapidTransit and line.code="B"`},
+		{"T3a", `physical_mode.id=physical_mode:Tramway and line.code="3a"`},
+		{" 14 ", `line.code="14"`},
+		{"N01", `line.code="N01"`},
+		{"Transilien", `line.code="Transilien"`},
+	}
+	for _, tt := range tests {
+		if got := LineFilter(tt.input); got != tt.want {
+			t.Errorf("LineFilter(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMatchLine(t *testing.T) {
+	tests := []struct {
+		label, query string
+		want         bool
+	}{
+		{"RER A", "RER A", true},
+		{"RER A", "rera", true},
+		{"RER A", "A", true},
+		{"M14", "14", true},
+		{"T3a", "t3A", true},
+		{"M14", "M1", false},
+		{"M114", "14", false},
+		{"RER B", "RER A", false},
+		{"M1", "T1", false},
+	}
+	for _, tt := range tests {
+		if got := MatchLine(tt.label, tt.query); got != tt.want {
+			t.Errorf("MatchLine(%q, %q) = %v, want %v", tt.label, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMatchDirection(t *testing.T) {
+	tests := []struct {
+		direction, query string
+		want             bool
+	}{
+		{"La Défense (Grande Arche)", "La Défense", true},
+		{"La Défense (Grande Arche)", "defense", true},
+		{"La Défense (Grande Arche)", "la def", true},
+		{"La Défense (Grande Arche)", "ARCHE defense", true},
+		{"Gare Saint-Lazare", "st lazare", true},
+		{"Stade de France", "st", true},
+		{"Château de Vincennes", "chateau", true},
+		{"Château de Vincennes", "defense", false},
+		{"Saint-Germain-en-Laye", "germain saint-denis", false},
+		{"Mairie d'Ivry", "ivry", true},
+		{"Anything", "", true},
+	}
+	for _, tt := range tests {
+		if got := MatchDirection(tt.direction, tt.query); got != tt.want {
+			t.Errorf("MatchDirection(%q, %q) = %v, want %v", tt.direction, tt.query, got, tt.want)
+		}
+	}
+	if !MatchLines("RER A", []string{"M1", "rer a"}) || MatchLines("M14", []string{"M1"}) || !MatchLines("M14", nil) {
+		t.Error("MatchLines")
+	}
+}
@@ -38,12 +38,13 @@ type NavitiaPlacesResponse struct {
 }
 
 type NavitiaPlace struct {
-	ID           string    `json:"id"`
-	Name         string    `json:"name"`
-	EmbeddedType string    `json:"embedded_type"`
-	Quality      int       `json:"quality"`
-	StopArea     *StopArea `json:"stop_area,omitempty"`
-	Address      *Address  `json:"address,omitempty"`
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	EmbeddedType string     `json:"embedded_type"`
+	Quality      int        `json:"quality"`
+	StopArea     *StopArea  `json:"stop_area,omitempty"`
+	StopPoint    *StopPoint `json:"stop_point,omitempty"`
+	Address      *Address   `json:"address,omitempty"`
 }
 
 type Address struct {
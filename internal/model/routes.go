@@ -0,0 +1,33 @@
+package model
+
+// RoutesResponse is returned by /lines/{id}/routes: one route per
+// direction and branch of the line.
+type RoutesResponse struct {
+	Routes []Route `json:"routes"`
+}
+
+// RouteSchedulesResponse is returned by /route_schedules. Each table's
+// rows are the route's stops in running order.
+type RouteSchedulesResponse struct {
+	RouteSchedules []RouteSchedule `json:"route_schedules"`
+}
+
+type RouteSchedule struct {
+	DisplayInformations DisplayInfo `json:"display_informations"`
+	Table               RouteTable  `json:"table"`
+}
+
+type RouteTable struct {
+	Rows []RouteRow `json:"rows"`
+}
+
+type RouteRow struct {
+	StopPoint StopPoint `json:"stop_point"`
+}
+
+// StopAreasResponse is returned by /stop_areas and its /lines/{id}/
+// variant. At depth 2, each stop area lists the lines serving it.
+type StopAreasResponse struct {
+	StopAreas  []StopArea `json:"stop_areas"`
+	Pagination Pagination `json:"pagination"`
+}
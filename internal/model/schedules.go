@@ -0,0 +1,32 @@
+package model
+
+// StopSchedulesResponse is returned by the Navitia /stop_schedules endpoint:
+// one timetable per route serving the stop.
+type StopSchedulesResponse struct {
+	StopSchedules []StopSchedule `json:"stop_schedules"`
+	Disruptions   []Disruption   `json:"disruptions,omitempty"`
+	Pagination    Pagination     `json:"pagination"`
+}
+
+type StopSchedule struct {
+	DisplayInformations DisplayInfo        `json:"display_informations"`
+	StopPoint           StopPoint          `json:"stop_point"`
+	Route               Route              `json:"route"`
+	DateTimes           []ScheduleDateTime `json:"date_times"`
+	// AdditionalInformations explains an empty schedule, e.g. "terminus"
+	// or "no_departure_this_day".
+	AdditionalInformations string `json:"additional_informations,omitempty"`
+}
+
+type ScheduleDateTime struct {
+	DateTime      string `json:"date_time"`
+	BaseDateTime  string `json:"base_date_time,omitempty"`
+	DataFreshness string `json:"data_freshness"`
+}
+
+// ArrivalsResponse is returned by the Navitia /arrivals endpoint. Arrivals
+// share the departure format; StopDateTime.ArrivalDateTime is the time.
+type ArrivalsResponse struct {
+	Arrivals    []Departure  `json:"arrivals"`
+	Disruptions []Disruption `json:"disruptions,omitempty"`
+}
@@ -0,0 +1,151 @@
+// Package notify delivers disruption alerts: desktop notifications,
+// webhooks and user commands.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Event kinds.
+const (
+	New     = "new"     // a disruption appeared
+	Changed = "changed" // its severity changed
+	Cleared = "cleared" // it is no longer active
+)
+
+// Event is a change in a disruption on a watched line.
+type Event struct {
+	Kind             string    `json:"event"`
+	DisruptionID     string    `json:"disruption_id"`
+	Lines            []string  `json:"lines"`
+	Effect           string    `json:"effect"` // e.g. "SIGNIFICANT_DELAYS"
+	Severity         string    `json:"severity"`
+	PreviousSeverity string    `json:"previous_severity,omitempty"`
+	Message          string    `json:"message"`
+	Time             time.Time `json:"time"`
+}
+
+// Title returns a short summary, e.g. "RER A: Interrupted" or
+// "M14: Delays → Modified".
+func (e Event) Title() string {
+	lines := strings.Join(e.Lines, ", ")
+	switch e.Kind {
+	case Cleared:
+		return lines + ": back to normal"
+	case Changed:
+		return fmt.Sprintf("%s: %s → %s", lines, e.PreviousSeverity, e.Severity)
+	default:
+		return lines + ": " + e.Severity
+	}
+}
+
+// Notifier delivers events.
+type Notifier interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// Desktop shows events with notify-send. Interruptions are critical.
+type Desktop struct{}
+
+func (Desktop) Notify(ctx context.Context, e Event) error {
+	urgency := "normal"
+	if e.Kind != Cleared && e.Effect == "NO_SERVICE" {
+		urgency = "critical"
+	}
+	cmd := exec.CommandContext(ctx, "notify-send", "--app-name=metro", "--urgency="+urgency, e.Title(), e.Message)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notify-send: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// DefaultTimeout bounds a webhook call or command, so that a hung one
+// doesn't block the events after it.
+const DefaultTimeout = 10 * time.Second
+
+// withTimeout returns ctx bounded by d, or DefaultTimeout if d is zero.
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		d = DefaultTimeout
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// Webhook POSTs events as JSON to a URL.
+type Webhook struct {
+	URL     string
+	Client  *http.Client  // http.DefaultClient if nil
+	Timeout time.Duration // DefaultTimeout if zero
+}
+
+func (w Webhook) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := withTimeout(ctx, w.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: %s returned %s", w.URL, resp.Status)
+	}
+	return nil
+}
+
+// Exec runs a shell command per event, with the event as JSON on stdin
+// and its fields in METRO_* environment variables.
+type Exec struct {
+	Command string
+	Timeout time.Duration // DefaultTimeout if zero
+}
+
+func (x Exec) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := withTimeout(ctx, x.Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", x.Command)
+	// Don't wait on children of the killed shell holding the output open
+	cmd.WaitDelay = time.Second
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(),
+		"METRO_EVENT="+e.Kind,
+		"METRO_DISRUPTION_ID="+e.DisruptionID,
+		"METRO_LINES="+strings.Join(e.Lines, ","),
+		"METRO_EFFECT="+e.Effect,
+		"METRO_SEVERITY="+e.Severity,
+		"METRO_PREVIOUS_SEVERITY="+e.PreviousSeverity,
+		"METRO_TITLE="+e.Title(),
+		"METRO_MESSAGE="+e.Message,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("exec %q: %w: %s", x.Command, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
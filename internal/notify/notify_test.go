@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testEvent = Event{
+	Kind:             Changed,
+	DisruptionID:     "abc",
+	Lines:            []string{"RER A"},
+	Effect:           "NO_SERVICE",
+	Severity:         "Interrupted",
+	PreviousSeverity: "Delays",
+	Message:          "Trafic interrompu entre Nation et Vincennes",
+	Time:             time.Date(2026, 2, 25, 14, 30, 0, 0, time.UTC),
+}
+
+func TestTitle(t *testing.T) {
+	tests := []struct {
+		kind string
+		want string
+	}{
+		{New, "RER A: Interrupted"},
+		{Changed, "RER A: Delays → Interrupted"},
+		{Cleared, "RER A: back to normal"},
+	}
+	for _, tt := range tests {
+		e := testEvent
+		e.Kind = tt.kind
+		if got := e.Title(); got != tt.want {
+			t.Errorf("Title() for %s = %q, want %q", tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var got Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s with Content-Type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	if err := (Webhook{URL: srv.URL}).Notify(context.Background(), testEvent); err != nil {
+		t.Fatal(err)
+	}
+	if got.DisruptionID != "abc" || got.Kind != Changed || got.Lines[0] != "RER A" {
+		t.Errorf("webhook received %+v", got)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := (Webhook{URL: failing.URL}).Notify(context.Background(), testEvent); err == nil {
+		t.Error("expected an error for a 500 response")
+	}
+
+	hung := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-hung:
+		case <-r.Context().Done():
+		}
+	}))
+	defer hanging.Close()
+	defer close(hung)
+	err := (Webhook{URL: hanging.URL, Timeout: 20 * time.Millisecond}).Notify(context.Background(), testEvent)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a timeout from a hung webhook, got %v", err)
+	}
+}
+
+func TestExec(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	cmd := `printf '%s|%s\n' "$METRO_EVENT" "$METRO_TITLE" > ` + out + ` && cat >> ` + out
+	if err := (Exec{Command: cmd}).Notify(context.Background(), testEvent); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, rest, _ := strings.Cut(string(data), "\n")
+	if first != "changed|RER A: Delays → Interrupted" {
+		t.Errorf("environment = %q", first)
+	}
+	if !strings.Contains(rest, `"disruption_id":"abc"`) {
+		t.Errorf("stdin = %q, want the event as JSON", rest)
+	}
+
+	if err := (Exec{Command: "exit 3"}).Notify(context.Background(), testEvent); err == nil {
+		t.Error("expected an error for a failing command")
+	}
+
+	start := time.Now()
+	if err := (Exec{Command: "sleep 10", Timeout: 50 * time.Millisecond}).Notify(context.Background(), testEvent); err == nil {
+		t.Error("expected an error for a hung command")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("hung command blocked for %s", d)
+	}
+}
@@ -0,0 +1,410 @@
+// Package primtest provides a fake PRIM API server for offline tests.
+//
+// The server answers the endpoints used by the client with recorded
+// fixtures (see the fixtures directory), so commands can be exercised end
+// to end by pointing PRIM_BASE_URL at Server.URL.
+package primtest
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// HubStopArea is a stop area (Gare de Lyon) served by more routes than
+// fit on one page of stop_schedules.
+const HubStopArea = "stop_area:IDFM:73626"
+
+// Server is a fake PRIM marketplace. Any non-empty apikey header is accepted.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+	injected []injected
+}
+
+// Response is a canned reply used by Inject to simulate API failures.
+type Response struct {
+	Status int
+	Body   string
+	Header map[string]string
+}
+
+type injected struct {
+	prefix string
+	resp   Response
+}
+
+// NewServer starts a fake PRIM server. Callers should Close it when done.
+func NewServer() *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Requests returns the request URIs (path and query) received so far.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Inject queues responses for the next requests whose path starts with
+// prefix. Each queued response is used once, in order, before the fixtures.
+func (s *Server) Inject(prefix string, resps ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range resps {
+		s.injected = append(s.injected, injected{prefix: prefix, resp: r})
+	}
+}
+
+// takeInjected pops the first queued response matching path, if any.
+func (s *Server) takeInjected(path string) (Response, bool) {
+	for i, inj := range s.injected {
+		if strings.HasPrefix(path, inj.prefix) {
+			s.injected = append(s.injected[:i], s.injected[i+1:]...)
+			return inj.resp, true
+		}
+	}
+	return Response{}, false
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.RequestURI())
+	inj, ok := s.takeInjected(r.URL.Path)
+	s.mu.Unlock()
+
+	if ok {
+		for k, v := range inj.Header {
+			w.Header().Set(k, v)
+		}
+		writeJSON(w, inj.Status, inj.Body)
+		return
+	}
+
+	if r.Header.Get("apikey") == "" {
+		writeJSON(w, http.StatusUnauthorized, `{"message":"Invalid key"}`)
+		return
+	}
+
+	path := r.URL.Path
+	switch {
+	case path == "/places":
+		s.servePlaces(w, r.URL.Query().Get("q"))
+	case path == "/v2/navitia/places":
+		serveFixture(w, "navitia_places.json")
+	case path == "/v2/navitia/lines":
+		serveLines(w, r.URL.Query().Get("filter"))
+	case path == "/v2/navitia/line_reports":
+		q := r.URL.Query()
+		serveLineReports(w, q.Get("filter"), q.Get("since"), q.Get("until"))
+	case strings.HasPrefix(path, "/v2/navitia/lines/") && strings.HasSuffix(path, "/routes"):
+		serveKeyed(w, "line_routes.json", pathID(path, "/v2/navitia/lines/", "/routes"))
+	case strings.HasPrefix(path, "/v2/navitia/lines/") && strings.HasSuffix(path, "/stop_areas"):
+		serveKeyed(w, "line_stop_areas.json", pathID(path, "/v2/navitia/lines/", "/stop_areas"))
+	case strings.HasPrefix(path, "/v2/navitia/routes/") && strings.HasSuffix(path, "/route_schedules"):
+		serveKeyed(w, "route_schedules.json", pathID(path, "/v2/navitia/routes/", "/route_schedules"))
+	case path == "/v2/navitia/journeys":
+		serveFixture(w, "journeys.json")
+	case strings.HasPrefix(path, "/v2/navitia/stop_areas/") && strings.HasSuffix(path, "/departures"):
+		serveFixture(w, "departures.json")
+	case strings.HasPrefix(path, "/v2/navitia/stop_areas/") && strings.HasSuffix(path, "/stop_schedules"):
+		name := "stop_schedules.json"
+		if pathID(path, "/v2/navitia/stop_areas/", "/stop_schedules") == HubStopArea {
+			name = "stop_schedules_hub.json"
+		}
+		q := r.URL.Query()
+		serveStopSchedules(w, name, q.Get("count"), q.Get("start_page"))
+	case strings.HasPrefix(path, "/v2/navitia/stop_areas/") && strings.HasSuffix(path, "/arrivals"):
+		serveFixture(w, "arrivals.json")
+	case strings.HasPrefix(path, "/v2/navitia/coords/") && strings.HasSuffix(path, "/places_nearby"):
+		serveFixture(w, "places_nearby.json")
+	default:
+		writeJSON(w, http.StatusNotFound, `{"error":{"id":"unknown_object","message":"ressource not found"}}`)
+	}
+}
+
+// servePlaces returns the fixture places whose name contains q, ignoring
+// case and accents, like the real search for simple queries.
+func (s *Server) servePlaces(w http.ResponseWriter, q string) {
+	data, err := fixtures.ReadFile("fixtures/places.json")
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, `{"message":"missing fixture"}`)
+		return
+	}
+	var resp struct {
+		Places []map[string]any `json:"places"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		writeJSON(w, http.StatusInternalServerError, `{"message":"bad fixture"}`)
+		return
+	}
+
+	matched := []map[string]any{}
+	for _, p := range resp.Places {
+		name, _ := p["name"].(string)
+		if strings.Contains(fold(name), fold(q)) {
+			matched = append(matched, p)
+		}
+	}
+	out, _ := json.Marshal(map[string]any{"places": matched})
+	writeJSON(w, http.StatusOK, string(out))
+}
+
+// serveLines picks lines_<mode>.json from the physical mode in the filter.
+// Modes without a fixture return no lines. A line.code clause keeps only
+// that line and the disruptions impacting it.
+func serveLines(w http.ResponseWriter, filter string) {
+	mode := ""
+	if i := strings.LastIndex(filter, "physical_mode:"); i >= 0 {
+		mode, _, _ = strings.Cut(filter[i+len("physical_mode:"):], " ")
+	}
+	var name string
+	switch mode {
+	case "Metro":
+		name = "lines_metro.json"
+	case "RapidTransit":
+		name = "lines_rer.json"
+	default:
+		writeJSON(w, http.StatusOK, `{"lines":[],"disruptions":[],"pagination":{}}`)
+		return
+	}
+
+	code, ok := lineCode(filter)
+	if !ok {
+		serveFixture(w, name)
+		return
+	}
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, `{"message":"missing fixture"}`)
+		return
+	}
+	var resp struct {
+		Lines       []map[string]any `json:"lines"`
+		Disruptions []map[string]any `json:"disruptions"`
+		Pagination  map[string]any   `json:"pagination"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		writeJSON(w, http.StatusInternalServerError, `{"message":"bad fixture"}`)
+		return
+	}
+
+	lines, disruptions := []map[string]any{}, []map[string]any{}
+	ids := make(map[string]bool)
+	for _, l := range resp.Lines {
+		if l["code"] == code {
+			lines = append(lines, l)
+			ids[l["id"].(string)] = true
+		}
+	}
+	for _, d := range resp.Disruptions {
+		objs, _ := d["impacted_objects"].([]any)
+		for _, o := range objs {
+			pt, _ := o.(map[string]any)["pt_object"].(map[string]any)
+			if id, _ := pt["id"].(string); ids[id] {
+				disruptions = append(disruptions, d)
+				break
+			}
+		}
+	}
+	out, _ := json.Marshal(map[string]any{"lines": lines, "disruptions": disruptions, "pagination": resp.Pagination})
+	writeJSON(w, http.StatusOK, string(out))
+}
+
+// serveLineReports keeps the line_reports.json disruptions applying
+// between since and until on lines of the filter's physical mode, and
+// reports the lines they impact.
+func serveLineReports(w http.ResponseWriter, filter, since, until string) {
+	mode := ""
+	if i := strings.LastIndex(filter, "physical_mode:"); i >= 0 {
+		mode, _, _ = strings.Cut(filter[i:], " ")
+	}
+	data, err := fixtures.ReadFile("fixtures/line_reports.json")
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, `{"message":"missing fixture"}`)
+		return
+	}
+	var resp struct {
+		LineReports []struct {
+			Line struct {
+				ID            string `json:"id"`
+				PhysicalModes []struct {
+					ID string `json:"id"`
+				} `json:"physical_modes"`
+			} `json:"line"`
+		} `json:"line_reports"`
+		Disruptions []struct {
+			Periods []struct {
+				Begin string `json:"begin"`
+				End   string `json:"end"`
+			} `json:"application_periods"`
+			Objects []struct {
+				PTObject struct {
+					ID string `json:"id"`
+				} `json:"pt_object"`
+			} `json:"impacted_objects"`
+		} `json:"disruptions"`
+	}
+	var raw struct {
+		LineReports []json.RawMessage `json:"line_reports"`
+		Disruptions []json.RawMessage `json:"disruptions"`
+	}
+	if json.Unmarshal(data, &resp) != nil || json.Unmarshal(data, &raw) != nil {
+		writeJSON(w, http.StatusInternalServerError, `{"message":"bad fixture"}`)
+		return
+	}
+
+	// Navitia local times compare as strings
+	inMode := make(map[string]bool)
+	for _, lr := range resp.LineReports {
+		for _, pm := range lr.Line.PhysicalModes {
+			if mode == "" || pm.ID == mode {
+				inMode[lr.Line.ID] = true
+			}
+		}
+	}
+	disruptions := []json.RawMessage{}
+	impacted := make(map[string]bool)
+	for i, d := range resp.Disruptions {
+		applies := false
+		for _, p := range d.Periods {
+			if (until == "" || p.Begin <= until) && (since == "" || p.End >= since) {
+				applies = true
+			}
+		}
+		if !applies {
+			continue
+		}
+		onLine := false
+		for _, o := range d.Objects {
+			if inMode[o.PTObject.ID] {
+				onLine = true
+				impacted[o.PTObject.ID] = true
+			}
+		}
+		if onLine {
+			disruptions = append(disruptions, raw.Disruptions[i])
+		}
+	}
+	reports := []json.RawMessage{}
+	for i, lr := range resp.LineReports {
+		if impacted[lr.Line.ID] {
+			reports = append(reports, raw.LineReports[i])
+		}
+	}
+	out, _ := json.Marshal(map[string]any{"line_reports": reports, "disruptions": disruptions, "pagination": map[string]any{}})
+	writeJSON(w, http.StatusOK, string(out))
+}
+
+// lineCode extracts X from a `line.code="X"` filter clause.
+func lineCode(filter string) (string, bool) {
+	_, rest, ok := strings.Cut(filter, `line.code="`)
+	if !ok {
+		return "", false
+	}
+	code, _, ok := strings.Cut(rest, `"`)
+	return code, ok
+}
+
+// pathID returns the object ID between prefix and suffix in path.
+func pathID(path, prefix, suffix string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(path, prefix), suffix)
+}
+
+// serveKeyed serves the response stored under id in a fixture mapping
+// object IDs to responses, or Navitia's unknown object error.
+// serveStopSchedules pages a stop_schedules fixture like Navitia: count
+// routes per page (10 by default) from start_page.
+func serveStopSchedules(w http.ResponseWriter, name, count, startPage string) {
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, `{"message":"missing fixture"}`)
+		return
+	}
+	var resp struct {
+		StopSchedules []json.RawMessage `json:"stop_schedules"`
+		Disruptions   []json.RawMessage `json:"disruptions"`
+		Pagination    map[string]int    `json:"pagination"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		writeJSON(w, http.StatusInternalServerError, `{"message":"bad fixture"}`)
+		return
+	}
+
+	perPage, page := 10, 0
+	if n, err := strconv.Atoi(count); err == nil && n > 0 {
+		perPage = n
+	}
+	if n, err := strconv.Atoi(startPage); err == nil && n > 0 {
+		page = n
+	}
+	total := len(resp.StopSchedules)
+	from := min(page*perPage, total)
+	resp.StopSchedules = resp.StopSchedules[from:min(from+perPage, total)]
+	resp.Pagination = map[string]int{
+		"total_result":   total,
+		"start_page":     page,
+		"items_per_page": perPage,
+		"items_on_page":  len(resp.StopSchedules),
+	}
+	out, _ := json.Marshal(resp)
+	writeJSON(w, http.StatusOK, string(out))
+}
+
+func serveKeyed(w http.ResponseWriter, name, id string) {
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, `{"message":"missing fixture"}`)
+		return
+	}
+	var byID map[string]json.RawMessage
+	if err := json.Unmarshal(data, &byID); err != nil {
+		writeJSON(w, http.StatusInternalServerError, `{"message":"bad fixture"}`)
+		return
+	}
+	resp, ok := byID[id]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, `{"error":{"id":"unknown_object","message":"ressource not found"}}`)
+		return
+	}
+	writeJSON(w, http.StatusOK, string(resp))
+}
+
+func serveFixture(w http.ResponseWriter, name string) {
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, `{"message":"missing fixture"}`)
+		return
+	}
+	writeJSON(w, http.StatusOK, string(data))
+}
+
+func writeJSON(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(body))
+}
+
+var accents = strings.NewReplacer(
+	"à", "a", "â", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"î", "i", "ï", "i",
+	"ô", "o", "ö", "o",
+	"ù", "u", "û", "u", "ü", "u",
+	"ç", "c",
+)
+
+func fold(s string) string {
+	return accents.Replace(strings.ToLower(s))
+}
@@ -0,0 +1,323 @@
+// Package stats stores sampled departures in an append-only JSONL log
+// and summarizes their punctuality.
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the log's file name in the data directory.
+const FileName = "departures.jsonl"
+
+// Sample is the last realtime observation of a departure before it left.
+type Sample struct {
+	Place      string    `json:"place"` // as given to metro stats record, e.g. "home · Châtelet"
+	StopID     string    `json:"stop_id"`
+	Stop       string    `json:"stop"`
+	Line       string    `json:"line"` // label, e.g. "RER B"
+	Direction  string    `json:"direction"`
+	Scheduled  time.Time `json:"scheduled"`
+	Expected   time.Time `json:"expected"`
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+// Delay returns how late the departure was; negative when early.
+func (s Sample) Delay() time.Duration {
+	return s.Expected.Sub(s.Scheduled)
+}
+
+func (s Sample) key() string {
+	return s.StopID + "|" + s.Line + "|" + s.Direction + "|" + s.Scheduled.UTC().Format(time.RFC3339)
+}
+
+// Log appends samples to a file. It is safe for concurrent use.
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Open returns a log writing to path. The file and its directory are
+// created on first write.
+func Open(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns the log file path.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Add appends samples.
+func (l *Log) Add(samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, s := range samples {
+		if err := enc.Encode(s); err != nil {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads the samples of departures scheduled from since on, ordered
+// by scheduled time. A departure recorded twice (e.g. by two recorders)
+// keeps its latest observation. A missing file is an empty log;
+// unreadable lines are skipped.
+func Load(path string, since time.Time) ([]Sample, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	byKey := make(map[string]Sample)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var s Sample
+		if err := json.Unmarshal(sc.Bytes(), &s); err != nil || s.Scheduled.Before(since) {
+			continue
+		}
+		k := s.key()
+		if prev, ok := byKey[k]; !ok || s.ObservedAt.After(prev.ObservedAt) {
+			byKey[k] = s
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	samples := make([]Sample, 0, len(byKey))
+	for _, s := range byKey {
+		samples = append(samples, s)
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		if !samples[i].Scheduled.Equal(samples[j].Scheduled) {
+			return samples[i].Scheduled.Before(samples[j].Scheduled)
+		}
+		return samples[i].key() < samples[j].key()
+	})
+	return samples, nil
+}
+
+// Tracker follows upcoming departures across samplings, keeping the
+// latest observation of each, and hands them over once they have left.
+type Tracker struct {
+	pending map[string]Sample
+}
+
+// NewTracker returns an empty tracker.
+func NewTracker() *Tracker {
+	return &Tracker{pending: make(map[string]Sample)}
+}
+
+// forgetAfter is how long a departure that stopped being observed before
+// leaving (e.g. cancelled) is kept.
+const forgetAfter = 30 * time.Minute
+
+// Observe records a sampling of departures taken at now and returns the
+// departures that have left since, ordered by scheduled time.
+func (t *Tracker) Observe(samples []Sample, now time.Time) []Sample {
+	for _, s := range samples {
+		t.pending[s.key()] = s
+	}
+	var left []Sample
+	for k, s := range t.pending {
+		switch {
+		case !s.Expected.After(now):
+			left = append(left, s)
+			delete(t.pending, k)
+		case now.Sub(s.ObservedAt) > forgetAfter:
+			delete(t.pending, k)
+		}
+	}
+	sort.Slice(left, func(i, j int) bool {
+		if !left[i].Scheduled.Equal(left[j].Scheduled) {
+			return left[i].Scheduled.Before(left[j].Scheduled)
+		}
+		return left[i].key() < left[j].key()
+	})
+	return left
+}
+
+// Punctuality summarizes the delays of a group of departures.
+type Punctuality struct {
+	Departures int
+	Median     time.Duration
+	P90        time.Duration
+	OnTime     float64 // share at most the on-time slack late, 0 to 1
+}
+
+// Measure computes the punctuality of samples. A departure is on time
+// when it leaves at most slack late.
+func Measure(samples []Sample, slack time.Duration) Punctuality {
+	if len(samples) == 0 {
+		return Punctuality{}
+	}
+	delays := make([]time.Duration, len(samples))
+	onTime := 0
+	for i, s := range samples {
+		delays[i] = s.Delay()
+		if delays[i] <= slack {
+			onTime++
+		}
+	}
+	sort.Slice(delays, func(i, j int) bool { return delays[i] < delays[j] })
+	return Punctuality{
+		Departures: len(samples),
+		Median:     percentile(delays, 50),
+		P90:        percentile(delays, 90),
+		OnTime:     float64(onTime) / float64(len(samples)),
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted values.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// Group is the punctuality of the departures sharing a key.
+type Group struct {
+	Line, Direction string
+	Weekday         time.Weekday // for hour of week groups
+	Hour            int          // for hour of week groups
+	Slot            string       // scheduled time of day, e.g. "08:10", for time slot groups
+	Punctuality
+}
+
+// ByLine groups samples by line and direction, ordered by line, then
+// direction.
+func ByLine(samples []Sample, slack time.Duration) []Group {
+	groups := group(samples, func(s Sample) Group {
+		return Group{Line: s.Line, Direction: s.Direction}
+	}, slack)
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Line != groups[j].Line {
+			return groups[i].Line < groups[j].Line
+		}
+		return groups[i].Direction < groups[j].Direction
+	})
+	return groups
+}
+
+// WorstHours groups samples by hour of the week in loc, keeps hours with
+// at least minDepartures, and returns the n least punctual: lowest on-time
+// share, then highest p90.
+func WorstHours(samples []Sample, loc *time.Location, slack time.Duration, minDepartures, n int) []Group {
+	groups := group(samples, func(s Sample) Group {
+		t := s.Scheduled.In(loc)
+		return Group{Weekday: t.Weekday(), Hour: t.Hour()}
+	}, slack)
+	kept := groups[:0]
+	for _, g := range groups {
+		if g.Departures >= minDepartures {
+			kept = append(kept, g)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		if kept[i].OnTime != kept[j].OnTime {
+			return kept[i].OnTime < kept[j].OnTime
+		}
+		if kept[i].P90 != kept[j].P90 {
+			return kept[i].P90 > kept[j].P90
+		}
+		return hourOfWeek(kept[i]) < hourOfWeek(kept[j])
+	})
+	return kept[:min(n, len(kept))]
+}
+
+func hourOfWeek(g Group) int {
+	// Monday first
+	return (int(g.Weekday)+6)%7*24 + g.Hour
+}
+
+// BySlot groups samples by line, direction and scheduled time of day in
+// loc, rounded down to step, ordered by line, direction and time. It
+// compares departures leaving at about the same time on different days.
+func BySlot(samples []Sample, loc *time.Location, step time.Duration, slack time.Duration) []Group {
+	groups := group(samples, func(s Sample) Group {
+		t := s.Scheduled.In(loc)
+		minutes := t.Hour()*60 + t.Minute()
+		minutes -= minutes % int(step.Minutes())
+		slot := time.Date(2000, 1, 1, minutes/60, minutes%60, 0, 0, time.UTC).Format("15:04")
+		return Group{Line: s.Line, Direction: s.Direction, Slot: slot}
+	}, slack)
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Direction != b.Direction {
+			return a.Direction < b.Direction
+		}
+		return a.Slot < b.Slot
+	})
+	return groups
+}
+
+// group splits samples by the group key returned by keyOf and measures
+// each group.
+func group(samples []Sample, keyOf func(Sample) Group, slack time.Duration) []Group {
+	var keys []Group
+	members := make(map[Group][]Sample)
+	for _, s := range samples {
+		k := keyOf(s)
+		if _, ok := members[k]; !ok {
+			keys = append(keys, k)
+		}
+		members[k] = append(members[k], s)
+	}
+	groups := make([]Group, len(keys))
+	for i, k := range keys {
+		k.Punctuality = Measure(members[k], slack)
+		groups[i] = k
+	}
+	return groups
+}
+
+// Filter returns the samples at place (matched against the recorded
+// place and stop names, case-insensitive) on lines accepted by matchLine.
+// An empty place or nil matchLine accepts all.
+func Filter(samples []Sample, place string, matchLine func(string) bool) []Sample {
+	place = strings.ToLower(place)
+	var out []Sample
+	for _, s := range samples {
+		if place != "" && !strings.Contains(strings.ToLower(s.Place), place) && !strings.Contains(strings.ToLower(s.Stop), place) {
+			continue
+		}
+		if matchLine != nil && !matchLine(s.Line) {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
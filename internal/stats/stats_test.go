@@ -0,0 +1,145 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Monday 2 March 2026, 08:00 UTC
+var t0 = time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+
+func sample(line string, scheduled time.Time, delay time.Duration) Sample {
+	return Sample{
+		StopID:     "stop_area:IDFM:71264",
+		Stop:       "Châtelet",
+		Place:      "home · Châtelet",
+		Line:       line,
+		Direction:  "La Défense",
+		Scheduled:  scheduled,
+		Expected:   scheduled.Add(delay),
+		ObservedAt: scheduled.Add(-time.Minute),
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	var samples []Sample
+	for i, delay := range []int{0, 0, 0, 1, 1, 2, 3, 5, 8, -1} {
+		samples = append(samples, sample("M1", t0.Add(time.Duration(i)*time.Minute), time.Duration(delay)*time.Minute))
+	}
+	p := Measure(samples, time.Minute)
+	if p.Departures != 10 || p.Median != time.Minute || p.P90 != 5*time.Minute || p.OnTime != 0.6 {
+		t.Errorf("Measure = %+v", p)
+	}
+	if p := Measure(nil, time.Minute); p != (Punctuality{}) {
+		t.Errorf("Measure(nil) = %+v", p)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tr := NewTracker()
+	first := sample("M1", t0.Add(2*time.Minute), time.Minute)
+	first.ObservedAt = t0
+	second := sample("M1", t0.Add(10*time.Minute), 0)
+	second.ObservedAt = t0
+
+	if left := tr.Observe([]Sample{first, second}, t0); len(left) != 0 {
+		t.Fatalf("nothing has left yet, got %+v", left)
+	}
+	// A later estimate replaces the earlier one
+	later := first
+	later.Expected = first.Scheduled.Add(3 * time.Minute)
+	later.ObservedAt = t0.Add(4 * time.Minute)
+	if left := tr.Observe([]Sample{later, second}, t0.Add(4*time.Minute)); len(left) != 0 {
+		t.Fatalf("got %+v", left)
+	}
+	left := tr.Observe([]Sample{second}, t0.Add(6*time.Minute))
+	if len(left) != 1 || left[0].Delay() != 3*time.Minute {
+		t.Fatalf("got %+v, want the first departure 3 min late", left)
+	}
+
+	// A departure no longer observed (e.g. cancelled) is eventually dropped
+	tr.Observe(nil, t0.Add(time.Hour))
+	if len(tr.pending) != 0 {
+		t.Errorf("pending = %+v", tr.pending)
+	}
+}
+
+func TestLogLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", FileName)
+	log := Open(path)
+	a := sample("M1", t0, 2*time.Minute)
+	old := sample("M1", t0.AddDate(0, 0, -40), 0)
+	if err := log.Add([]Sample{old, a}); err != nil {
+		t.Fatal(err)
+	}
+	// The same departure recorded again by another recorder, later
+	again := a
+	again.Expected = a.Scheduled.Add(3 * time.Minute)
+	again.ObservedAt = a.ObservedAt.Add(time.Minute)
+	if err := log.Add([]Sample{again}); err != nil {
+		t.Fatal(err)
+	}
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	f.WriteString("{\"line\":")
+	f.Close()
+
+	samples, err := Load(path, t0.AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 1 || samples[0].Delay() != 3*time.Minute {
+		t.Errorf("Load = %+v", samples)
+	}
+}
+
+func TestWorstHours(t *testing.T) {
+	var samples []Sample
+	// Monday 08:xx: 5 departures, 2 on time
+	for i, delay := range []int{0, 4, 0, 6, 3} {
+		samples = append(samples, sample("M1", t0.Add(time.Duration(i)*5*time.Minute), time.Duration(delay)*time.Minute))
+	}
+	// Monday 09:xx: 5 departures, all on time
+	for i := 0; i < 5; i++ {
+		samples = append(samples, sample("M1", t0.Add(time.Hour+time.Duration(i)*5*time.Minute), 0))
+	}
+	// Tuesday 08:xx: too few to rank
+	samples = append(samples, sample("M1", t0.AddDate(0, 0, 1), 10*time.Minute))
+
+	worst := WorstHours(samples, time.UTC, time.Minute, 5, 1)
+	if len(worst) != 1 || worst[0].Weekday != time.Monday || worst[0].Hour != 8 || worst[0].OnTime != 0.4 {
+		t.Errorf("WorstHours = %+v", worst)
+	}
+}
+
+func TestBySlot(t *testing.T) {
+	samples := []Sample{
+		sample("M1", t0.Add(10*time.Minute), 0),                              // 08:10 Monday
+		sample("M1", t0.AddDate(0, 0, 1).Add(12*time.Minute), 4*time.Minute), // 08:12 Tuesday
+		sample("M1", t0.Add(25*time.Minute), 0),                              // 08:25
+	}
+	groups := BySlot(samples, time.UTC, 5*time.Minute, time.Minute)
+	if len(groups) != 2 {
+		t.Fatalf("BySlot = %+v", groups)
+	}
+	if g := groups[0]; g.Slot != "08:10" || g.Departures != 2 || g.OnTime != 0.5 {
+		t.Errorf("08:10 slot = %+v", g)
+	}
+	if g := groups[1]; g.Slot != "08:25" || g.Departures != 1 || g.OnTime != 1 {
+		t.Errorf("08:25 slot = %+v", g)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	b := sample("RER B", t0, 0)
+	b.Place, b.Stop = "work · Gare du Nord", "Gare du Nord"
+	samples := []Sample{sample("M1", t0, 0), b}
+
+	if got := Filter(samples, "nord", nil); len(got) != 1 || got[0].Line != "RER B" {
+		t.Errorf("by place: %+v", got)
+	}
+	if got := Filter(samples, "", func(l string) bool { return l == "M1" }); len(got) != 1 || got[0].Line != "M1" {
+		t.Errorf("by line: %+v", got)
+	}
+}